@@ -80,11 +80,19 @@ func main() {
 		TIF:     model.Day,
 		Account: account,
 	}
-	if err := c.SendOrderWS(context.Background(), ord); err != nil {
+	h, err := c.SendOrderWS(context.Background(), ord)
+	if err != nil {
 		slog.Error("send ws order", slog.Any("err", err))
 		return
 	}
+	ack, err := h.Wait(context.Background())
+	if err != nil {
+		slog.Error("ws order not accepted", slog.String("wsClOrdId", h.WSClOrdID), slog.Any("err", err))
+		return
+	}
 	slog.Info("ws order sent",
+		slog.String("clOrdId", ack.ClOrdID),
+		slog.String("status", string(ack.Status)),
 		slog.String("symbol", symbol),
 		slog.Int64("qty", qty),
 		slog.String("side", string(sd)),
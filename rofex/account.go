@@ -3,6 +3,8 @@ package rofex
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/carvalab/rofex-go/rofex/model"
 )
@@ -11,8 +13,78 @@ import (
 //
 // Devuelve todas las cuentas disponibles para el usuario actual.
 // Útil para determinar qué cuentas se pueden usar para trading.
+//
+// El listado queda en el cache del cliente: a partir de ese momento SubscribeOrderReport,
+// los métodos de órdenes y las consultas por cuenta rechazan localmente las cuentas
+// desconocidas con un ValidationError. El cache vence a la hora (ver WithAccountsTTL):
+// vencido, la validación local se omite hasta la próxima llamada a Accounts o Account.
 func (c *Client) Accounts(ctx context.Context) (model.AccountsResponse, error) {
-	return getTyped[model.AccountsResponse](ctx, c, pathAccounts)
+	res, err := getTyped[model.AccountsResponse](ctx, c, pathAccounts)
+	if err != nil {
+		return res, err
+	}
+	c.accounts.put(res)
+	return res, nil
+}
+
+// Account devuelve una cuenta por nombre desde el cache o, si el listado todavía no
+// se consultó o el cache venció, llamando a Accounts.
+func (c *Client) Account(ctx context.Context, name string) (model.Account, error) {
+	res, ok := c.accounts.get()
+	if !ok {
+		var err error
+		if res, err = c.Accounts(ctx); err != nil {
+			return model.Account{}, err
+		}
+	}
+	acc, ok := res.ByName(name)
+	if !ok {
+		return model.Account{}, &ValidationError{Field: "account", Msg: fmt.Sprintf("unknown account %q", name)}
+	}
+	return acc, nil
+}
+
+// validateAccount verifica que la cuenta esté informada y, si hay un listado de cuentas
+// vigente en el cache, que sea una de ellas.
+func (c *Client) validateAccount(account string) error {
+	if account == "" {
+		return &ValidationError{Field: "account", Msg: "required"}
+	}
+	res, ok := c.accounts.get()
+	if !ok {
+		return nil
+	}
+	if _, ok := res.ByName(account); !ok {
+		return &ValidationError{Field: "account", Msg: fmt.Sprintf("unknown account %q", account)}
+	}
+	return nil
+}
+
+// accountCacheTTL es la vigencia por defecto del listado de cuentas en cache.
+const accountCacheTTL = time.Hour
+
+// accountCache guarda el último listado de cuentas consultado con Accounts durante ttl.
+type accountCache struct {
+	ttl time.Duration
+
+	mu     sync.RWMutex
+	res    model.AccountsResponse
+	loaded time.Time
+}
+
+func (ac *accountCache) get() (model.AccountsResponse, bool) {
+	ac.mu.RLock()
+	defer ac.mu.RUnlock()
+	if ac.loaded.IsZero() || time.Since(ac.loaded) >= ac.ttl {
+		return model.AccountsResponse{}, false
+	}
+	return ac.res, true
+}
+
+func (ac *accountCache) put(res model.AccountsResponse) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	ac.res, ac.loaded = res, time.Now()
 }
 
 // AccountPosition consulta las posiciones de una cuenta según Primary Risk API.
@@ -28,8 +100,11 @@ func (c *Client) Accounts(ctx context.Context) (model.AccountsResponse, error) {
 //
 // Referencia: docs/primary-api.md - "Consultar las posiciones de una cuenta"
 func (c *Client) AccountPosition(ctx context.Context, account string) (model.AccountPositionResponse, error) {
-	if account == "" {
-		return model.AccountPositionResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.AccountPositionResponse{}, err
+	}
+	if c.paper != nil {
+		return c.paper.accountPosition(account), nil
 	}
 	path := fmt.Sprintf(pathAccountPos, account)
 	return getTyped[model.AccountPositionResponse](ctx, c, path)
@@ -45,8 +120,8 @@ func (c *Client) AccountPosition(ctx context.Context, account string) (model.Acc
 //
 // Referencia: docs/primary-api.md - "Consultar detalle de posiciones"
 func (c *Client) DetailedPosition(ctx context.Context, account string) (model.DetailedPositionResponse, error) {
-	if account == "" {
-		return model.DetailedPositionResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.DetailedPositionResponse{}, err
 	}
 	path := fmt.Sprintf(pathDetailedPos, account)
 	return getTyped[model.DetailedPositionResponse](ctx, c, path)
@@ -68,9 +143,48 @@ func (c *Client) DetailedPosition(ctx context.Context, account string) (model.De
 //
 // Referencia: docs/primary-api.md - "Consultar reporte de cuenta"
 func (c *Client) AccountReport(ctx context.Context, account string) (model.AccountReportResponse, error) {
-	if account == "" {
-		return model.AccountReportResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.AccountReportResponse{}, err
 	}
 	path := fmt.Sprintf(pathAccountReport, account)
 	return getTyped[model.AccountReportResponse](ctx, c, path)
 }
+
+// riskInterval es la separación mínima entre consultas a un mismo endpoint de
+// reportes de riesgo (1 request cada 5 segundos según la documentación).
+const riskInterval = 5 * time.Second
+
+// riskPacer espacia las consultas a un endpoint de riesgo: cada wait reserva el
+// siguiente turno libre y bloquea hasta que llegue o ctx se cancele.
+type riskPacer struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRiskPacer() *riskPacer { return &riskPacer{interval: riskInterval} }
+
+func (p *riskPacer) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+
+	d := time.Until(at)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,257 @@
+// Package algo implementa algoritmos de ejecución del lado del cliente sobre rofex.Client.
+//
+// Los algoritmos reciben una orden padre y la ejecutan mediante órdenes hijas
+// enviadas con SendOrder, respetando el precio límite de la padre y la grilla de
+// ticks y lotes del instrumento (model.Instrument). Se alimentan con los
+// Execution Reports de la cuenta y la Market Data del instrumento:
+//
+//	client, _ := rofex.NewClient(...)
+//	orders, _ := client.SubscribeOrderReport(ctx, "REM6771", false)
+//	md, _ := client.SubscribeMarketData(ctx, []string{"DLR/MAR26"},
+//		[]model.MDEntry{model.MDBids, model.MDOffers, model.MDLast, model.MDTradeVolume}, 1, model.MarketROFEX)
+//
+//	ex, _ := algo.NewExecutor(client, algo.Parent{
+//		Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Buy, Qty: 500, LimitPrice: &limit,
+//	}, &algo.TWAP{Start: start, End: end, Slices: 20, Randomize: 0.3}, algo.Options{})
+//	go ex.Run(ctx, orders.Events, md.Events)
+//	fmt.Println(ex.Progress())
+package algo
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Parent describe la orden padre a ejecutar.
+type Parent struct {
+	Account    string
+	Symbol     string
+	Market     model.Market // Por defecto model.MarketROFEX
+	Side       model.Side
+	Qty        int64
+	LimitPrice *float64 // Precio límite (nil = sin límite)
+}
+
+func (p Parent) instrumentID() model.InstrumentID {
+	return model.InstrumentID{Symbol: p.Symbol, MarketID: string(p.Market)}
+}
+
+// State es el estado de un algoritmo.
+type State string
+
+const (
+	StateWorking   State = "WORKING"
+	StateFinishing State = "FINISHING"
+	StateCompleted State = "COMPLETED"
+	StateCancelled State = "CANCELLED"
+	StateFailed    State = "FAILED"
+)
+
+// Done indica si el algoritmo terminó.
+func (s State) Done() bool {
+	return s == StateCompleted || s == StateCancelled || s == StateFailed
+}
+
+// Progress resume el avance de un algoritmo.
+type Progress struct {
+	State    State
+	Qty      int64   // Cantidad total de la padre
+	Target   int64   // Cantidad que el schedule espera ejecutada a la fecha
+	Filled   int64   // Cantidad operada
+	Working  int64   // Cantidad en hijas activas
+	AvgPx    float64 // Precio promedio operado
+	Children int     // Hijas enviadas
+	Err      error   // Último error de envío (si lo hubo)
+}
+
+// Remaining devuelve la cantidad pendiente de operar.
+func (p Progress) Remaining() int64 { return p.Qty - p.Filled }
+
+// child es una orden hija enviada al mercado.
+type child struct {
+	clOrdID    string
+	qty        int64
+	leaves     int64
+	price      *float64
+	status     model.OrderStatus
+	sent       time.Time
+	cancelling bool
+}
+
+func (c *child) active() bool { return !c.status.IsTerminal() }
+
+// sendRejected devuelve un *rofex.OrderRejectedError si la API respondió el envío con
+// HTTP 200 pero un status distinto de OK o sin clOrdID: no hay orden que seguir.
+func sendRejected(res model.SendOrderResponse) error {
+	if (res.Status == "" || strings.EqualFold(res.Status, "OK")) && res.Order.ClientID != "" {
+		return nil
+	}
+	return &rofex.OrderRejectedError{Text: "status " + res.Status}
+}
+
+// statusError devuelve un error si la API respondió un reemplazo o una cancelación con
+// HTTP 200 pero un status distinto de OK: la orden no cambió.
+func statusError(status string) error {
+	if status == "" || strings.EqualFold(status, "OK") {
+		return nil
+	}
+	return fmt.Errorf("status %s", status)
+}
+
+// children lleva el estado de las hijas de un algoritmo y sus fills, deduplicados por execId.
+type children struct {
+	byID    map[string]*child
+	order   []*child
+	execIDs map[string]struct{}
+	filled  int64
+	notion  float64
+}
+
+func newChildren() *children {
+	return &children{byID: make(map[string]*child), execIDs: make(map[string]struct{})}
+}
+
+func (cs *children) add(c *child) {
+	cs.byID[c.clOrdID] = c
+	cs.order = append(cs.order, c)
+}
+
+// rekey asocia el nuevo clOrdID de una hija reemplazada.
+func (cs *children) rekey(old, id string) {
+	if c, ok := cs.byID[old]; ok {
+		cs.byID[id] = c
+		c.clOrdID = id
+	}
+}
+
+// apply procesa un Execution Report y devuelve la hija afectada (nil si no es del algoritmo).
+// lastQty es la cantidad operada nueva informada por el reporte (0 si no hubo fill).
+func (cs *children) apply(rep model.OrderDetails) (c *child, lastQty int64) {
+	c, ok := cs.byID[rep.ClOrdID]
+	if !ok {
+		return nil, 0
+	}
+	if c.clOrdID != rep.ClOrdID {
+		// Reporte tardío de un clOrdID ya reemplazado: solo interesan sus fills.
+		return c, cs.fill(rep)
+	}
+	c.status = model.OrderStatus(rep.Status)
+	if rep.OrderQty > 0 {
+		c.qty = int64(rep.OrderQty)
+	}
+	if rep.LeavesQty != nil {
+		c.leaves = int64(*rep.LeavesQty)
+	}
+	if !c.active() {
+		c.leaves = 0
+	}
+	return c, cs.fill(rep)
+}
+
+func (cs *children) fill(rep model.OrderDetails) int64 {
+	if rep.LastQty == nil || *rep.LastQty <= 0 || rep.LastPx == nil || rep.ExecID == nil {
+		return 0
+	}
+	if _, seen := cs.execIDs[*rep.ExecID]; seen {
+		return 0
+	}
+	cs.execIDs[*rep.ExecID] = struct{}{}
+	q := int64(*rep.LastQty)
+	cs.filled += q
+	cs.notion += float64(q) * *rep.LastPx
+	return q
+}
+
+func (cs *children) avgPx() float64 {
+	if cs.filled == 0 {
+		return 0
+	}
+	return cs.notion / float64(cs.filled)
+}
+
+func (cs *children) working() []*child {
+	var out []*child
+	for _, c := range cs.order {
+		if c.active() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (cs *children) workingQty() int64 {
+	var n int64
+	for _, c := range cs.working() {
+		n += c.leaves
+	}
+	return n
+}
+
+// quote guarda el top of book y los acumulados del instrumento. Las actualizaciones
+// parciales (solo BI u OF) conservan el otro lado.
+type quote struct {
+	bid, ask, last float64
+	tradeVolume    float64
+	hasTV          bool
+}
+
+func (q *quote) update(md model.MarketData) {
+	if md.Bids != nil {
+		q.bid = 0
+		if len(md.Bids) > 0 {
+			q.bid = md.Bids[0].Price
+		}
+	}
+	if md.Offers != nil {
+		q.ask = 0
+		if len(md.Offers) > 0 {
+			q.ask = md.Offers[0].Price
+		}
+	}
+	if md.LA != nil && md.LA.Price != nil {
+		q.last = *md.LA.Price
+	}
+	if md.TradeVolume != nil {
+		q.tradeVolume = *md.TradeVolume
+		q.hasTV = true
+	}
+}
+
+// touch devuelve el mejor precio del lado indicado (0 si no hay).
+func (q *quote) touch(side model.Side) float64 {
+	if side == model.Sell {
+		return q.ask
+	}
+	return q.bid
+}
+
+func (q *quote) mid() float64 {
+	if q.bid > 0 && q.ask > 0 {
+		return (q.bid + q.ask) / 2
+	}
+	return 0
+}
+
+// opposite devuelve el lado contrario.
+func opposite(side model.Side) model.Side {
+	if side == model.Sell {
+		return model.Buy
+	}
+	return model.Sell
+}
+
+// capPrice limita px al precio límite de la padre (nunca peor que el límite).
+func capPrice(px float64, side model.Side, limit *float64) float64 {
+	if limit == nil {
+		return px
+	}
+	if side == model.Sell {
+		return math.Max(px, *limit)
+	}
+	return math.Min(px, *limit)
+}
@@ -0,0 +1,285 @@
+package algo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// venue simula los endpoints REST usados por los algoritmos y registra las órdenes recibidas.
+type venue struct {
+	mu     sync.Mutex
+	seq    int
+	log    []string
+	ts     *httptest.Server
+	status map[string]any   // respuesta de /rest/order/id
+	instr  []map[string]any // respuesta de /rest/instruments/details
+	reject map[string]bool  // paths de órdenes que responden status ERROR
+}
+
+func newVenue(t *testing.T, trades []map[string]any) *venue {
+	t.Helper()
+	v := &venue{}
+	v.ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		if v.reject[r.URL.Path] {
+			v.log = append(v.log, "error "+r.URL.Path)
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "ERROR", "description": "rejected"})
+			return
+		}
+		switch r.URL.Path {
+		case "/rest/instruments/detail":
+			inst := map[string]any{
+				"minPriceIncrement":        0.5,
+				"roundLot":                 5,
+				"instrumentPricePrecision": 1,
+				"instrumentId":             map[string]any{"marketId": "ROFX", "symbol": q.Get("symbol")},
+			}
+			for _, in := range v.instr {
+				if id, _ := in["instrumentId"].(map[string]any); id["symbol"] == q.Get("symbol") {
+					inst["maturityDate"], inst["cficode"] = in["maturityDate"], in["cficode"]
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "instrument": inst})
+		case "/rest/instruments/details":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "instruments": v.instr})
+		case "/rest/order/newSingleOrder":
+			v.seq++
+			id := fmt.Sprintf("ch%d", v.seq)
+			v.log = append(v.log, fmt.Sprintf("new %s %s %s", q.Get("ordType"), q.Get("orderQty"), q.Get("price")))
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": id}})
+		case "/rest/order/cancelById":
+			v.log = append(v.log, "cancel "+q.Get("clOrdId"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": q.Get("clOrdId")}})
+		case "/rest/order/replaceById":
+			v.seq++
+			id := fmt.Sprintf("rp%d", v.seq)
+			v.log = append(v.log, fmt.Sprintf("replace %s %s", q.Get("clOrdId"), q.Get("price")))
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": id}})
+		case "/rest/order/id":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": v.status})
+		case "/rest/data/getTrades":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "trades": trades})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	t.Cleanup(v.ts.Close)
+	return v
+}
+
+func (v *venue) orders() []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]string(nil), v.log...)
+}
+
+func report(clOrdID, status, execID string, last int, px float64, leaves int) *model.OrderReportEvent {
+	rep := model.OrderDetails{ClOrdID: clOrdID, Status: status, LeavesQty: &leaves}
+	if last > 0 {
+		rep.ExecID, rep.LastQty, rep.LastPx = &execID, &last, &px
+	}
+	return &model.OrderReportEvent{Type: model.WSMessageOrderReport, OrderReport: rep}
+}
+
+func TestSchedules(t *testing.T) {
+	start := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	end := start.Add(4 * time.Hour)
+
+	twap := &TWAP{Start: start, End: end, Slices: 8, Randomize: 0.5, Seed: 7}
+	var prev int64
+	for m := 0; m <= 240; m += 10 {
+		got := twap.Target(start.Add(time.Duration(m)*time.Minute), 1000)
+		if got < prev {
+			t.Fatalf("twap not monotonic at %dm: %d < %d", m, got, prev)
+		}
+		prev = got
+	}
+	if first := twap.Target(start, 1000); first < 45 || first > 300 {
+		t.Fatalf("twap first slice out of jitter bounds: %d", first)
+	}
+	if got := twap.Target(end, 1000); got != 1000 {
+		t.Fatalf("twap end: %d", got)
+	}
+
+	// 3/4 del volumen en la primera hora
+	profile := VolumeProfile{Bucket: time.Hour, Location: time.UTC, Volume: map[int]float64{11: 300, 12: 50, 13: 50}}
+	vwap := &VWAP{Start: start, End: start.Add(3 * time.Hour), Profile: profile}
+	if got := vwap.Target(start.Add(time.Hour), 1000); got != 750 {
+		t.Fatalf("vwap after first hour: want 750 got %d", got)
+	}
+	if got := vwap.Target(start.Add(150*time.Minute), 1000); got != 938 {
+		t.Fatalf("vwap mid last hour: want 938 got %d", got)
+	}
+
+	pov := &POV{Rate: 0.1}
+	tv := func(v float64) model.MarketData { return model.MarketData{TradeVolume: &v} }
+	pov.OnMarketData(tv(5000))
+	pov.OnMarketData(tv(5420))
+	if got := pov.Target(start, 1000); got != 42 {
+		t.Fatalf("pov: want 42 got %d", got)
+	}
+	// Lo operado por el algoritmo no cuenta como volumen del mercado
+	pov.OnFill(20)
+	if got := pov.Target(start, 1000); got != 40 {
+		t.Fatalf("pov without own fills: want 40 got %d", got)
+	}
+}
+
+func TestBuildVolumeProfile(t *testing.T) {
+	day1 := time.Date(2026, 3, 2, 11, 5, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	v := newVenue(t, []map[string]any{
+		{"price": 1000, "size": 10, "servertime": day1.UnixMilli()},
+		{"price": 1000, "size": 30, "servertime": day2.UnixMilli()},
+		{"price": 1000, "size": 6, "servertime": day2.Add(time.Hour).UnixMilli()},
+	})
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+
+	p, err := BuildVolumeProfile(context.Background(), c, "DLR/MAR26", "", day1, day2, time.Hour, time.UTC)
+	if err != nil {
+		t.Fatalf("BuildVolumeProfile: %v", err)
+	}
+	if p.Volume[11] != 20 || p.Volume[12] != 3 {
+		t.Fatalf("profile: %+v", p.Volume)
+	}
+}
+
+func TestExecutor_LimitTicksProgressAndFinish(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	now := start
+	limit := 1000.3
+	ex, err := NewExecutor(c, Parent{Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Buy, Qty: 100, LimitPrice: &limit},
+		&TWAP{Start: start, End: start.Add(4 * time.Minute), Slices: 4}, Options{ChildTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewExecutor: %v", err)
+	}
+	ex.now = func() time.Time { return now }
+	ex.inst, _ = c.Instrument(ctx, "DLR/MAR26", model.MarketROFEX)
+
+	// La punta vendedora está por encima del límite: la hija se acota al límite y al tick de 0.5
+	ask := []model.BookLevel{{Price: 1001, Size: 50}}
+	ex.OnMarketData(&model.MarketDataEvent{InstrumentID: model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}, MarketData: model.MarketData{Offers: ask}})
+	ex.step(ctx)
+	ex.OnOrderReport(report("ch1", "FILLED", "e1", 25, 1000, 0))
+
+	// Segundo tramo con fill parcial; al vencer el TTL se cancela y se re-cotiza el remanente
+	now = start.Add(time.Minute)
+	ex.step(ctx)
+	ex.OnOrderReport(report("ch2", "PARTIALLY_FILLED", "e2", 15, 1000.5, 10))
+	ex.OnOrderReport(report("ch2", "PARTIALLY_FILLED", "e2", 15, 1000.5, 10)) // duplicado
+	now = now.Add(time.Minute + time.Second)
+	ex.step(ctx)
+	ex.OnOrderReport(report("ch2", "CANCELLED", "", 0, 0, 0))
+
+	p := ex.Progress()
+	if p.Filled != 40 || p.AvgPx != (25*1000+15*1000.5)/40.0 {
+		t.Fatalf("progress: %+v", p)
+	}
+
+	if err := ex.Finish(ctx); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	ex.step(ctx)
+	ex.OnOrderReport(report("ch3", "FILLED", "e3", 60, 1000, 0))
+	ex.step(ctx)
+
+	want := []string{
+		"new LIMIT 25 1000",
+		"new LIMIT 25 1000",
+		"cancel ch2",
+		"new LIMIT 60 1000",
+	}
+	got := v.orders()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("orders:\nwant %v\ngot  %v", want, got)
+	}
+	if p := ex.Progress(); p.State != StateCompleted || p.Filled != 100 || p.Remaining() != 0 {
+		t.Fatalf("final progress: %+v", p)
+	}
+}
+
+func TestExecutor_CancelLeavesNoChildren(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	price := 1000.0
+	ex, _ := NewExecutor(c, Parent{Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Sell, Qty: 50, LimitPrice: &price},
+		&POV{Rate: 1}, Options{Interval: 5 * time.Millisecond})
+	orders := make(chan *model.OrderReportEvent, 4)
+	md := make(chan *model.MarketDataEvent, 4)
+	done := make(chan error, 1)
+	go func() { done <- ex.Run(ctx, orders, md) }()
+
+	tv := func(v float64) *model.MarketDataEvent {
+		return &model.MarketDataEvent{InstrumentID: model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}, MarketData: model.MarketData{TradeVolume: &v}}
+	}
+	md <- tv(100)
+	md <- tv(120)
+	for ex.Progress().Children == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if err := ex.Cancel(ctx); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	select {
+	case <-done:
+		t.Fatalf("Run returned before cancel confirmation")
+	case <-time.After(20 * time.Millisecond):
+	}
+	orders <- report("ch1", "CANCELLED", "", 0, 0, 0)
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := v.orders(); fmt.Sprint(got) != "[new LIMIT 20 1000 cancel ch1]" {
+		t.Fatalf("orders: %v", got)
+	}
+	if p := ex.Progress(); p.State != StateCancelled || p.Working != 0 {
+		t.Fatalf("progress: %+v", p)
+	}
+}
+
+func TestExecutor_ErrorStatusFails(t *testing.T) {
+	v := newVenue(t, nil)
+	v.reject = map[string]bool{"/rest/order/newSingleOrder": true}
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	start := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	limit := 1000.0
+	ex, err := NewExecutor(c, Parent{Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Buy, Qty: 100, LimitPrice: &limit},
+		&TWAP{Start: start, End: start.Add(4 * time.Minute), Slices: 4}, Options{})
+	if err != nil {
+		t.Fatalf("NewExecutor: %v", err)
+	}
+	ex.now = func() time.Time { return start }
+	ex.inst, _ = c.Instrument(ctx, "DLR/MAR26", model.MarketROFEX)
+
+	// HTTP 200 con status ERROR: no queda una hija fantasma y el algoritmo se detiene
+	ex.step(ctx)
+	ex.step(ctx)
+	p := ex.Progress()
+	if p.State != StateFailed || p.Children != 0 || p.Working != 0 || !errors.As(p.Err, new(*rofex.OrderRejectedError)) {
+		t.Fatalf("progress: %+v", p)
+	}
+	if got := v.orders(); fmt.Sprint(got) != "[error /rest/order/newSingleOrder]" {
+		t.Fatalf("orders: %v", got)
+	}
+}
@@ -0,0 +1,358 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Options configura un Executor.
+type Options struct {
+	Interval    time.Duration // Frecuencia de reevaluación del schedule (por defecto 5s)
+	ChildTTL    time.Duration // Vida máxima de una hija antes de cancelarla y re-cotizar (por defecto 30s)
+	Passive     bool          // Cotizar en el propio lado del libro en lugar de tomar la punta contraria
+	MinChildQty int64         // Tamaño mínimo de una hija (por defecto el lote del instrumento)
+	TIF         model.TimeInForce
+	Logger      *slog.Logger
+}
+
+// Executor ejecuta una orden padre según un Schedule mediante órdenes hijas.
+//
+// En cada evaluación compara lo operado con Schedule.Target y, si está atrasado y no
+// hay una hija trabajando, envía una hija LIMIT por la diferencia: al precio de la punta
+// contraria (o la propia si Options.Passive), acotado por el LimitPrice de la padre y
+// ajustado a la grilla de ticks y lotes del instrumento. Sin precios en el libro se usa
+// el LimitPrice; sin LimitPrice la hija es MARKET. Las hijas que superan ChildTTL se
+// cancelan y se vuelven a cotizar.
+//
+// Cancel detiene el algoritmo y cancela las hijas activas; Finish envía el remanente
+// de inmediato. Run vuelve cuando el algoritmo termina y no quedan hijas activas.
+type Executor struct {
+	client *rofex.Client
+	parent Parent
+	sched  Schedule
+	opts   Options
+
+	mu       sync.Mutex
+	inst     model.Instrument
+	state    State
+	kids     *children
+	quote    quote
+	target   int64
+	err      error
+	finisher *child // hija enviada por Finish
+	now      func() time.Time
+}
+
+// NewExecutor valida la orden padre y crea el Executor. La ejecución comienza con Run.
+func NewExecutor(c *rofex.Client, parent Parent, sched Schedule, opts Options) (*Executor, error) {
+	if parent.Account == "" {
+		return nil, &rofex.ValidationError{Field: "account", Msg: "required"}
+	}
+	if parent.Symbol == "" {
+		return nil, &rofex.ValidationError{Field: "symbol", Msg: "required"}
+	}
+	if parent.Side != model.Buy && parent.Side != model.Sell {
+		return nil, &rofex.ValidationError{Field: "side", Msg: "must be BUY or SELL"}
+	}
+	if parent.Qty <= 0 {
+		return nil, &rofex.ValidationError{Field: "qty", Msg: "must be > 0"}
+	}
+	if sched == nil {
+		return nil, &rofex.ValidationError{Field: "schedule", Msg: "required"}
+	}
+	if parent.Market == "" {
+		parent.Market = model.MarketROFEX
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.ChildTTL <= 0 {
+		opts.ChildTTL = 30 * time.Second
+	}
+	if opts.TIF == "" {
+		opts.TIF = model.Day
+	}
+	return &Executor{
+		client: c,
+		parent: parent,
+		sched:  sched,
+		opts:   opts,
+		state:  StateWorking,
+		kids:   newChildren(),
+		now:    time.Now,
+	}, nil
+}
+
+// Run ejecuta el algoritmo consumiendo Execution Reports y Market Data hasta que
+// termine (completo, cancelado o fallido) o ctx se cancele. md puede ser nil si el
+// schedule no depende de Market Data, en cuyo caso las hijas se cotizan al LimitPrice.
+func (e *Executor) Run(ctx context.Context, orders <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) error {
+	inst, err := e.client.Instrument(ctx, e.parent.Symbol, e.parent.Market)
+	if err != nil {
+		e.fail(fmt.Errorf("algo: instrument detail: %w", err))
+		return e.Progress().Err
+	}
+	e.mu.Lock()
+	e.inst = inst
+	if e.opts.MinChildQty <= 0 {
+		e.opts.MinChildQty = inst.LotSize()
+	}
+	e.mu.Unlock()
+
+	ticker := time.NewTicker(e.opts.Interval)
+	defer ticker.Stop()
+	e.step(ctx)
+	for {
+		if e.done() {
+			return e.Progress().Err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			e.OnOrderReport(ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			e.OnMarketData(ev)
+		case <-ticker.C:
+		}
+		e.step(ctx)
+	}
+}
+
+// OnOrderReport actualiza el estado de las hijas con un Execution Report. Un rechazo
+// del mercado detiene el algoritmo (StateFailed) para no reenviar la misma hija en bucle.
+func (e *Executor) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, lastQty := e.kids.apply(ev.OrderReport)
+	if obs, ok := e.sched.(FillObserver); ok && lastQty > 0 {
+		obs.OnFill(lastQty)
+	}
+	if c != nil && c.status == model.StatusRejected && !e.state.Done() {
+		text := ""
+		if ev.OrderReport.Text != nil {
+			text = *ev.OrderReport.Text
+		}
+		e.err = fmt.Errorf("algo: child %s rejected: %s", c.clOrdID, text)
+		e.state = StateFailed
+	}
+}
+
+// OnMarketData actualiza el libro del instrumento y el schedule (si observa Market Data).
+func (e *Executor) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil || ev.InstrumentID != e.parent.instrumentID() {
+		return
+	}
+	e.mu.Lock()
+	e.quote.update(ev.MarketData)
+	e.mu.Unlock()
+	if obs, ok := e.sched.(MarketDataObserver); ok {
+		obs.OnMarketData(ev.MarketData)
+	}
+}
+
+// Progress devuelve el avance del algoritmo.
+func (e *Executor) Progress() Progress {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return Progress{
+		State:    e.state,
+		Qty:      e.parent.Qty,
+		Target:   e.target,
+		Filled:   e.kids.filled,
+		Working:  e.kids.workingQty(),
+		AvgPx:    e.kids.avgPx(),
+		Children: len(e.kids.order),
+		Err:      e.err,
+	}
+}
+
+// Cancel detiene el algoritmo y cancela las hijas activas. Lo ya operado se mantiene.
+// Run vuelve cuando el mercado confirma la cancelación de todas las hijas.
+func (e *Executor) Cancel(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state.Done() {
+		return nil
+	}
+	e.state = StateCancelled
+	return e.cancelWorkingLocked(ctx, nil)
+}
+
+// Finish abandona el schedule y envía el remanente de inmediato, cancelando antes
+// las hijas activas. El precio sigue acotado por el LimitPrice de la padre.
+func (e *Executor) Finish(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.state != StateWorking {
+		return nil
+	}
+	e.state = StateFinishing
+	return e.cancelWorkingLocked(ctx, nil)
+}
+
+func (e *Executor) done() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state.Done() && len(e.kids.working()) == 0
+}
+
+func (e *Executor) fail(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.err = err
+	e.state = StateFailed
+}
+
+// step reevalúa el schedule y envía, cancela o re-cotiza hijas.
+func (e *Executor) step(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	if e.kids.filled >= e.parent.Qty && !e.state.Done() {
+		e.state = StateCompleted
+	}
+	if e.state.Done() {
+		// Un algoritmo fallido no deja hijas trabajando en el mercado.
+		_ = e.cancelWorkingLocked(ctx, nil)
+		return
+	}
+
+	working := e.kids.working()
+	for _, c := range working {
+		if c.cancelling || c == e.finisher {
+			continue
+		}
+		if now.Sub(c.sent) >= e.opts.ChildTTL {
+			_ = e.cancelLocked(ctx, c)
+		}
+	}
+	if len(working) > 0 {
+		return
+	}
+
+	target := e.parent.Qty
+	if e.state == StateWorking {
+		target = e.sched.Target(now, e.parent.Qty)
+	}
+	e.target = target
+	qty := target - e.kids.filled
+	if e.state == StateWorking {
+		qty = e.inst.RoundQty(qty)
+		if qty < e.opts.MinChildQty {
+			return
+		}
+	}
+	if qty <= 0 {
+		return
+	}
+	c, err := e.sendLocked(ctx, qty)
+	if err != nil {
+		e.err = err
+		e.logWarn("child order failed", slog.Any("err", err))
+		if errors.Is(err, rofex.ErrTradingHalted) || errors.As(err, new(*rofex.OrderRejectedError)) {
+			e.state = StateFailed
+		}
+		return
+	}
+	if e.state == StateFinishing {
+		e.finisher = c
+	}
+}
+
+// childPrice devuelve el precio de la próxima hija (nil = MARKET).
+func (e *Executor) childPrice() *float64 {
+	side := e.parent.Side
+	ref := e.quote.touch(opposite(side))
+	if e.opts.Passive {
+		ref = e.quote.touch(side)
+	}
+	if ref == 0 {
+		if e.parent.LimitPrice == nil {
+			return nil
+		}
+		ref = *e.parent.LimitPrice
+	}
+	px := e.inst.RoundPrice(capPrice(ref, side, e.parent.LimitPrice), side)
+	return &px
+}
+
+func (e *Executor) sendLocked(ctx context.Context, qty int64) (*child, error) {
+	o := rofex.NewOrder{
+		Symbol:  e.parent.Symbol,
+		Market:  e.parent.Market,
+		Side:    e.parent.Side,
+		Type:    model.OrderTypeLimit,
+		Qty:     qty,
+		Price:   e.childPrice(),
+		TIF:     e.opts.TIF,
+		Account: e.parent.Account,
+	}
+	if o.Price == nil {
+		o.Type = model.OrderTypeMarket
+		o.TIF = model.ImmediateOrCancel
+	}
+	res, err := e.client.SendOrder(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if err := sendRejected(res); err != nil {
+		return nil, err
+	}
+	c := &child{
+		clOrdID: res.Order.ClientID,
+		qty:     qty,
+		leaves:  qty,
+		price:   o.Price,
+		status:  model.StatusPendingNew,
+		sent:    e.now(),
+	}
+	e.kids.add(c)
+	return c, nil
+}
+
+func (e *Executor) cancelWorkingLocked(ctx context.Context, except *child) error {
+	var errs []error
+	for _, c := range e.kids.working() {
+		if c == except || c.cancelling {
+			continue
+		}
+		if err := e.cancelLocked(ctx, c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *Executor) cancelLocked(ctx context.Context, c *child) error {
+	if _, err := e.client.CancelOrder(ctx, c.clOrdID, ""); err != nil {
+		e.logWarn("child cancel failed", slog.String("clOrdId", c.clOrdID), slog.Any("err", err))
+		return fmt.Errorf("cancel %s: %w", c.clOrdID, err)
+	}
+	c.cancelling = true
+	return nil
+}
+
+func (e *Executor) logWarn(msg string, args ...any) {
+	if e.opts.Logger != nil {
+		e.opts.Logger.Warn(msg, append(args, slog.String("symbol", e.parent.Symbol))...)
+	}
+}
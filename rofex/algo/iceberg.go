@@ -0,0 +1,319 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// PricePolicy define a qué precio se repone cada porción visible de un iceberg.
+type PricePolicy string
+
+const (
+	// PriceFixed repone siempre al LimitPrice de la padre.
+	PriceFixed PricePolicy = "FIXED"
+	// PriceJoin se une a la mejor punta del propio lado, sin superar el LimitPrice.
+	PriceJoin PricePolicy = "JOIN"
+	// PriceImprove mejora en un tick la mejor punta del propio lado, sin cruzar el libro
+	// ni superar el LimitPrice.
+	PriceImprove PricePolicy = "IMPROVE"
+)
+
+// IcebergOptions configura un Iceberg.
+type IcebergOptions struct {
+	DisplayQty int64       // Cantidad visible nominal de cada porción
+	Variance   float64     // Variación aleatoria (0..1) de la cantidad visible, ej. 0.2 = ±20%
+	Policy     PricePolicy // Política de precio al reponer (por defecto PriceFixed)
+	Seed       int64       // Semilla del generador (0 = derivada del reloj)
+	TIF        model.TimeInForce
+	Logger     *slog.Logger
+}
+
+// Iceberg emula del lado del cliente una orden iceberg (reserva): muestra en el mercado
+// solo una porción de la orden padre con SendOrder y, cuando esa porción se opera por
+// completo (Execution Reports de SubscribeOrderReport), envía la siguiente hasta completar
+// el total. Sirve para instrumentos donde NewOrder.Iceberg/DisplayQty no es soportado
+// por el mercado (por ejemplo, gran parte de MERV).
+//
+// Cada porción se envía como LIMIT al precio que indique IcebergOptions.Policy, siempre
+// acotado por el LimitPrice de la padre y ajustado a la grilla de ticks y lotes.
+//
+// Uso:
+//
+//	ice, _ := algo.NewIceberg(client, algo.Parent{Account: "REM6771", Symbol: "MERV - XMEV - GGAL - 48hs",
+//		Market: model.MarketROFEX, Side: model.Sell, Qty: 10000, LimitPrice: &px},
+//		algo.IcebergOptions{DisplayQty: 500, Variance: 0.2, Policy: algo.PriceJoin})
+//	go ice.Run(ctx, orders.Events, md.Events)
+type Iceberg struct {
+	client *rofex.Client
+	parent Parent
+	opts   IcebergOptions
+
+	mu    sync.Mutex
+	inst  model.Instrument
+	state State
+	kids  *children
+	quote quote
+	rnd   *rand.Rand
+	err   error
+}
+
+// NewIceberg valida la orden padre y crea el Iceberg. La padre requiere LimitPrice.
+func NewIceberg(c *rofex.Client, parent Parent, opts IcebergOptions) (*Iceberg, error) {
+	if parent.Account == "" {
+		return nil, &rofex.ValidationError{Field: "account", Msg: "required"}
+	}
+	if parent.Symbol == "" {
+		return nil, &rofex.ValidationError{Field: "symbol", Msg: "required"}
+	}
+	if parent.Side != model.Buy && parent.Side != model.Sell {
+		return nil, &rofex.ValidationError{Field: "side", Msg: "must be BUY or SELL"}
+	}
+	if parent.Qty <= 0 {
+		return nil, &rofex.ValidationError{Field: "qty", Msg: "must be > 0"}
+	}
+	if parent.LimitPrice == nil {
+		return nil, &rofex.ValidationError{Field: "limitPrice", Msg: "required for iceberg"}
+	}
+	if opts.DisplayQty <= 0 || opts.DisplayQty > parent.Qty {
+		return nil, &rofex.ValidationError{Field: "displayQty", Msg: "must be > 0 and <= qty"}
+	}
+	if parent.Market == "" {
+		parent.Market = model.MarketROFEX
+	}
+	if opts.Policy == "" {
+		opts.Policy = PriceFixed
+	}
+	if opts.TIF == "" {
+		opts.TIF = model.Day
+	}
+	seed := opts.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Iceberg{
+		client: c,
+		parent: parent,
+		opts:   opts,
+		state:  StateWorking,
+		kids:   newChildren(),
+		rnd:    rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Run envía la primera porción y repone las siguientes a medida que se operan, hasta
+// completar la padre, cancelarla o ante un rechazo. md puede ser nil con PriceFixed.
+func (ice *Iceberg) Run(ctx context.Context, orders <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) error {
+	inst, err := ice.client.Instrument(ctx, ice.parent.Symbol, ice.parent.Market)
+	if err != nil {
+		ice.mu.Lock()
+		ice.err, ice.state = fmt.Errorf("iceberg: instrument detail: %w", err), StateFailed
+		ice.mu.Unlock()
+		return ice.Progress().Err
+	}
+	ice.mu.Lock()
+	ice.inst = inst
+	ice.mu.Unlock()
+
+	ice.replenish(ctx)
+	for {
+		if ice.done() {
+			return ice.Progress().Err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			ice.OnOrderReport(ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			ice.OnMarketData(ev)
+			continue
+		}
+		ice.replenish(ctx)
+	}
+}
+
+// OnOrderReport actualiza la porción visible. Un rechazo o una cancelación que no pidió
+// el Iceberg lo detienen (StateFailed).
+func (ice *Iceberg) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil {
+		return
+	}
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	c, _ := ice.kids.apply(ev.OrderReport)
+	if c == nil || ice.state.Done() {
+		return
+	}
+	switch {
+	case c.status == model.StatusRejected:
+		ice.err, ice.state = fmt.Errorf("iceberg: slice %s rejected", c.clOrdID), StateFailed
+	case c.status == model.StatusCancelled && !c.cancelling:
+		ice.err, ice.state = fmt.Errorf("iceberg: slice %s cancelled externally", c.clOrdID), StateFailed
+	}
+}
+
+// OnMarketData actualiza el libro usado por las políticas PriceJoin y PriceImprove.
+func (ice *Iceberg) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil || ev.InstrumentID != ice.parent.instrumentID() {
+		return
+	}
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	ice.quote.update(ev.MarketData)
+}
+
+// Progress devuelve el avance de la padre. Working es la cantidad visible en el mercado.
+func (ice *Iceberg) Progress() Progress {
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	return Progress{
+		State:    ice.state,
+		Qty:      ice.parent.Qty,
+		Target:   ice.parent.Qty,
+		Filled:   ice.kids.filled,
+		Working:  ice.kids.workingQty(),
+		AvgPx:    ice.kids.avgPx(),
+		Children: len(ice.kids.order),
+		Err:      ice.err,
+	}
+}
+
+// Cancel cancela la padre: no se reponen más porciones y se cancela la visible.
+// Run vuelve cuando el mercado confirma la cancelación.
+func (ice *Iceberg) Cancel(ctx context.Context) error {
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	if ice.state.Done() {
+		return nil
+	}
+	ice.state = StateCancelled
+	return ice.cancelWorkingLocked(ctx)
+}
+
+func (ice *Iceberg) done() bool {
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	return ice.state.Done() && len(ice.kids.working()) == 0
+}
+
+// replenish envía la siguiente porción si no hay una visible.
+func (ice *Iceberg) replenish(ctx context.Context) {
+	ice.mu.Lock()
+	defer ice.mu.Unlock()
+	remaining := ice.parent.Qty - ice.kids.filled
+	if remaining <= 0 && !ice.state.Done() {
+		ice.state = StateCompleted
+	}
+	if ice.state.Done() {
+		_ = ice.cancelWorkingLocked(ctx)
+		return
+	}
+	if len(ice.kids.working()) > 0 {
+		return
+	}
+
+	qty := ice.displayQty(remaining)
+	px := ice.slicePrice()
+	o := rofex.NewOrder{
+		Symbol:  ice.parent.Symbol,
+		Market:  ice.parent.Market,
+		Side:    ice.parent.Side,
+		Type:    model.OrderTypeLimit,
+		Qty:     qty,
+		Price:   &px,
+		TIF:     ice.opts.TIF,
+		Account: ice.parent.Account,
+	}
+	res, err := ice.client.SendOrder(ctx, o)
+	if err == nil {
+		err = sendRejected(res)
+	}
+	if err != nil {
+		ice.err, ice.state = fmt.Errorf("iceberg: send slice: %w", err), StateFailed
+		if ice.opts.Logger != nil {
+			ice.opts.Logger.Warn("iceberg slice failed", slog.String("symbol", ice.parent.Symbol), slog.Any("err", err))
+		}
+		return
+	}
+	ice.kids.add(&child{
+		clOrdID: res.Order.ClientID,
+		qty:     qty,
+		leaves:  qty,
+		price:   &px,
+		status:  model.StatusPendingNew,
+		sent:    time.Now(),
+	})
+}
+
+// displayQty calcula la próxima porción visible: DisplayQty ± Variance, ajustada al lote
+// y nunca mayor que el remanente.
+func (ice *Iceberg) displayQty(remaining int64) int64 {
+	qty := float64(ice.opts.DisplayQty)
+	if v := math.Min(math.Max(ice.opts.Variance, 0), 1); v > 0 {
+		qty *= 1 + v*(2*ice.rnd.Float64()-1)
+	}
+	n := ice.inst.RoundQty(int64(math.Round(qty)))
+	if lot := ice.inst.LotSize(); n < lot {
+		n = lot
+	}
+	if n > remaining {
+		n = remaining
+	}
+	return n
+}
+
+// slicePrice aplica la política de precio, acotada por el límite de la padre.
+func (ice *Iceberg) slicePrice() float64 {
+	side := ice.parent.Side
+	limit := *ice.parent.LimitPrice
+	px := limit
+	if touch := ice.quote.touch(side); touch > 0 && ice.opts.Policy != PriceFixed {
+		px = touch
+		if ice.opts.Policy == PriceImprove {
+			tick := ice.inst.TickAt(touch)
+			improved := touch + tick
+			if side == model.Sell {
+				improved = touch - tick
+			}
+			// Mejorar sin cruzar el libro
+			if opp := ice.quote.touch(opposite(side)); opp == 0 ||
+				(side == model.Buy && improved < opp) || (side == model.Sell && improved > opp) {
+				px = improved
+			}
+		}
+		px = capPrice(px, side, &limit)
+	}
+	return ice.inst.RoundPrice(px, side)
+}
+
+func (ice *Iceberg) cancelWorkingLocked(ctx context.Context) error {
+	var errs []error
+	for _, c := range ice.kids.working() {
+		if c.cancelling {
+			continue
+		}
+		if _, err := ice.client.CancelOrder(ctx, c.clOrdID, ""); err != nil {
+			errs = append(errs, fmt.Errorf("cancel %s: %w", c.clOrdID, err))
+			continue
+		}
+		c.cancelling = true
+	}
+	return errors.Join(errs...)
+}
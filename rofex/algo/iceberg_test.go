@@ -0,0 +1,125 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestIceberg_ReplenishImproveAndCancel(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	limit := 1000.3
+	ice, err := NewIceberg(c, Parent{Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Buy, Qty: 100, LimitPrice: &limit},
+		IcebergOptions{DisplayQty: 30, Policy: PriceImprove})
+	if err != nil {
+		t.Fatalf("NewIceberg: %v", err)
+	}
+	id := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	book := func(bid, ask float64) *model.MarketDataEvent {
+		return &model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+			Bids: []model.BookLevel{{Price: bid, Size: 10}}, Offers: []model.BookLevel{{Price: ask, Size: 10}},
+		}}
+	}
+	// Libro previo al arranque: la primera porción mejora la punta compradora en un tick
+	ice.OnMarketData(book(999, 1001))
+
+	orders := make(chan *model.OrderReportEvent, 4)
+	md := make(chan *model.MarketDataEvent, 4)
+	done := make(chan error, 1)
+	go func() { done <- ice.Run(ctx, orders, md) }()
+	waitChildren := func(n int) {
+		for ice.Progress().Children < n {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitChildren(1)
+	orders <- report("ch1", "PARTIALLY_FILLED", "e1", 10, 999.5, 20)
+	orders <- report("ch1", "FILLED", "e2", 20, 999.5, 0)
+	waitChildren(2)
+
+	// La punta sube por encima del límite: la reposición queda acotada al límite en el tick
+	md <- book(1000.5, 1001)
+	for bid := 0.0; bid != 1000.5; {
+		ice.mu.Lock()
+		bid = ice.quote.bid
+		ice.mu.Unlock()
+	}
+	orders <- report("ch2", "FILLED", "e3", 30, 999.5, 0)
+	waitChildren(3)
+
+	if err := ice.Cancel(ctx); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	orders <- report("ch3", "CANCELLED", "", 0, 0, 0)
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := []string{
+		"new LIMIT 30 999.5",
+		"new LIMIT 30 999.5",
+		"new LIMIT 30 1000",
+		"cancel ch3",
+	}
+	if got := v.orders(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("orders:\nwant %v\ngot  %v", want, got)
+	}
+	if p := ice.Progress(); p.State != StateCancelled || p.Filled != 60 || p.AvgPx != 999.5 || p.Working != 0 {
+		t.Fatalf("progress: %+v", p)
+	}
+}
+
+func TestIceberg_ErrorStatusFails(t *testing.T) {
+	v := newVenue(t, nil)
+	v.reject = map[string]bool{"/rest/order/newSingleOrder": true}
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	limit := 1000.0
+	ice, err := NewIceberg(c, Parent{Account: "REM6771", Symbol: "DLR/MAR26", Side: model.Buy, Qty: 100, LimitPrice: &limit},
+		IcebergOptions{DisplayQty: 30})
+	if err != nil {
+		t.Fatalf("NewIceberg: %v", err)
+	}
+	// HTTP 200 con status ERROR: Run termina con el error en lugar de esperar una porción fantasma
+	if err := ice.Run(ctx, nil, nil); !errors.As(err, new(*rofex.OrderRejectedError)) {
+		t.Fatalf("Run: %v", err)
+	}
+	if p := ice.Progress(); p.State != StateFailed || p.Children != 0 {
+		t.Fatalf("progress: %+v", p)
+	}
+}
+
+func TestIceberg_RandomizedDisplay(t *testing.T) {
+	limit := 100.0
+	ice, _ := NewIceberg(nil, Parent{Account: "A", Symbol: "S", Side: model.Sell, Qty: 1000, LimitPrice: &limit},
+		IcebergOptions{DisplayQty: 100, Variance: 0.3, Seed: 1})
+	lot := 5.0
+	ice.inst = model.Instrument{RoundLot: &lot}
+
+	seen := map[int64]bool{}
+	for i := 0; i < 50; i++ {
+		q := ice.displayQty(1000)
+		if q < 70 || q > 130 || q%5 != 0 {
+			t.Fatalf("display qty out of bounds: %d", q)
+		}
+		seen[q] = true
+	}
+	if len(seen) < 3 {
+		t.Fatalf("display qty not randomized: %v", seen)
+	}
+	if q := ice.displayQty(12); q != 12 {
+		t.Fatalf("last slice must be the remainder: %d", q)
+	}
+}
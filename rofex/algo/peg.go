@@ -0,0 +1,381 @@
+package algo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// PegType es el precio de referencia de una orden pegada.
+type PegType string
+
+const (
+	// PegPrimary sigue la mejor punta del propio lado (BI para compras, OF para ventas).
+	PegPrimary PegType = "PRIMARY"
+	// PegMarket sigue la mejor punta del lado contrario (OF para compras, BI para ventas).
+	PegMarket PegType = "MARKET"
+	// PegMid sigue el punto medio entre BI y OF.
+	PegMid PegType = "MID"
+)
+
+// PegRule define cómo se reprecia una orden pegada.
+//
+// Offset se expresa en ticks hacia el lado agresivo: en una compra +1 es un tick por
+// encima de la referencia y -1 un tick por debajo; en una venta, al revés. Limit es el
+// peor precio aceptable (máximo para compras, mínimo para ventas).
+type PegRule struct {
+	Type   PegType
+	Offset int
+	Limit  *float64
+}
+
+// PegOptions configura un PegManager.
+type PegOptions struct {
+	MinReplaceInterval time.Duration // Tiempo mínimo entre reemplazos de una misma orden (por defecto 1s)
+	Logger             *slog.Logger
+}
+
+// PeggedOrder describe una orden pegada.
+type PeggedOrder struct {
+	ID       string // clOrdID original con el que se pegó la orden
+	ClOrdID  string // clOrdID vigente luego de los reemplazos
+	Symbol   string
+	Side     model.Side
+	Price    float64
+	Rule     PegRule
+	Replaces int
+}
+
+type pegged struct {
+	PeggedOrder
+	instrument  model.InstrumentID
+	proprietary string
+	inst        model.Instrument
+	leaves      int64
+	pending     string // clOrdID del reemplazo pendiente de confirmación
+	replacing   bool   // ReplaceOrder en curso
+	lastReplace time.Time
+}
+
+// book guarda los niveles del libro de un instrumento.
+type book struct {
+	bids, offers []model.BookLevel
+}
+
+// PegManager mantiene órdenes en el libro pegadas a una referencia (mejor punta propia,
+// punta contraria o punto medio) más un offset en ticks, reemplazándolas con ReplaceOrder
+// cada vez que la referencia se mueve.
+//
+// Los precios se ajustan a la grilla de ticks del instrumento del lado conservador y
+// nunca superan PegRule.Limit. Cada orden se reemplaza como máximo una vez cada
+// PegOptions.MinReplaceInterval; los movimientos del libro durante ese intervalo se
+// aplican al vencer. El manager sigue la cadena de clOrdID de cada reemplazo a través de
+// los Execution Reports (CANCELLED "Reemplazada" para el anterior, NEW para el nuevo) y
+// libera la orden cuando se opera por completo o se cancela.
+//
+// Para PegPrimary, si la mejor punta propia es la misma orden pegada se toma el nivel
+// siguiente, de modo de no perseguir el propio precio (suscribir depth >= 2).
+//
+// Uso:
+//
+//	pm := algo.NewPegManager(client, algo.PegOptions{})
+//	go pm.Watch(ctx, orders.Events, md.Events)
+//	err := pm.Peg(ctx, clOrdID, "", algo.PegRule{Type: algo.PegPrimary, Offset: 1, Limit: &max})
+type PegManager struct {
+	client *rofex.Client
+	opts   PegOptions
+
+	mu    sync.Mutex
+	pegs  map[string]*pegged // por clOrdID (original, vigente y pendiente)
+	books map[model.InstrumentID]*book
+	now   func() time.Time
+}
+
+// NewPegManager crea un PegManager.
+func NewPegManager(c *rofex.Client, opts PegOptions) *PegManager {
+	if opts.MinReplaceInterval <= 0 {
+		opts.MinReplaceInterval = time.Second
+	}
+	return &PegManager{
+		client: c,
+		opts:   opts,
+		pegs:   make(map[string]*pegged),
+		books:  make(map[model.InstrumentID]*book),
+		now:    time.Now,
+	}
+}
+
+// Peg pega una orden activa del mercado. El estado de la orden (símbolo, lado, precio y
+// remanente) se consulta con OrderStatus. La primera repreciación ocurre con la
+// siguiente Market Data del instrumento.
+func (m *PegManager) Peg(ctx context.Context, clOrdID, proprietary string, rule PegRule) error {
+	switch rule.Type {
+	case PegPrimary, PegMarket, PegMid:
+	default:
+		return &rofex.ValidationError{Field: "type", Msg: "must be PRIMARY, MARKET or MID"}
+	}
+	st, err := m.client.OrderStatus(ctx, clOrdID, proprietary)
+	if err != nil {
+		return fmt.Errorf("peg: order status: %w", err)
+	}
+	o := st.Order
+	if !model.OrderStatus(o.Status).IsActive() {
+		return &rofex.ValidationError{Field: "clOrdID", Msg: "order is not active: " + o.Status}
+	}
+	if o.Price == nil {
+		return &rofex.ValidationError{Field: "clOrdID", Msg: "order has no limit price"}
+	}
+	inst, err := m.client.Instrument(ctx, o.InstrumentID.Symbol, model.Market(o.InstrumentID.MarketID))
+	if err != nil {
+		return fmt.Errorf("peg: instrument detail: %w", err)
+	}
+	if proprietary == "" {
+		proprietary = o.Proprietary
+	}
+	p := &pegged{
+		PeggedOrder: PeggedOrder{
+			ID:      clOrdID,
+			ClOrdID: clOrdID,
+			Symbol:  o.InstrumentID.Symbol,
+			Side:    o.Side,
+			Price:   *o.Price,
+			Rule:    rule,
+		},
+		instrument:  o.InstrumentID,
+		proprietary: proprietary,
+		inst:        inst,
+	}
+	if o.LeavesQty != nil {
+		p.leaves = *o.LeavesQty
+	}
+	m.mu.Lock()
+	m.pegs[clOrdID] = p
+	m.mu.Unlock()
+	return nil
+}
+
+// Unpeg deja de repreciar la orden (por su clOrdID original o vigente). La orden sigue en el mercado.
+func (m *PegManager) Unpeg(clOrdID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.pegs[clOrdID]; ok {
+		m.removeLocked(p)
+	}
+}
+
+// Pegged devuelve las órdenes pegadas, ordenadas por ID.
+func (m *PegManager) Pegged() []PeggedOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	seen := make(map[*pegged]bool)
+	var out []PeggedOrder
+	for _, p := range m.pegs {
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p.PeggedOrder)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Watch procesa Execution Reports y Market Data hasta que ctx se cancele. Cada
+// MinReplaceInterval reevalúa las órdenes cuyo reemplazo quedó demorado.
+func (m *PegManager) Watch(ctx context.Context, orders <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) {
+	ticker := time.NewTicker(m.opts.MinReplaceInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			m.OnOrderReport(ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			m.OnMarketData(ctx, ev)
+		case <-ticker.C:
+			m.reprice(ctx, nil)
+		}
+	}
+}
+
+// OnOrderReport sigue la cadena de reemplazos y libera las órdenes terminadas.
+func (m *PegManager) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil {
+		return
+	}
+	rep := ev.OrderReport
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.pegs[rep.ClOrdID]
+	if !ok {
+		return
+	}
+	status := model.OrderStatus(rep.Status)
+	switch rep.ClOrdID {
+	case p.pending:
+		if status == model.StatusRejected {
+			// Reemplazo rechazado: la orden anterior sigue vigente
+			delete(m.pegs, p.pending)
+			p.pending = ""
+			return
+		}
+		p.ClOrdID, p.pending = rep.ClOrdID, ""
+		p.Replaces++
+	case p.ClOrdID:
+		if rep.IsReplaced() {
+			return
+		}
+	default:
+		return // reporte de un clOrdID anterior de la cadena
+	}
+	if rep.Price != nil {
+		p.Price = *rep.Price
+	}
+	if rep.LeavesQty != nil {
+		p.leaves = int64(*rep.LeavesQty)
+	}
+	if status.IsTerminal() {
+		m.removeLocked(p)
+	}
+}
+
+// OnMarketData actualiza el libro del instrumento y reprecia sus órdenes pegadas.
+func (m *PegManager) OnMarketData(ctx context.Context, ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	m.mu.Lock()
+	b, ok := m.books[ev.InstrumentID]
+	if !ok {
+		b = &book{}
+		m.books[ev.InstrumentID] = b
+	}
+	if ev.MarketData.Bids != nil {
+		b.bids = ev.MarketData.Bids
+	}
+	if ev.MarketData.Offers != nil {
+		b.offers = ev.MarketData.Offers
+	}
+	m.mu.Unlock()
+	m.reprice(ctx, &ev.InstrumentID)
+}
+
+type replacement struct {
+	p     *pegged
+	from  string
+	price float64
+}
+
+// reprice reemplaza las órdenes (del instrumento indicado, o todas) cuyo precio objetivo cambió.
+func (m *PegManager) reprice(ctx context.Context, only *model.InstrumentID) {
+	m.mu.Lock()
+	now := m.now()
+	var todo []replacement
+	for id, p := range m.pegs {
+		if id != p.ID || p.pending != "" || p.replacing {
+			continue
+		}
+		if only != nil && p.instrument != *only {
+			continue
+		}
+		if now.Sub(p.lastReplace) < m.opts.MinReplaceInterval {
+			continue
+		}
+		px, ok := m.targetLocked(p)
+		if !ok || math.Abs(px-p.Price) < 1e-9 {
+			continue
+		}
+		p.replacing = true
+		p.lastReplace = now
+		todo = append(todo, replacement{p: p, from: p.ClOrdID, price: px})
+	}
+	m.mu.Unlock()
+
+	for _, r := range todo {
+		px := r.price
+		res, err := m.client.ReplaceOrder(ctx, r.from, r.p.proprietary, nil, &px)
+		m.mu.Lock()
+		r.p.replacing = false
+		if err != nil {
+			if m.opts.Logger != nil {
+				m.opts.Logger.Warn("peg replace failed", slog.String("clOrdId", r.from), slog.Any("err", err))
+			}
+		} else if id := res.Order.ClientID; id != "" && m.pegs[r.p.ID] == r.p {
+			r.p.pending = id
+			m.pegs[id] = r.p
+		}
+		m.mu.Unlock()
+	}
+}
+
+// targetLocked calcula el precio objetivo de una orden pegada según el libro actual.
+func (m *PegManager) targetLocked(p *pegged) (float64, bool) {
+	b, ok := m.books[p.instrument]
+	if !ok {
+		return 0, false
+	}
+	side := p.Side
+	var ref float64
+	switch p.Rule.Type {
+	case PegPrimary:
+		levels := b.bids
+		if side == model.Sell {
+			levels = b.offers
+		}
+		// Si la mejor punta es la propia orden, referenciar el nivel siguiente
+		if len(levels) > 0 && math.Abs(levels[0].Price-p.Price) < 1e-9 && levels[0].Size <= float64(p.leaves) {
+			levels = levels[1:]
+		}
+		if len(levels) == 0 {
+			return 0, false
+		}
+		ref = levels[0].Price
+	case PegMarket:
+		levels := b.offers
+		if side == model.Sell {
+			levels = b.bids
+		}
+		if len(levels) == 0 {
+			return 0, false
+		}
+		ref = levels[0].Price
+	case PegMid:
+		if len(b.bids) == 0 || len(b.offers) == 0 {
+			return 0, false
+		}
+		ref = (b.bids[0].Price + b.offers[0].Price) / 2
+	}
+	px := ref
+	if p.Rule.Offset != 0 {
+		step := float64(p.Rule.Offset) * p.inst.TickAt(ref)
+		if side == model.Sell {
+			step = -step
+		}
+		px += step
+	}
+	px = p.inst.RoundPrice(capPrice(px, side, p.Rule.Limit), side)
+	return px, px > 0
+}
+
+func (m *PegManager) removeLocked(p *pegged) {
+	for id, q := range m.pegs {
+		if q == p {
+			delete(m.pegs, id)
+		}
+	}
+}
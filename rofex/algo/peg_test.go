@@ -0,0 +1,77 @@
+package algo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestPegManager_RepriceThrottleAndChain(t *testing.T) {
+	v := newVenue(t, nil)
+	v.status = map[string]any{
+		"clOrdId": "o1", "proprietary": "PBCP", "status": "NEW", "side": "BUY", "price": 999.0, "leavesQty": 10,
+		"instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/MAR26"},
+	}
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	pm := NewPegManager(c, PegOptions{})
+	now := time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)
+	pm.now = func() time.Time { return now }
+
+	limit := 1001.0
+	if err := pm.Peg(ctx, "o1", "", PegRule{Type: PegPrimary, Offset: 1, Limit: &limit}); err != nil {
+		t.Fatalf("Peg: %v", err)
+	}
+	id := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	md := func(bids ...model.BookLevel) *model.MarketDataEvent {
+		return &model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{Bids: bids}}
+	}
+	rep := func(clOrdID, status string, price float64) *model.OrderReportEvent {
+		leaves := 10
+		return &model.OrderReportEvent{OrderReport: model.OrderDetails{ClOrdID: clOrdID, Status: status, Price: &price, LeavesQty: &leaves}}
+	}
+
+	// Mejor compra 999.5 (ajena): la orden pasa a 1000 (un tick de 0.5 arriba)
+	pm.OnMarketData(ctx, md(model.BookLevel{Price: 999.5, Size: 3}, model.BookLevel{Price: 999, Size: 10}))
+	replaced := rep("o1", "CANCELLED", 999)
+	text := "Reemplazada"
+	replaced.OrderReport.Text = &text
+	pm.OnOrderReport(replaced)
+	pm.OnOrderReport(rep("rp1", "NEW", 1000))
+
+	// Nuestra orden es la mejor compra: se referencia el nivel siguiente y no hay cambio
+	pm.OnMarketData(ctx, md(model.BookLevel{Price: 1000, Size: 10}, model.BookLevel{Price: 999.5, Size: 3}))
+
+	// La referencia sube dentro del intervalo mínimo: el reemplazo se demora
+	now = now.Add(500 * time.Millisecond)
+	pm.OnMarketData(ctx, md(model.BookLevel{Price: 1000.5, Size: 4}, model.BookLevel{Price: 1000, Size: 10}))
+	now = now.Add(600 * time.Millisecond)
+	pm.reprice(ctx, nil)
+	// Acotado por el límite en 1001
+	pm.OnOrderReport(rep("rp2", "NEW", 1001))
+
+	// Un reemplazo rechazado deja vigente la orden anterior
+	now = now.Add(2 * time.Second)
+	pm.OnMarketData(ctx, md(model.BookLevel{Price: 999, Size: 4}))
+	pm.OnOrderReport(rep("rp3", "REJECTED", 999.5))
+
+	pegged := pm.Pegged()
+	if len(pegged) != 1 || pegged[0].ClOrdID != "rp2" || pegged[0].Price != 1001 || pegged[0].Replaces != 2 {
+		t.Fatalf("pegged: %+v", pegged)
+	}
+
+	want := []string{"replace o1 1000", "replace rp1 1001", "replace rp2 999.5"}
+	if got := v.orders(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("orders:\nwant %v\ngot  %v", want, got)
+	}
+
+	pm.OnOrderReport(rep("rp2", "FILLED", 1001))
+	if got := pm.Pegged(); len(got) != 0 {
+		t.Fatalf("filled order still pegged: %+v", got)
+	}
+}
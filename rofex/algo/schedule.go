@@ -0,0 +1,260 @@
+package algo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Schedule define cuánto de la orden padre debería estar ejecutado a cada momento.
+type Schedule interface {
+	// Target devuelve la cantidad acumulada objetivo (0..total) al instante now.
+	Target(now time.Time, total int64) int64
+}
+
+// MarketDataObserver es implementado por los schedules que dependen de Market Data en vivo (POV).
+type MarketDataObserver interface {
+	OnMarketData(md model.MarketData)
+}
+
+// FillObserver es implementado por los schedules que necesitan conocer lo operado por
+// el propio algoritmo (POV, para no contarlo como volumen del mercado).
+type FillObserver interface {
+	OnFill(qty int64)
+}
+
+// TWAP reparte la orden en Slices tramos iguales entre Start y End. Cada tramo se
+// libera al comienzo de su intervalo. Randomize (0..1) varía el tamaño de cada tramo
+// en ±Randomize de su tamaño nominal para no dejar un patrón regular en el libro.
+type TWAP struct {
+	Start     time.Time
+	End       time.Time
+	Slices    int
+	Randomize float64
+	Seed      int64 // Semilla del generador (0 = derivada de Start)
+
+	once    sync.Once
+	weights []float64 // fracción acumulada al final de cada tramo
+}
+
+// Target implementa Schedule.
+func (t *TWAP) Target(now time.Time, total int64) int64 {
+	t.once.Do(t.init)
+	if now.Before(t.Start) {
+		return 0
+	}
+	if !now.Before(t.End) {
+		return total
+	}
+	n := len(t.weights)
+	idx := int(float64(n) * float64(now.Sub(t.Start)) / float64(t.End.Sub(t.Start)))
+	if idx >= n {
+		idx = n - 1
+	}
+	return int64(math.Round(t.weights[idx] * float64(total)))
+}
+
+func (t *TWAP) init() {
+	n := t.Slices
+	if n < 1 {
+		n = 1
+	}
+	seed := t.Seed
+	if seed == 0 {
+		seed = t.Start.UnixNano()
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	jitter := math.Min(math.Max(t.Randomize, 0), 1)
+	sizes := make([]float64, n)
+	var sum float64
+	for i := range sizes {
+		sizes[i] = 1 + jitter*(2*rnd.Float64()-1)
+		sum += sizes[i]
+	}
+	t.weights = make([]float64, n)
+	var acc float64
+	for i, s := range sizes {
+		acc += s / sum
+		t.weights[i] = acc
+	}
+	t.weights[n-1] = 1
+}
+
+// VolumeProfile es la distribución del volumen operado a lo largo de la rueda,
+// en intervalos de Bucket desde la medianoche (en Location, por defecto rofex.MarketLocation).
+type VolumeProfile struct {
+	Bucket   time.Duration
+	Location *time.Location
+	Volume   map[int]float64 // índice de intervalo -> volumen promedio
+}
+
+func (p VolumeProfile) bucket(t time.Time) int {
+	loc := p.Location
+	if loc == nil {
+		loc = rofex.MarketLocation
+	}
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	return int(t.Sub(midnight) / p.Bucket)
+}
+
+// BuildVolumeProfile arma un VolumeProfile con los trades históricos del instrumento
+// (HistoricTrades) entre from y to, promediando el volumen por intervalo entre los días
+// con operaciones.
+//
+// Ejemplo:
+//
+//	profile, err := algo.BuildVolumeProfile(ctx, client, "DLR/MAR26", model.MarketROFEX,
+//		time.Now().AddDate(0, 0, -10), time.Now(), 15*time.Minute, loc)
+func BuildVolumeProfile(ctx context.Context, c *rofex.Client, symbol string, market model.Market, from, to time.Time, bucket time.Duration, loc *time.Location) (VolumeProfile, error) {
+	if bucket <= 0 {
+		return VolumeProfile{}, &rofex.ValidationError{Field: "bucket", Msg: "must be > 0"}
+	}
+	res, err := c.HistoricTrades(ctx, symbol, market, from, to)
+	if err != nil {
+		return VolumeProfile{}, fmt.Errorf("volume profile: %w", err)
+	}
+	if loc == nil {
+		loc = rofex.MarketLocation
+	}
+	p := VolumeProfile{Bucket: bucket, Location: loc, Volume: make(map[int]float64)}
+	days := make(map[string]struct{})
+	for _, tr := range res.Trades {
+		if tr.ServerTime == 0 {
+			continue
+		}
+		ts := time.UnixMilli(tr.ServerTime).In(loc)
+		days[ts.Format("20060102")] = struct{}{}
+		p.Volume[p.bucket(ts)] += tr.Size
+	}
+	if n := float64(len(days)); n > 1 {
+		for k, v := range p.Volume {
+			p.Volume[k] = v / n
+		}
+	}
+	return p, nil
+}
+
+// VWAP distribuye la orden entre Start y End en proporción al perfil de volumen
+// histórico (ver BuildVolumeProfile). Dentro de cada intervalo el avance es lineal.
+// Si el perfil no tiene volumen en la ventana se comporta como un TWAP lineal.
+type VWAP struct {
+	Start   time.Time
+	End     time.Time
+	Profile VolumeProfile
+}
+
+// Target implementa Schedule.
+func (v *VWAP) Target(now time.Time, total int64) int64 {
+	if now.Before(v.Start) {
+		return 0
+	}
+	if !now.Before(v.End) {
+		return total
+	}
+	frac := v.fraction(now)
+	return int64(math.Round(frac * float64(total)))
+}
+
+// fraction recorre la ventana por intervalos del perfil y acumula el volumen esperado.
+func (v *VWAP) fraction(now time.Time) float64 {
+	step := v.Profile.Bucket
+	if step <= 0 {
+		return linear(v.Start, v.End, now)
+	}
+	var sum, done float64
+	for t := v.Start; t.Before(v.End); {
+		next := v.Profile.boundary(t)
+		if next.After(v.End) {
+			next = v.End
+		}
+		seg := next.Sub(t)
+		w := v.Profile.Volume[v.Profile.bucket(t)] * float64(seg) / float64(step)
+		sum += w
+		switch {
+		case !now.Before(next):
+			done += w
+		case now.After(t):
+			done += w * float64(now.Sub(t)) / float64(seg)
+		}
+		t = next
+	}
+	if sum == 0 {
+		return linear(v.Start, v.End, now)
+	}
+	return done / sum
+}
+
+// boundary devuelve el comienzo del intervalo siguiente a t.
+func (p VolumeProfile) boundary(t time.Time) time.Time {
+	loc := p.Location
+	if loc == nil {
+		loc = rofex.MarketLocation
+	}
+	lt := t.In(loc)
+	midnight := time.Date(lt.Year(), lt.Month(), lt.Day(), 0, 0, 0, 0, loc)
+	return midnight.Add(time.Duration(p.bucket(t)+1) * p.Bucket)
+}
+
+func linear(start, end, now time.Time) float64 {
+	if !end.After(start) {
+		return 1
+	}
+	return math.Min(1, float64(now.Sub(start))/float64(end.Sub(start)))
+}
+
+// POV (percentage of volume) sigue una fracción Rate del volumen operado en el mercado
+// (TV) desde que comenzó la ejecución, sin contar lo operado por el propio algoritmo
+// (ver FillObserver). Requiere suscribir model.MDTradeVolume.
+// Si End no es cero, al llegar End el objetivo pasa a ser el total.
+type POV struct {
+	Rate float64 // Participación objetivo (ej. 0.1 = 10%)
+	End  time.Time
+
+	mu     sync.Mutex
+	base   float64
+	volume float64
+	own    float64 // operado por el algoritmo
+	seen   bool
+}
+
+// OnMarketData implementa MarketDataObserver. El primer TV recibido es la base.
+func (p *POV) OnMarketData(md model.MarketData) {
+	if md.TradeVolume == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.seen {
+		p.base, p.seen = *md.TradeVolume, true
+	}
+	p.volume = *md.TradeVolume - p.base
+}
+
+// OnFill implementa FillObserver.
+func (p *POV) OnFill(qty int64) {
+	p.mu.Lock()
+	p.own += float64(qty)
+	p.mu.Unlock()
+}
+
+// Target implementa Schedule.
+func (p *POV) Target(now time.Time, total int64) int64 {
+	if !p.End.IsZero() && !now.Before(p.End) {
+		return total
+	}
+	p.mu.Lock()
+	vol := math.Max(p.volume-p.own, 0)
+	p.mu.Unlock()
+	target := int64(math.Floor(p.Rate * vol))
+	if target > total {
+		return total
+	}
+	return target
+}
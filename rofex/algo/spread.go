@@ -0,0 +1,552 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// SpreadLeg es una pata de un spread.
+type SpreadLeg struct {
+	Symbol string
+	Market model.Market // Por defecto model.MarketROFEX
+	Side   model.Side
+	Ratio  int64 // Contratos por unidad de spread (por defecto 1)
+}
+
+func (l SpreadLeg) instrumentID() model.InstrumentID {
+	return model.InstrumentID{Symbol: l.Symbol, MarketID: string(l.Market)}
+}
+
+// SpreadOrder describe un spread a ejecutar en dos patas, una compradora y una vendedora.
+//
+// El precio del spread es el de la pata compradora menos el de la vendedora (ponderados
+// por Ratio): Price es el máximo a pagar (o el mínimo a cobrar, si es negativo).
+// Para un roll de DLR comprado (vender DLR/ENE26, comprar DLR/FEB26) el spread es
+// FEB26 - ENE26.
+type SpreadOrder struct {
+	Account string
+	Passive SpreadLeg // Pata que se trabaja en el libro
+	Hedge   SpreadLeg // Pata que se cubre agresivamente a medida que se opera la pasiva
+	Qty     int64     // Unidades de spread
+	Price   float64   // Spread objetivo
+}
+
+// legs devuelve la pata compradora y la vendedora.
+func (o SpreadOrder) legs() (buy, sell SpreadLeg) {
+	if o.Passive.Side == model.Buy {
+		return o.Passive, o.Hedge
+	}
+	return o.Hedge, o.Passive
+}
+
+// SpreadOptions configura un SpreadExecutor.
+type SpreadOptions struct {
+	MinReplaceInterval time.Duration // Tiempo mínimo entre reemplazos de la pata pasiva (por defecto 1s)
+	HedgeSlippage      int           // Ticks más allá de la punta al cubrir (por defecto 0)
+	HedgeTTL           time.Duration // Vida máxima de una cobertura antes de re-cotizarla (por defecto 2s)
+	TIF                model.TimeInForce
+	Logger             *slog.Logger
+}
+
+// SpreadQuote es el libro sintético del spread derivado de ambas patas.
+type SpreadQuote struct {
+	Bid   float64 // Spread al que se puede vender agresivamente (0 si falta alguna punta)
+	Offer float64 // Spread al que se puede comprar agresivamente (0 si falta alguna punta)
+}
+
+// SpreadProgress resume el avance de un spread.
+type SpreadProgress struct {
+	State         State
+	Qty           int64   // Unidades de spread
+	PassiveFilled int64   // Contratos operados en la pata pasiva
+	HedgeFilled   int64   // Contratos operados en la pata de cobertura
+	PassiveAvgPx  float64 // Precio promedio de la pata pasiva
+	HedgeAvgPx    float64 // Precio promedio de la pata de cobertura
+	Spread        float64 // Spread logrado (compradora - vendedora) sobre lo operado
+	Imbalance     int64   // Contratos de la pata de cobertura pendientes de cubrir
+	Err           error
+}
+
+// SpreadExecutor ejecuta un spread de calendario (o entre instrumentos) trabajando la
+// pata pasiva en el libro y cubriendo la otra cuando la primera se opera.
+//
+// El precio de la pata pasiva se deriva de la punta agresiva de la pata de cobertura
+// (Market Data BI/OF de ambas patas) de modo que, si se opera y se cubre a ese precio,
+// el spread logrado no supere SpreadOrder.Price. Cuando la punta de cobertura se mueve,
+// la pasiva se reemplaza con ReplaceOrder (como máximo una vez por MinReplaceInterval).
+// Si la pata de cobertura se queda sin punta, la pasiva se cancela para no quedar con
+// riesgo de pata.
+//
+// Cancel deja de trabajar la pata pasiva pero sigue cubriendo lo ya operado hasta que
+// Imbalance sea 0. Lo mismo ocurre si la pata pasiva falla (rechazo del mercado o error
+// de envío), que deja el spread en StateFailed. Run vuelve cuando el spread termina y
+// no queda desbalance.
+type SpreadExecutor struct {
+	client *rofex.Client
+	order  SpreadOrder
+	opts   SpreadOptions
+
+	mu          sync.Mutex
+	insts       map[model.InstrumentID]model.Instrument
+	quotes      map[model.InstrumentID]*quote
+	passive     *children
+	hedge       *children
+	state       State
+	err         error
+	lastReplace time.Time
+	now         func() time.Time
+}
+
+// NewSpreadExecutor valida el spread y crea el SpreadExecutor. La ejecución comienza con Run.
+func NewSpreadExecutor(c *rofex.Client, o SpreadOrder, opts SpreadOptions) (*SpreadExecutor, error) {
+	if o.Account == "" {
+		return nil, &rofex.ValidationError{Field: "account", Msg: "required"}
+	}
+	if o.Passive.Symbol == "" || o.Hedge.Symbol == "" {
+		return nil, &rofex.ValidationError{Field: "symbol", Msg: "both legs required"}
+	}
+	if !((o.Passive.Side == model.Buy && o.Hedge.Side == model.Sell) || (o.Passive.Side == model.Sell && o.Hedge.Side == model.Buy)) {
+		return nil, &rofex.ValidationError{Field: "side", Msg: "legs must be one BUY and one SELL"}
+	}
+	if o.Qty <= 0 {
+		return nil, &rofex.ValidationError{Field: "qty", Msg: "must be > 0"}
+	}
+	for _, l := range []*SpreadLeg{&o.Passive, &o.Hedge} {
+		if l.Market == "" {
+			l.Market = model.MarketROFEX
+		}
+		if l.Ratio <= 0 {
+			l.Ratio = 1
+		}
+	}
+	if opts.MinReplaceInterval <= 0 {
+		opts.MinReplaceInterval = time.Second
+	}
+	if opts.HedgeTTL <= 0 {
+		opts.HedgeTTL = 2 * time.Second
+	}
+	if opts.TIF == "" {
+		opts.TIF = model.Day
+	}
+	return &SpreadExecutor{
+		client:  c,
+		order:   o,
+		opts:    opts,
+		insts:   make(map[model.InstrumentID]model.Instrument),
+		quotes:  map[model.InstrumentID]*quote{o.Passive.instrumentID(): {}, o.Hedge.instrumentID(): {}},
+		passive: newChildren(),
+		hedge:   newChildren(),
+		state:   StateWorking,
+		now:     time.Now,
+	}, nil
+}
+
+// Run ejecuta el spread consumiendo Execution Reports de la cuenta y Market Data (BI/OF)
+// de ambas patas, hasta que termine sin desbalance o ctx se cancele.
+func (s *SpreadExecutor) Run(ctx context.Context, orders <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) error {
+	for _, l := range []SpreadLeg{s.order.Passive, s.order.Hedge} {
+		inst, err := s.client.Instrument(ctx, l.Symbol, l.Market)
+		if err != nil {
+			s.mu.Lock()
+			s.err, s.state = fmt.Errorf("spread: instrument detail %s: %w", l.Symbol, err), StateFailed
+			s.mu.Unlock()
+			return s.Progress().Err
+		}
+		s.mu.Lock()
+		s.insts[l.instrumentID()] = inst
+		s.mu.Unlock()
+	}
+
+	ticker := time.NewTicker(s.opts.MinReplaceInterval)
+	defer ticker.Stop()
+	s.step(ctx)
+	for {
+		if s.finished() {
+			return s.Progress().Err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			s.OnOrderReport(ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			s.OnMarketData(ev)
+		case <-ticker.C:
+		}
+		s.step(ctx)
+	}
+}
+
+// OnOrderReport actualiza las órdenes de ambas patas.
+func (s *SpreadExecutor) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, _ := s.passive.apply(ev.OrderReport); c != nil && c.status == model.StatusRejected && s.state == StateWorking {
+		s.err, s.state = fmt.Errorf("spread: passive leg %s rejected", c.clOrdID), StateFailed
+	}
+	s.hedge.apply(ev.OrderReport)
+}
+
+// OnMarketData actualiza el libro de la pata correspondiente.
+func (s *SpreadExecutor) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if q, ok := s.quotes[ev.InstrumentID]; ok {
+		q.update(ev.MarketData)
+	}
+}
+
+// Book devuelve el libro sintético del spread (compradora - vendedora).
+func (s *SpreadExecutor) Book() SpreadQuote {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buy, sell := s.order.legs()
+	qb, qs := s.quotes[buy.instrumentID()], s.quotes[sell.instrumentID()]
+	var sq SpreadQuote
+	if qb.ask > 0 && qs.bid > 0 {
+		sq.Offer = float64(buy.Ratio)*qb.ask - float64(sell.Ratio)*qs.bid
+	}
+	if qb.bid > 0 && qs.ask > 0 {
+		sq.Bid = float64(buy.Ratio)*qb.bid - float64(sell.Ratio)*qs.ask
+	}
+	return sq
+}
+
+// Progress devuelve el avance del spread.
+func (s *SpreadExecutor) Progress() SpreadProgress {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := SpreadProgress{
+		State:         s.state,
+		Qty:           s.order.Qty,
+		PassiveFilled: s.passive.filled,
+		HedgeFilled:   s.hedge.filled,
+		PassiveAvgPx:  s.passive.avgPx(),
+		HedgeAvgPx:    s.hedge.avgPx(),
+		Imbalance:     s.imbalanceLocked(),
+		Err:           s.err,
+	}
+	if p.PassiveFilled > 0 && p.HedgeFilled > 0 {
+		buy, sell := p.PassiveAvgPx*float64(s.order.Passive.Ratio), p.HedgeAvgPx*float64(s.order.Hedge.Ratio)
+		if s.order.Passive.Side == model.Sell {
+			buy, sell = sell, buy
+		}
+		p.Spread = buy - sell
+	}
+	return p
+}
+
+// Cancel deja de trabajar la pata pasiva. Lo ya operado se sigue cubriendo.
+func (s *SpreadExecutor) Cancel(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state.Done() {
+		return nil
+	}
+	s.state = StateCancelled
+	return cancelChildren(ctx, s.client, s.passive)
+}
+
+func (s *SpreadExecutor) finished() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.state.Done() || len(s.passive.working()) > 0 || len(s.hedge.working()) > 0 {
+		return false
+	}
+	return s.imbalanceLocked() == 0
+}
+
+// imbalanceLocked devuelve los contratos de cobertura que faltan para balancear lo operado en la pasiva.
+func (s *SpreadExecutor) imbalanceLocked() int64 {
+	return s.passive.filled*s.order.Hedge.Ratio/s.order.Passive.Ratio - s.hedge.filled
+}
+
+func (s *SpreadExecutor) step(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state == StateFailed {
+		// Sin pata pasiva solo queda cubrir lo ya operado
+		_ = cancelChildren(ctx, s.client, s.passive)
+		s.hedgeLocked(ctx)
+		return
+	}
+	total := s.order.Qty * s.order.Passive.Ratio
+	if s.passive.filled >= total && s.imbalanceLocked() == 0 && s.state == StateWorking {
+		s.state = StateCompleted
+	}
+	if s.state == StateWorking {
+		s.workPassiveLocked(ctx, total-s.passive.filled)
+	}
+	s.hedgeLocked(ctx)
+}
+
+// workPassiveLocked envía o reprecia la pata pasiva según la punta de cobertura.
+func (s *SpreadExecutor) workPassiveLocked(ctx context.Context, remaining int64) {
+	working := s.passive.working()
+	px, ok := s.passivePriceLocked()
+	if !ok {
+		// Sin punta para cubrir no se deja la pasiva en el libro
+		_ = cancelChildren(ctx, s.client, s.passive)
+		return
+	}
+	if len(working) == 0 {
+		if remaining <= 0 {
+			return
+		}
+		c, err := s.sendLocked(ctx, s.order.Passive, remaining, &px)
+		if err != nil {
+			s.err, s.state = fmt.Errorf("spread: passive leg: %w", err), StateFailed
+			return
+		}
+		s.passive.add(c)
+		s.lastReplace = c.sent
+		return
+	}
+	c := working[0]
+	if c.cancelling || c.price == nil || math.Abs(*c.price-px) < 1e-9 {
+		return
+	}
+	now := s.now()
+	if now.Sub(s.lastReplace) < s.opts.MinReplaceInterval {
+		return
+	}
+	s.lastReplace = now
+	res, err := s.client.ReplaceOrder(ctx, c.clOrdID, "", nil, &px)
+	if err == nil {
+		err = statusError(res.Status)
+	}
+	if err != nil {
+		s.logWarn("passive replace failed", slog.String("clOrdId", c.clOrdID), slog.Any("err", err))
+		return
+	}
+	if id := res.Order.ClientID; id != "" {
+		s.passive.rekey(c.clOrdID, id)
+	}
+	c.price = &px
+}
+
+// passivePriceLocked calcula el precio de la pasiva que, cubierta a la punta de la otra
+// pata (más el slippage), logra el spread objetivo.
+func (s *SpreadExecutor) passivePriceLocked() (float64, bool) {
+	p, h := s.order.Passive, s.order.Hedge
+	hedgePx, ok := s.hedgePriceLocked()
+	if !ok {
+		return 0, false
+	}
+	hedgeNotional := float64(h.Ratio) * hedgePx
+	var px float64
+	if p.Side == model.Buy {
+		px = (s.order.Price + hedgeNotional) / float64(p.Ratio)
+	} else {
+		px = (hedgeNotional - s.order.Price) / float64(p.Ratio)
+	}
+	px = s.insts[p.instrumentID()].RoundPrice(px, p.Side)
+	return px, px > 0
+}
+
+// hedgePriceLocked devuelve el precio agresivo de la pata de cobertura (punta contraria ± slippage).
+func (s *SpreadExecutor) hedgePriceLocked() (float64, bool) {
+	h := s.order.Hedge
+	touch := s.quotes[h.instrumentID()].touch(opposite(h.Side))
+	if touch <= 0 {
+		return 0, false
+	}
+	inst := s.insts[h.instrumentID()]
+	slip := float64(s.opts.HedgeSlippage) * inst.TickAt(touch)
+	if h.Side == model.Sell {
+		slip = -slip
+	}
+	return inst.RoundPrice(touch+slip, opposite(h.Side)), true
+}
+
+// hedgeLocked cubre el desbalance y re-cotiza las coberturas vencidas.
+func (s *SpreadExecutor) hedgeLocked(ctx context.Context) {
+	now := s.now()
+	var working int64
+	for _, c := range s.hedge.working() {
+		working += c.leaves
+		if !c.cancelling && now.Sub(c.sent) >= s.opts.HedgeTTL {
+			_ = cancelChild(ctx, s.client, c)
+		}
+	}
+	need := s.imbalanceLocked() - working
+	if need <= 0 {
+		return
+	}
+	var price *float64
+	if px, ok := s.hedgePriceLocked(); ok {
+		price = &px
+	}
+	c, err := s.sendLocked(ctx, s.order.Hedge, need, price)
+	if err != nil {
+		s.err = fmt.Errorf("spread: hedge leg: %w", err)
+		s.logWarn("hedge order failed", slog.Int64("qty", need), slog.Any("err", err))
+		return
+	}
+	s.hedge.add(c)
+}
+
+func (s *SpreadExecutor) sendLocked(ctx context.Context, leg SpreadLeg, qty int64, price *float64) (*child, error) {
+	o := rofex.NewOrder{
+		Symbol:  leg.Symbol,
+		Market:  leg.Market,
+		Side:    leg.Side,
+		Type:    model.OrderTypeLimit,
+		Qty:     qty,
+		Price:   price,
+		TIF:     s.opts.TIF,
+		Account: s.order.Account,
+	}
+	if price == nil {
+		o.Type, o.TIF = model.OrderTypeMarket, model.ImmediateOrCancel
+	}
+	res, err := s.client.SendOrder(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if err := sendRejected(res); err != nil {
+		return nil, err
+	}
+	return &child{
+		clOrdID: res.Order.ClientID,
+		qty:     qty,
+		leaves:  qty,
+		price:   price,
+		status:  model.StatusPendingNew,
+		sent:    s.now(),
+	}, nil
+}
+
+func (s *SpreadExecutor) logWarn(msg string, args ...any) {
+	if s.opts.Logger != nil {
+		s.opts.Logger.Warn(msg, args...)
+	}
+}
+
+// cancelChildren cancela las órdenes activas de cs que no estén ya cancelándose.
+func cancelChildren(ctx context.Context, c *rofex.Client, cs *children) error {
+	var errs []error
+	for _, ch := range cs.working() {
+		if ch.cancelling {
+			continue
+		}
+		if err := cancelChild(ctx, c, ch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func cancelChild(ctx context.Context, c *rofex.Client, ch *child) error {
+	res, err := c.CancelOrder(ctx, ch.clOrdID, "")
+	if err == nil {
+		err = statusError(res.Status)
+	}
+	if err != nil {
+		return fmt.Errorf("cancel %s: %w", ch.clOrdID, err)
+	}
+	ch.cancelling = true
+	return nil
+}
+
+// NextMaturity devuelve el contrato que sigue a symbol en la curva de vencimientos:
+// el de mismo subyacente (prefijo hasta "/"), mismo código CFI y el menor maturityDate
+// posterior al de symbol. Los spreads, opciones y variantes del mismo símbolo se descartan.
+func NextMaturity(ctx context.Context, c *rofex.Client, symbol string, market model.Market) (model.Instrument, error) {
+	if market == "" {
+		market = model.MarketROFEX
+	}
+	cur, err := c.Instrument(ctx, symbol, market)
+	if err != nil {
+		return model.Instrument{}, fmt.Errorf("roll: instrument detail %s: %w", symbol, err)
+	}
+	if cur.MaturityDate == nil || *cur.MaturityDate == "" {
+		return model.Instrument{}, fmt.Errorf("roll: %s has no maturity date", symbol)
+	}
+	underlying, _, ok := strings.Cut(symbol, "/")
+	if !ok {
+		return model.Instrument{}, fmt.Errorf("roll: %s is not a futures symbol", symbol)
+	}
+	all, err := c.InstrumentsDetails(ctx)
+	if err != nil {
+		return model.Instrument{}, fmt.Errorf("roll: instruments: %w", err)
+	}
+	var next *model.Instrument
+	for i := range all.Instruments {
+		in := &all.Instruments[i]
+		s := in.InstrumentID.Symbol
+		if in.InstrumentID.MarketID != string(market) || strings.Count(s, "/") != 1 || strings.ContainsAny(s, " ") ||
+			!strings.HasPrefix(s, underlying+"/") || in.CFICode != cur.CFICode ||
+			in.MaturityDate == nil || *in.MaturityDate <= *cur.MaturityDate {
+			continue
+		}
+		if next == nil || *in.MaturityDate < *next.MaturityDate {
+			next = in
+		}
+	}
+	if next == nil {
+		return model.Instrument{}, fmt.Errorf("roll: no maturity after %s (%s)", symbol, *cur.MaturityDate)
+	}
+	return *next, nil
+}
+
+// Roll arma el spread que pasa la posición pos al siguiente vencimiento (ver NextMaturity):
+// cierra el contrato actual y abre el mismo tamaño en el siguiente. El contrato siguiente,
+// normalmente menos líquido, es la pata pasiva y el actual la de cobertura.
+//
+// El SpreadOrder devuelto no tiene precio: completar Price (siguiente - actual para una
+// posición comprada, actual - siguiente para una vendida) antes de NewSpreadExecutor.
+//
+// Ejemplo:
+//
+//	so, err := algo.Roll(ctx, client, "REM6771", pos)
+//	so.Price = 12.5
+//	ex, err := algo.NewSpreadExecutor(client, so, algo.SpreadOptions{})
+func Roll(ctx context.Context, c *rofex.Client, account string, pos model.Position) (SpreadOrder, error) {
+	net := int64(math.Round(pos.BuySize - pos.SellSize))
+	if net == 0 {
+		return SpreadOrder{}, &rofex.ValidationError{Field: "position", Msg: "no net position to roll"}
+	}
+	symbol := pos.Symbol
+	if symbol == "" {
+		symbol = pos.Instrument.SymbolReference
+	}
+	next, err := NextMaturity(ctx, c, symbol, model.MarketROFEX)
+	if err != nil {
+		return SpreadOrder{}, err
+	}
+	// Comprado: vender el actual y comprar el siguiente; vendido: al revés
+	closeSide, openSide := model.Sell, model.Buy
+	if net < 0 {
+		closeSide, openSide, net = model.Buy, model.Sell, -net
+	}
+	return SpreadOrder{
+		Account: account,
+		Passive: SpreadLeg{Symbol: next.InstrumentID.Symbol, Market: model.MarketROFEX, Side: openSide, Ratio: 1},
+		Hedge:   SpreadLeg{Symbol: symbol, Market: model.MarketROFEX, Side: closeSide, Ratio: 1},
+		Qty:     net,
+	}, nil
+}
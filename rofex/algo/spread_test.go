@@ -0,0 +1,256 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestSpreadExecutor_WorkPassiveAndHedge(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	// Roll comprado: vender ENE26 (cobertura) y comprar FEB26 (pasiva) pagando a lo sumo 12
+	s, err := NewSpreadExecutor(c, SpreadOrder{
+		Account: "REM6771",
+		Passive: SpreadLeg{Symbol: "DLR/FEB26", Side: model.Buy},
+		Hedge:   SpreadLeg{Symbol: "DLR/ENE26", Side: model.Sell},
+		Qty:     10,
+		Price:   12,
+	}, SpreadOptions{})
+	if err != nil {
+		t.Fatalf("NewSpreadExecutor: %v", err)
+	}
+	start := time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)
+	now := start
+	s.now = func() time.Time { return now }
+	for _, sym := range []string{"DLR/FEB26", "DLR/ENE26"} {
+		inst, err := c.Instrument(ctx, sym, model.MarketROFEX)
+		if err != nil {
+			t.Fatalf("Instrument: %v", err)
+		}
+		s.insts[model.InstrumentID{Symbol: sym, MarketID: "ROFX"}] = inst
+	}
+	book := func(sym string, bid, ask float64) *model.MarketDataEvent {
+		return &model.MarketDataEvent{InstrumentID: model.InstrumentID{Symbol: sym, MarketID: "ROFX"}, MarketData: model.MarketData{
+			Bids: []model.BookLevel{{Price: bid, Size: 10}}, Offers: []model.BookLevel{{Price: ask, Size: 10}},
+		}}
+	}
+
+	// Sin libro de la cobertura no se envía la pasiva
+	s.step(ctx)
+	if got := v.orders(); len(got) != 0 {
+		t.Fatalf("passive sent without hedge book: %v", got)
+	}
+
+	s.OnMarketData(book("DLR/ENE26", 1000, 1001))
+	s.OnMarketData(book("DLR/FEB26", 1010, 1015))
+	if b := s.Book(); b.Bid != 9 || b.Offer != 15 {
+		t.Fatalf("synthetic book: %+v", b)
+	}
+	s.step(ctx) // pasiva a 1000 + 12
+
+	// La punta de cobertura sube: se reemplaza la pasiva una vez pasado MinReplaceInterval
+	s.OnMarketData(book("DLR/ENE26", 1000.5, 1001))
+	s.step(ctx)
+	now = now.Add(time.Second)
+	s.step(ctx)
+
+	// Fill parcial de la pasiva: se cubre el desbalance a la punta compradora de ENE26
+	s.OnOrderReport(report("rp2", "PARTIALLY_FILLED", "e1", 5, 1012.5, 5))
+	if p := s.Progress(); p.Imbalance != 5 {
+		t.Fatalf("imbalance before hedge: %+v", p)
+	}
+	s.step(ctx)
+	s.OnOrderReport(report("ch3", "FILLED", "e2", 5, 1000.5, 0))
+
+	// Cancel deja de trabajar la pasiva; no queda desbalance
+	if err := s.Cancel(ctx); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	s.OnOrderReport(report("rp2", "CANCELLED", "", 0, 0, 0))
+	if !s.finished() {
+		t.Fatalf("spread not finished: %+v", s.Progress())
+	}
+
+	want := []string{
+		"new LIMIT 10 1012",
+		"replace ch1 1012.5",
+		"new LIMIT 5 1000.5",
+		"cancel rp2",
+	}
+	if got := v.orders(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("orders:\nwant %v\ngot  %v", want, got)
+	}
+	p := s.Progress()
+	if p.State != StateCancelled || p.PassiveFilled != 5 || p.HedgeFilled != 5 || p.Spread != 12 || p.Imbalance != 0 {
+		t.Fatalf("progress: %+v", p)
+	}
+}
+
+func TestSpreadExecutor_PassiveRejectedKeepsHedging(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	s, err := NewSpreadExecutor(c, SpreadOrder{
+		Account: "REM6771",
+		Passive: SpreadLeg{Symbol: "DLR/FEB26", Side: model.Buy},
+		Hedge:   SpreadLeg{Symbol: "DLR/ENE26", Side: model.Sell},
+		Qty:     10,
+		Price:   12,
+	}, SpreadOptions{})
+	if err != nil {
+		t.Fatalf("NewSpreadExecutor: %v", err)
+	}
+	for _, sym := range []string{"DLR/FEB26", "DLR/ENE26"} {
+		inst, err := c.Instrument(ctx, sym, model.MarketROFEX)
+		if err != nil {
+			t.Fatalf("Instrument: %v", err)
+		}
+		s.insts[model.InstrumentID{Symbol: sym, MarketID: "ROFX"}] = inst
+	}
+	s.OnMarketData(&model.MarketDataEvent{InstrumentID: model.InstrumentID{Symbol: "DLR/ENE26", MarketID: "ROFX"}, MarketData: model.MarketData{
+		Bids: []model.BookLevel{{Price: 1000, Size: 10}}, Offers: []model.BookLevel{{Price: 1001, Size: 10}},
+	}})
+	s.step(ctx) // pasiva ch1
+
+	// La pasiva opera 3 y luego el mercado la rechaza: el spread falla pero se cubre lo operado
+	s.OnOrderReport(report("ch1", "PARTIALLY_FILLED", "e1", 3, 1012, 7))
+	s.OnOrderReport(report("ch1", "REJECTED", "", 0, 0, 0))
+	if p := s.Progress(); p.State != StateFailed || p.Err == nil {
+		t.Fatalf("want failed spread: %+v", p)
+	}
+	s.step(ctx)
+	if s.finished() {
+		t.Fatalf("finished with imbalance: %+v", s.Progress())
+	}
+	s.OnOrderReport(report("ch2", "FILLED", "e2", 3, 1000, 0))
+	s.step(ctx)
+	if !s.finished() {
+		t.Fatalf("spread not finished after hedge: %+v", s.Progress())
+	}
+	if got := v.orders(); fmt.Sprint(got) != fmt.Sprint([]string{"new LIMIT 10 1012", "new LIMIT 3 1000"}) {
+		t.Fatalf("orders: %v", got)
+	}
+}
+
+func TestSpreadExecutor_ErrorStatus(t *testing.T) {
+	v := newVenue(t, nil)
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	s, err := NewSpreadExecutor(c, SpreadOrder{
+		Account: "REM6771",
+		Passive: SpreadLeg{Symbol: "DLR/FEB26", Side: model.Buy},
+		Hedge:   SpreadLeg{Symbol: "DLR/ENE26", Side: model.Sell},
+		Qty:     10,
+		Price:   12,
+	}, SpreadOptions{})
+	if err != nil {
+		t.Fatalf("NewSpreadExecutor: %v", err)
+	}
+	now := time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	for _, sym := range []string{"DLR/FEB26", "DLR/ENE26"} {
+		inst, err := c.Instrument(ctx, sym, model.MarketROFEX)
+		if err != nil {
+			t.Fatalf("Instrument: %v", err)
+		}
+		s.insts[model.InstrumentID{Symbol: sym, MarketID: "ROFX"}] = inst
+	}
+	hedgeBook := func(bid float64) *model.MarketDataEvent {
+		return &model.MarketDataEvent{InstrumentID: model.InstrumentID{Symbol: "DLR/ENE26", MarketID: "ROFX"}, MarketData: model.MarketData{
+			Bids: []model.BookLevel{{Price: bid, Size: 10}}, Offers: []model.BookLevel{{Price: 1001, Size: 10}},
+		}}
+	}
+	s.OnMarketData(hedgeBook(1000))
+	s.step(ctx) // pasiva ch1 a 1012
+
+	// Reemplazo y cobertura con status ERROR: la pasiva conserva su precio y no queda
+	// una cobertura fantasma, el desbalance sigue pendiente
+	v.mu.Lock()
+	v.reject = map[string]bool{"/rest/order/replaceById": true, "/rest/order/newSingleOrder": true}
+	v.mu.Unlock()
+	s.OnMarketData(hedgeBook(1000.5))
+	now = now.Add(time.Second)
+	s.OnOrderReport(report("ch1", "PARTIALLY_FILLED", "e1", 4, 1012, 6))
+	s.step(ctx)
+
+	s.mu.Lock()
+	passive := s.passive.working()[0]
+	hedges := len(s.hedge.order)
+	s.mu.Unlock()
+	if passive.clOrdID != "ch1" || *passive.price != 1012 {
+		t.Fatalf("passive changed by rejected replace: %s @ %v", passive.clOrdID, *passive.price)
+	}
+	if p := s.Progress(); hedges != 0 || p.Imbalance != 4 || p.Err == nil {
+		t.Fatalf("hedge: children=%d progress=%+v", hedges, p)
+	}
+
+	// Cuando el mercado vuelve a aceptar, la cobertura se envía
+	v.mu.Lock()
+	v.reject = nil
+	v.mu.Unlock()
+	now = now.Add(time.Second)
+	s.step(ctx)
+	want := []string{
+		"new LIMIT 10 1012",
+		"error /rest/order/replaceById",
+		"error /rest/order/newSingleOrder",
+		"replace ch1 1012.5",
+		"new LIMIT 4 1000.5",
+	}
+	if got := v.orders(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("orders:\nwant %v\ngot  %v", want, got)
+	}
+}
+
+func TestRoll(t *testing.T) {
+	v := newVenue(t, nil)
+	fut := func(symbol, maturity string) map[string]any {
+		return map[string]any{
+			"instrumentId": map[string]any{"marketId": "ROFX", "symbol": symbol},
+			"cficode":      "FXXXSX",
+			"maturityDate": maturity,
+		}
+	}
+	v.instr = []map[string]any{
+		fut("DLR/MAR26", "20260331"),
+		fut("DLR/ENE26", "20260130"),
+		fut("DLR/ENE26/FEB26", "20260130"),
+		fut("DLR/FEB26", "20260227"),
+		fut("GGAL/FEB26", "20260220"),
+		{"instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/ENE26 1100 C"}, "cficode": "OCAFXS", "maturityDate": "20260130"},
+	}
+	c, _ := rofex.NewClient(rofex.WithBaseURL(v.ts.URL + "/"))
+	ctx := context.Background()
+
+	so, err := Roll(ctx, c, "REM6771", model.Position{Symbol: "DLR/ENE26", BuySize: 3, SellSize: 5})
+	if err != nil {
+		t.Fatalf("Roll: %v", err)
+	}
+	want := SpreadOrder{
+		Account: "REM6771",
+		Passive: SpreadLeg{Symbol: "DLR/FEB26", Market: model.MarketROFEX, Side: model.Sell, Ratio: 1},
+		Hedge:   SpreadLeg{Symbol: "DLR/ENE26", Market: model.MarketROFEX, Side: model.Buy, Ratio: 1},
+		Qty:     2,
+	}
+	if so != want {
+		t.Fatalf("roll:\nwant %+v\ngot  %+v", want, so)
+	}
+
+	if _, err := Roll(ctx, c, "REM6771", model.Position{Symbol: "DLR/MAR26", BuySize: 1}); err == nil {
+		t.Fatalf("want error when there is no later maturity")
+	}
+	var ve *rofex.ValidationError
+	if _, err := Roll(ctx, c, "REM6771", model.Position{Symbol: "DLR/ENE26", BuySize: 2, SellSize: 2}); !errors.As(err, &ve) {
+		t.Fatalf("want ValidationError for a flat position, got %v", err)
+	}
+}
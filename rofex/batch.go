@@ -0,0 +1,236 @@
+package rofex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// BatchOptions configura SendOrders.
+type BatchOptions struct {
+	Concurrency    int           // Envíos simultáneos (por defecto 4); el RateLimiter del cliente sigue aplicando
+	AllOrNone      bool          // Si una orden falla o es rechazada, no enviar las pendientes y cancelar las ya aceptadas
+	ConfirmTimeout time.Duration // AllOrNone: espera de la confirmación de cada orden por OrderStatus (por defecto 5s)
+}
+
+const (
+	// batchConfirmInterval es el espaciado entre consultas de OrderStatus de una orden
+	// pendiente de confirmación.
+	batchConfirmInterval = 250 * time.Millisecond
+	// batchUnwindTimeout acota la cancelación de las órdenes de un lote fallido, que
+	// continúa aunque se cancele el ctx de SendOrders.
+	batchUnwindTimeout = time.Minute
+)
+
+// BatchResult es el resultado de una orden dentro de SendOrders.
+type BatchResult struct {
+	Index     int      // Posición de la orden en el lote
+	Order     NewOrder // Orden enviada
+	ClOrdID   string   // clientId devuelto por el mercado (vacío si no se aceptó)
+	Err       error    // Error de validación o envío; ErrBatchAborted si no se envió por AllOrNone
+	Cancelled bool     // Cancelada por AllOrNone luego de ser aceptada
+	CancelErr error    // Error al cancelarla (la orden puede seguir activa)
+}
+
+// SendOrders envía un lote de órdenes (canasta de bonos, réplica de un índice, etc.).
+//
+// Primero valida todas las órdenes y, si alguna es inválida, no envía ninguna y devuelve
+// un *ValidationError con el índice de la orden (ej. "orders[3].price"). Luego las envía
+// con SendOrder con hasta opts.Concurrency envíos simultáneos, respetando el RateLimiter
+// y los controles pre-trade del cliente.
+//
+// Con opts.AllOrNone, ante el primer error no se envían las órdenes pendientes
+// (Err = ErrBatchAborted). Si todos los envíos fueron aceptados por la API, se confirma
+// el estado de cada orden con OrderStatus hasta que deje PENDING_NEW: una orden REJECTED
+// por el mercado (Err = *OrderRejectedError) o sin confirmar dentro de
+// opts.ConfirmTimeout (Err = ErrOrderAckTimeout) hace fallar el lote. Ante una falla las
+// órdenes ya aceptadas se cancelan con CancelOrder, respetando el límite de 1 cancelación
+// por segundo, aunque ctx se cancele. En ese caso SendOrders devuelve el error de la
+// orden que falló. Sin AllOrNone los errores se informan solo por orden y no se
+// confirma el estado de las órdenes (ver SubscribeOrderReport).
+//
+// Ejemplo:
+//
+//	results, err := client.SendOrders(ctx, basket, rofex.BatchOptions{Concurrency: 5, AllOrNone: true})
+//	for _, r := range results {
+//		fmt.Println(r.Order.Symbol, r.ClOrdID, r.Err)
+//	}
+func (c *Client) SendOrders(ctx context.Context, orders []NewOrder, opts BatchOptions) ([]BatchResult, error) {
+	results := make([]BatchResult, len(orders))
+	for i, o := range orders {
+		results[i] = BatchResult{Index: i, Order: o}
+	}
+	if len(orders) == 0 {
+		return results, nil
+	}
+
+	var first error
+	for i, o := range orders {
+		if err := c.validateOrder(ctx, o); err != nil {
+			results[i].Err = err
+			if first == nil {
+				first = indexedValidationError(i, err)
+			}
+		}
+	}
+	if first != nil {
+		return results, first
+	}
+	if c.Halted() {
+		return results, ErrTradingHalted
+	}
+
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = 4
+	}
+	if workers > len(orders) {
+		workers = len(orders)
+	}
+
+	var (
+		aborted atomic.Bool
+		failMu  sync.Mutex
+		failed  = -1
+		next    atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(orders) {
+					return
+				}
+				if opts.AllOrNone && aborted.Load() {
+					results[i].Err = ErrBatchAborted
+					continue
+				}
+				results[i].ClOrdID, results[i].Err = c.sendBatchLeg(ctx, orders[i])
+				if results[i].Err != nil && opts.AllOrNone {
+					aborted.Store(true)
+					failMu.Lock()
+					if failed < 0 || i < failed {
+						failed = i
+					}
+					failMu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !opts.AllOrNone {
+		return results, nil
+	}
+	if failed < 0 {
+		failed = c.confirmBatch(ctx, results, opts.ConfirmTimeout)
+	}
+	if failed < 0 {
+		return results, nil
+	}
+	c.unwindBatch(ctx, results)
+	return results, fmt.Errorf("send orders: order %d: %w", failed, results[failed].Err)
+}
+
+// sendBatchLeg envía una orden del lote y traduce un status no-OK en error.
+func (c *Client) sendBatchLeg(ctx context.Context, o NewOrder) (string, error) {
+	res, err := c.SendOrder(ctx, o)
+	if err != nil {
+		return "", err
+	}
+	if res.Status != "" && !strings.EqualFold(res.Status, "OK") {
+		return "", fmt.Errorf("send order %s: status %s", o.Symbol, res.Status)
+	}
+	return res.Order.ClientID, nil
+}
+
+// confirmBatch consulta el estado de cada orden del lote hasta que deje PENDING_NEW y
+// devuelve el índice de la primera rechazada o sin confirmar (-1 si todas ingresaron).
+func (c *Client) confirmBatch(ctx context.Context, results []BatchResult, timeout time.Duration) int {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	failed := -1
+	for i := range results {
+		r := &results[i]
+		if err := c.confirmBatchLeg(ctx, r.ClOrdID); err != nil {
+			r.Err = err
+			if failed < 0 {
+				failed = i
+			}
+		}
+	}
+	return failed
+}
+
+// confirmBatchLeg espera a que la orden deje PENDING_NEW. Los errores de consulta se
+// reintentan hasta que venza ctx.
+func (c *Client) confirmBatchLeg(ctx context.Context, clOrdID string) error {
+	for {
+		res, err := c.OrderStatus(ctx, clOrdID, "")
+		if err == nil && strings.EqualFold(res.Status, "OK") {
+			switch model.OrderStatus(res.Order.Status) {
+			case model.StatusRejected:
+				return &OrderRejectedError{ClOrdID: clOrdID, Text: res.Order.Text}
+			case model.StatusPendingNew, "":
+			default:
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("confirm %s: %w", clOrdID, ErrOrderAckTimeout)
+		case <-time.After(batchConfirmInterval):
+		}
+	}
+}
+
+// unwindBatch cancela las órdenes aceptadas de un lote AllOrNone fallido, incluidas las
+// que no se pudieron confirmar. La cancelación no depende de ctx: un lote abortado
+// por el llamador no debe quedar a medias en el mercado.
+func (c *Client) unwindBatch(ctx context.Context, results []BatchResult) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), batchUnwindTimeout)
+	defer cancel()
+	for i := range results {
+		r := &results[i]
+		var rej *OrderRejectedError
+		if r.ClOrdID == "" || errors.As(r.Err, &rej) {
+			continue
+		}
+		for attempt := 1; attempt <= 3; attempt++ {
+			if err := c.waitCancelSlot(ctx); err != nil {
+				r.CancelErr = err
+				break
+			}
+			r.CancelErr = c.cancelOne(ctx, r.ClOrdID, "", false)
+			if r.CancelErr == nil || !isTransient(r.CancelErr) {
+				break
+			}
+		}
+		r.Cancelled = r.CancelErr == nil
+		if r.CancelErr != nil && c.logger != nil {
+			c.logger.Error("batch unwind: cancel failed", slog.String("clOrdId", r.ClOrdID), slog.Any("err", r.CancelErr))
+		}
+	}
+}
+
+// indexedValidationError antepone el índice de la orden al campo de un *ValidationError.
+func indexedValidationError(i int, err error) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return &ValidationError{Field: fmt.Sprintf("orders[%d].%s", i, ve.Field), Msg: ve.Msg}
+	}
+	return fmt.Errorf("orders[%d]: %w", i, err)
+}
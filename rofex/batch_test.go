@@ -0,0 +1,160 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestSendOrders(t *testing.T) {
+	// Constantes
+	var (
+		mu        sync.Mutex
+		inflight  int32
+		maxFlight int32
+		seq       int
+		sent      []string
+		cancelled []string
+		queried   = map[string]int{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/rest/order/newSingleOrder":
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				m := atomic.LoadInt32(&maxFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxFlight, m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			if q.Get("symbol") == "BAD" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			seq++
+			id := q.Get("symbol") + "-" + strconv.Itoa(seq)
+			sent = append(sent, q.Get("symbol"))
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": id}})
+		case "/rest/order/id":
+			// Primera consulta PENDING_NEW; luego NEW, o REJECTED para el símbolo REJ
+			id := q.Get("clOrdId")
+			mu.Lock()
+			queried[id]++
+			n := queried[id]
+			mu.Unlock()
+			status := "NEW"
+			switch {
+			case n == 1:
+				status = "PENDING_NEW"
+			case strings.HasPrefix(id, "REJ-"):
+				status = "REJECTED"
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clOrdId": id, "status": status, "text": "Price out of range"}})
+		case "/rest/order/cancelById":
+			mu.Lock()
+			cancelled = append(cancelled, q.Get("clOrdId"))
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": q.Get("clOrdId")}})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	c.cancelInterval = time.Millisecond
+	ctx := context.Background()
+	price := 100.0
+	leg := func(symbol string) NewOrder {
+		return NewOrder{Symbol: symbol, Side: model.Buy, Type: model.OrderTypeLimit, Qty: 10, Price: &price, TIF: model.Day, Account: "REM6771"}
+	}
+
+	t.Run("validates whole batch first", func(t *testing.T) {
+		bad := leg("AL30")
+		bad.Price = nil
+		results, err := c.SendOrders(ctx, []NewOrder{leg("GD30"), bad}, BatchOptions{})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Field != "orders[1].price" {
+			t.Fatalf("want indexed validation error, got %v", err)
+		}
+		if results[1].Err == nil || len(sent) != 0 {
+			t.Fatalf("nothing must be sent: results=%+v sent=%v", results, sent)
+		}
+	})
+
+	t.Run("bounded concurrency", func(t *testing.T) {
+		basket := make([]NewOrder, 12)
+		for i := range basket {
+			basket[i] = leg("GD30")
+		}
+		results, err := c.SendOrders(ctx, basket, BatchOptions{Concurrency: 3})
+		if err != nil {
+			t.Fatalf("SendOrders: %v", err)
+		}
+		for _, r := range results {
+			if r.Err != nil || r.ClOrdID == "" {
+				t.Fatalf("result %d: %+v", r.Index, r)
+			}
+		}
+		if m := atomic.LoadInt32(&maxFlight); m > 3 || m < 2 {
+			t.Fatalf("max in-flight: %d", m)
+		}
+	})
+
+	t.Run("all or none unwinds accepted legs", func(t *testing.T) {
+		mu.Lock()
+		seq, sent = 0, nil
+		mu.Unlock()
+		results, err := c.SendOrders(ctx, []NewOrder{leg("AL30"), leg("GD35"), leg("BAD"), leg("AE38")}, BatchOptions{Concurrency: 1, AllOrNone: true})
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("want failing leg error, got %v", err)
+		}
+		if !results[0].Cancelled || !results[1].Cancelled || results[2].Err == nil || !errors.Is(results[3].Err, ErrBatchAborted) {
+			t.Fatalf("results: %+v", results)
+		}
+		if len(cancelled) != 2 || cancelled[0] != "AL30-1" || cancelled[1] != "GD35-2" {
+			t.Fatalf("cancelled: %v", cancelled)
+		}
+	})
+
+	t.Run("all or none unwinds on market rejection", func(t *testing.T) {
+		mu.Lock()
+		seq, sent, cancelled = 0, nil, nil
+		mu.Unlock()
+		results, err := c.SendOrders(ctx, []NewOrder{leg("AL30"), leg("REJ"), leg("GD35")}, BatchOptions{Concurrency: 1, AllOrNone: true})
+		var rej *OrderRejectedError
+		if !errors.As(err, &rej) || rej.ClOrdID != "REJ-2" || rej.Text != "Price out of range" {
+			t.Fatalf("want OrderRejectedError, got %v", err)
+		}
+		if !results[0].Cancelled || results[1].Cancelled || !results[2].Cancelled {
+			t.Fatalf("results: %+v", results)
+		}
+		if len(cancelled) != 2 || cancelled[0] != "AL30-1" || cancelled[1] != "GD35-3" {
+			t.Fatalf("cancelled: %v", cancelled)
+		}
+
+		// Todas confirmadas: el lote se acepta sin cancelar nada
+		mu.Lock()
+		cancelled = nil
+		mu.Unlock()
+		if _, err := c.SendOrders(ctx, []NewOrder{leg("AL30"), leg("GD35")}, BatchOptions{AllOrNone: true}); err != nil || len(cancelled) != 0 {
+			t.Fatalf("confirmed batch: err=%v cancelled=%v", err, cancelled)
+		}
+	})
+}
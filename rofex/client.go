@@ -50,6 +50,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/carvalab/rofex-go/rofex/model"
@@ -179,20 +181,34 @@ func (a *StaticTokenAuth) Refresh(ctx context.Context, c *Client) error { return
 //
 // Referencia: docs/primary-api.md - Documentación completa de Primary API v1.21
 type Client struct {
-	baseURL      string            // URL base para API REST
-	wsURL        string            // URL base para WebSocket
-	http         HTTPDoer          // Interfaz de cliente HTTP
-	limiter      RateLimiter       // Limitador de velocidad
-	auth         AuthProvider      // Proveedor de autenticación
-	logger       *slog.Logger      // Logger estructurado
-	userAgent    string            // Encabezado user agent
-	timeout      time.Duration     // Timeout de requests
-	proprietary  string            // Valor proprietary por defecto
-	wsBuf        int               // Tamaño del buffer WebSocket
-	wsDropOnFull bool              // Descartar mensajes cuando buffer lleno
-	wsClient     WSClient          // Interfaz de cliente WebSocket
-	env          model.Environment // Entorno actual
-	envExplicit  bool              // Si el entorno fue establecido explícitamente
+	baseURL        string              // URL base para API REST
+	wsURL          string              // URL base para WebSocket
+	http           HTTPDoer            // Interfaz de cliente HTTP
+	limiter        RateLimiter         // Limitador de velocidad
+	auth           AuthProvider        // Proveedor de autenticación
+	logger         *slog.Logger        // Logger estructurado
+	userAgent      string              // Encabezado user agent
+	timeout        time.Duration       // Timeout de requests
+	proprietary    string              // Valor proprietary por defecto
+	wsBuf          int                 // Tamaño del buffer WebSocket
+	wsDropOnFull   bool                // Descartar mensajes cuando buffer lleno
+	wsClient       WSClient            // Interfaz de cliente WebSocket
+	env            model.Environment   // Entorno actual
+	envExplicit    bool                // Si el entorno fue establecido explícitamente
+	instruments    *instrumentCache    // Cache de descripciones de instrumentos
+	accounts       *accountCache       // Cache del listado de Accounts
+	risk           *RiskManager        // Controles pre-trade (opcional)
+	lineage        *Lineage            // Cadena de reemplazos de ReplaceOrder
+	redirectStale  bool                // Redirigir ids reemplazados al vigente
+	wsOrderTimeout time.Duration       // Espera del primer Execution Report de SendOrderWS
+	wsOrders       *wsOrderSession     // Conexión de Execution Reports de SendOrderWS
+	holidays       map[string]struct{} // Feriados del mercado (yyyymmdd), ver WithHolidays
+	paper          *PaperBroker        // Simulador de órdenes (WithPaperTrading)
+
+	halted         atomic.Bool   // Ingreso de órdenes detenido (kill switch)
+	cancelMu       sync.Mutex    // Serializa cancelaciones masivas
+	lastCancel     time.Time     // Última cancelación de CancelAll
+	cancelInterval time.Duration // Espaciado mínimo entre cancelaciones
 }
 
 // WSClient abstrae websocket connection para facilitar testing/mocking.
@@ -238,19 +254,26 @@ func (coderWSClient) Dial(ctx context.Context, url string, opts *websocket.DialO
 // Referencia: docs/primary-api.md - "Conectándose a la API por token de autenticación"
 func NewClient(opts ...Option) (*Client, error) {
 	c := &Client{
-		baseURL:      "https://api.remarkets.primary.com.ar/",
-		wsURL:        "wss://api.remarkets.primary.com.ar/",
-		http:         &http.Client{Timeout: 15 * time.Second},
-		limiter:      noLimiter{},
-		userAgent:    "rofex-go/0.1.0 (+https://github.com/carvalab/rofex-go)",
-		timeout:      15 * time.Second,
-		proprietary:  "PBCP",
-		wsBuf:        128,
-		wsDropOnFull: false,
-		wsClient:     &coderWSClient{},
-		env:          model.EnvironmentRemarket,
-		logger:       slog.Default(),
+		baseURL:        "https://api.remarkets.primary.com.ar/",
+		wsURL:          "wss://api.remarkets.primary.com.ar/",
+		http:           &http.Client{Timeout: 15 * time.Second},
+		limiter:        noLimiter{},
+		userAgent:      "rofex-go/0.1.0 (+https://github.com/carvalab/rofex-go)",
+		timeout:        15 * time.Second,
+		proprietary:    "PBCP",
+		wsBuf:          128,
+		wsDropOnFull:   false,
+		wsClient:       &coderWSClient{},
+		env:            model.EnvironmentRemarket,
+		logger:         slog.Default(),
+		instruments:    newInstrumentCache(),
+		accounts:       &accountCache{ttl: accountCacheTTL},
+		lineage:        NewLineage(),
+		wsOrderTimeout: 10 * time.Second,
+
+		cancelInterval: time.Second,
 	}
+	c.wsOrders = newWSOrderSession(c)
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -0,0 +1,685 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// ContingentKind identifica el tipo de grupo de órdenes vinculadas.
+type ContingentKind string
+
+const (
+	// ContingentOCO (One-Cancels-Other): al operarse una pata se reduce o cancela la otra.
+	ContingentOCO ContingentKind = "OCO"
+	// ContingentBracket: entrada más take-profit y stop-loss vinculados como OCO.
+	ContingentBracket ContingentKind = "BRACKET"
+	// ContingentOTO (One-Triggers-Other): la orden padre activa a las hijas al operarse.
+	ContingentOTO ContingentKind = "OTO"
+)
+
+// LegRole identifica el rol de una pata dentro de un grupo contingente.
+type LegRole string
+
+const (
+	LegOCO        LegRole = "OCO"
+	LegEntry      LegRole = "ENTRY"
+	LegTakeProfit LegRole = "TAKE_PROFIT"
+	LegStopLoss   LegRole = "STOP_LOSS"
+	LegParent     LegRole = "PARENT"
+	LegChild      LegRole = "CHILD"
+)
+
+// ContingentLeg es una pata de un grupo contingente y su último estado conocido.
+type ContingentLeg struct {
+	Role       LegRole           `json:"role"`
+	Order      NewOrder          `json:"order"`                // Parámetros originales
+	ClOrdID    string            `json:"clOrdId,omitempty"`    // clOrdID vigente (vacío si no fue enviada)
+	Status     model.OrderStatus `json:"status,omitempty"`     // Último estado reportado
+	Qty        int64             `json:"qty"`                  // Cantidad vigente en el mercado
+	CumQty     int64             `json:"cumQty"`               // Cantidad operada
+	Cancelling bool              `json:"cancelling,omitempty"` // Cancelación pedida por el manager
+}
+
+func (l *ContingentLeg) sent() bool { return l.ClOrdID != "" }
+
+// ContingentGroup agrupa las patas vinculadas. La primera pata de un BRACKET u OTO es la que dispara al resto.
+type ContingentGroup struct {
+	ID       string          `json:"id"`
+	Kind     ContingentKind  `json:"kind"`
+	Legs     []ContingentLeg `json:"legs"`
+	Done     bool            `json:"done"`
+	Stale    []string        `json:"stale,omitempty"` // clOrdIDs reemplazados cuyos reportes se ignoran
+	Created  time.Time       `json:"created"`
+	Finished time.Time       `json:"finished,omitempty"` // Momento en que el grupo terminó
+
+	early []model.OrderDetails // Reportes llegados antes de conocer el clOrdID, a aplicar
+}
+
+func (g *ContingentGroup) trigger() *ContingentLeg {
+	if g.Kind == ContingentOCO || len(g.Legs) == 0 {
+		return nil
+	}
+	return &g.Legs[0]
+}
+
+func (g *ContingentGroup) firstLinked() int {
+	if g.trigger() != nil {
+		return 1
+	}
+	return 0
+}
+
+func (g *ContingentGroup) clone() ContingentGroup {
+	out := *g
+	out.Legs = append([]ContingentLeg(nil), g.Legs...)
+	out.Stale = append([]string(nil), g.Stale...)
+	out.early = nil
+	return out
+}
+
+// ContingentStore persiste el estado de los grupos para recuperarlo luego de un reinicio.
+type ContingentStore interface {
+	Load() ([]ContingentGroup, error)
+	Save(groups []ContingentGroup) error
+}
+
+// FileContingentStore guarda los grupos como JSON en un archivo local.
+type FileContingentStore struct {
+	Path string
+}
+
+// Load lee los grupos del archivo. Un archivo inexistente equivale a no tener grupos.
+func (s FileContingentStore) Load() ([]ContingentGroup, error) {
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var groups []ContingentGroup
+	if err := json.Unmarshal(b, &groups); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return groups, nil
+}
+
+// Save escribe los grupos de forma atómica (archivo temporal + rename).
+func (s FileContingentStore) Save(groups []ContingentGroup) error {
+	b, err := json.MarshalIndent(groups, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// ContingentManager emula órdenes OCO, bracket y OTO del lado del cliente sobre
+// SendOrder, ReplaceOrder y CancelOrder.
+//
+// Primary no ofrece órdenes contingentes nativas. El manager mantiene el estado de las
+// patas vinculadas y reacciona a los Execution Reports (OnOrderReport / Watch):
+//   - OCO: una operación parcial reduce la otra pata; una total la cancela.
+//   - BRACKET: cada operación de la entrada envía o ajusta take-profit y stop-loss
+//     a la cantidad operada; ambos se comportan como OCO entre sí.
+//   - OTO: las hijas se envían proporcionalmente a lo operado por la padre.
+//
+// El estado se persiste en un ContingentStore luego de cada cambio. Al reiniciar,
+// NewContingentManager carga los grupos y Resync consulta el estado de las patas
+// activas para procesar lo ocurrido mientras el proceso estuvo detenido. Los grupos
+// terminados se quitan del store y quedan consultables con Group durante
+// ContingentRetention.
+//
+// Cada grupo se procesa bajo su propio lock, que se mantiene durante sus envíos a la
+// API: un request lento demora solo a su grupo.
+//
+// Uso:
+//
+//	cm, _ := rofex.NewContingentManager(client, rofex.FileContingentStore{Path: "oco.json"})
+//	_ = cm.Resync(ctx)
+//	sub, _ := client.SubscribeOrderReport(ctx, account, false)
+//	go cm.Watch(ctx, sub.Events)
+//	grp, err := cm.PlaceOCO(ctx, takeProfit, stopLoss)
+type ContingentManager struct {
+	client *Client
+	store  ContingentStore
+
+	mu      sync.Mutex // Protege groups, byClOrd, early, seq y las copias publicadas de los grupos
+	groups  map[string]*contingentEntry
+	byClOrd map[string]string
+	early   map[string]earlyReports
+	pruned  time.Time // Última limpieza de early
+	seq     int
+
+	saveMu sync.Mutex // Serializa las escrituras del store
+}
+
+// ContingentRetention es el tiempo que un grupo terminado sigue disponible en Group y
+// Groups antes de descartarse.
+const ContingentRetention = time.Hour
+
+// contingentEarlyWindow es el tiempo que se guardan los reportes de clOrdIDs todavía
+// desconocidos: un reporte puede llegar antes que la respuesta de SendOrder o
+// ReplaceOrder que informa el clOrdID de la pata.
+const contingentEarlyWindow = time.Minute
+
+// earlyReports son los reportes de un clOrdID aún no asociado a un grupo.
+type earlyReports struct {
+	at   time.Time
+	reps []model.OrderDetails
+}
+
+// contingentEntry es un grupo con su lock. Los métodos *Locked del manager requieren
+// el lock del grupo que reciben.
+type contingentEntry struct {
+	mu   sync.Mutex
+	g    *ContingentGroup // Estado vigente, protegido por mu
+	snap ContingentGroup  // Última copia publicada, protegida por ContingentManager.mu
+}
+
+// NewContingentManager crea el manager y recupera los grupos persistidos en store (puede ser nil).
+func NewContingentManager(c *Client, store ContingentStore) (*ContingentManager, error) {
+	m := &ContingentManager{
+		client:  c,
+		store:   store,
+		groups:  make(map[string]*contingentEntry),
+		byClOrd: make(map[string]string),
+		early:   make(map[string]earlyReports),
+	}
+	if store == nil {
+		return m, nil
+	}
+	groups, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("contingent store load: %w", err)
+	}
+	for i := range groups {
+		g := groups[i]
+		if g.Done {
+			continue
+		}
+		m.groups[g.ID] = &contingentEntry{g: &g, snap: g.clone()}
+		m.indexGroupLocked(&g)
+	}
+	return m, nil
+}
+
+// PlaceOCO envía dos órdenes vinculadas: al operarse una, la otra se reduce o cancela.
+func (m *ContingentManager) PlaceOCO(ctx context.Context, a, b NewOrder) (ContingentGroup, error) {
+	return m.place(ctx, ContingentOCO, []ContingentLeg{{Role: LegOCO, Order: a}, {Role: LegOCO, Order: b}})
+}
+
+// PlaceBracket envía la orden de entrada. Take-profit y stop-loss se envían a medida que
+// la entrada se opera, por la cantidad operada. Si sus Qty vienen en cero se usa la de la entrada.
+func (m *ContingentManager) PlaceBracket(ctx context.Context, entry, takeProfit, stopLoss NewOrder) (ContingentGroup, error) {
+	if takeProfit.Qty == 0 {
+		takeProfit.Qty = entry.Qty
+	}
+	if stopLoss.Qty == 0 {
+		stopLoss.Qty = entry.Qty
+	}
+	return m.place(ctx, ContingentBracket, []ContingentLeg{
+		{Role: LegEntry, Order: entry},
+		{Role: LegTakeProfit, Order: takeProfit},
+		{Role: LegStopLoss, Order: stopLoss},
+	})
+}
+
+// PlaceOTO envía la orden padre; las hijas se envían proporcionalmente a lo operado por la padre.
+func (m *ContingentManager) PlaceOTO(ctx context.Context, parent NewOrder, children ...NewOrder) (ContingentGroup, error) {
+	if len(children) == 0 {
+		return ContingentGroup{}, &ValidationError{Field: "children", Msg: "required"}
+	}
+	legs := []ContingentLeg{{Role: LegParent, Order: parent}}
+	for _, ch := range children {
+		legs = append(legs, ContingentLeg{Role: LegChild, Order: ch})
+	}
+	return m.place(ctx, ContingentOTO, legs)
+}
+
+func (m *ContingentManager) place(ctx context.Context, kind ContingentKind, legs []ContingentLeg) (ContingentGroup, error) {
+	for i, l := range legs {
+		if err := m.client.validateOrder(ctx, l.Order); err != nil {
+			return ContingentGroup{}, fmt.Errorf("leg %d (%s): %w", i, l.Role, err)
+		}
+	}
+	g := &ContingentGroup{Kind: kind, Legs: legs, Created: time.Now()}
+	e := &contingentEntry{g: g}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m.mu.Lock()
+	m.seq++
+	g.ID = fmt.Sprintf("%s-%d-%d", strings.ToLower(string(kind)), time.Now().UnixNano(), m.seq)
+	e.snap = g.clone()
+	m.groups[g.ID] = e
+	m.mu.Unlock()
+
+	var err error
+	if trig := g.trigger(); trig != nil {
+		err = m.sendLocked(ctx, g, trig, trig.Order.Qty)
+	} else {
+		err = m.rebalanceLocked(ctx, g)
+	}
+	if err == nil {
+		err = m.applyEarlyLocked(ctx, g)
+	}
+	if err != nil {
+		// Evitar patas sueltas: cancelar lo enviado y descartar el grupo.
+		for i := range g.Legs {
+			if g.Legs[i].sent() {
+				_ = m.cancelLocked(ctx, &g.Legs[i])
+			}
+		}
+		g.Done = true
+	}
+	m.commitLocked(e)
+	return g.clone(), err
+}
+
+// Cancel cancela todas las patas activas del grupo.
+func (m *ContingentManager) Cancel(ctx context.Context, groupID string) error {
+	m.mu.Lock()
+	e, ok := m.groups[groupID]
+	m.mu.Unlock()
+	if !ok {
+		return &ValidationError{Field: "groupID", Msg: "unknown group"}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g := e.g
+	var errs []error
+	for i := range g.Legs {
+		l := &g.Legs[i]
+		if l.sent() && !l.Status.IsTerminal() {
+			errs = append(errs, m.cancelLocked(ctx, l))
+		}
+	}
+	g.Done = true
+	m.commitLocked(e)
+	return errors.Join(errs...)
+}
+
+// Group devuelve una copia del grupo.
+func (m *ContingentManager) Group(id string) (ContingentGroup, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.groups[id]
+	if !ok {
+		return ContingentGroup{}, false
+	}
+	return e.snap.clone(), true
+}
+
+// Groups devuelve una copia de todos los grupos ordenados por fecha de creación.
+func (m *ContingentManager) Groups() []ContingentGroup {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshotLocked(false)
+}
+
+// Watch procesa Execution Reports hasta que ctx se cancele o el canal se cierre.
+// Los errores de reacción se registran en el logger del cliente.
+func (m *ContingentManager) Watch(ctx context.Context, events <-chan *model.OrderReportEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := m.OnOrderReport(ctx, ev); err != nil && m.client.logger != nil {
+				m.client.logger.Error("contingent order reaction failed", slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// OnOrderReport aplica un Execution Report a la pata correspondiente y ajusta sus hermanas.
+//
+// Los reportes de clOrdIDs desconocidos se guardan durante contingentEarlyWindow y se
+// aplican si el clOrdID resulta ser de una pata (el reporte llegó antes que la
+// respuesta REST del envío).
+func (m *ContingentManager) OnOrderReport(ctx context.Context, ev *model.OrderReportEvent) error {
+	if ev == nil || ev.OrderReport.ClOrdID == "" {
+		return nil
+	}
+	m.mu.Lock()
+	e, ok := m.groups[m.byClOrd[ev.OrderReport.ClOrdID]]
+	if !ok {
+		m.bufferLocked(ev.OrderReport)
+	}
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	err := m.applyLocked(ctx, e.g, ev.OrderReport)
+	err = errors.Join(err, m.applyEarlyLocked(ctx, e.g))
+	m.commitLocked(e)
+	return err
+}
+
+// bufferLocked guarda el reporte de un clOrdID desconocido y descarta los guardados
+// hace más de contingentEarlyWindow. Requiere m.mu.
+func (m *ContingentManager) bufferLocked(rep model.OrderDetails) {
+	now := time.Now()
+	if now.Sub(m.pruned) > contingentEarlyWindow {
+		for id, b := range m.early {
+			if now.Sub(b.at) > contingentEarlyWindow {
+				delete(m.early, id)
+			}
+		}
+		m.pruned = now
+	}
+	b := m.early[rep.ClOrdID]
+	b.at = now
+	b.reps = append(b.reps, rep)
+	m.early[rep.ClOrdID] = b
+}
+
+// applyEarlyLocked aplica los reportes que llegaron antes de indexar sus clOrdIDs.
+func (m *ContingentManager) applyEarlyLocked(ctx context.Context, g *ContingentGroup) error {
+	var errs []error
+	for len(g.early) > 0 {
+		rep := g.early[0]
+		g.early = g.early[1:]
+		errs = append(errs, m.applyLocked(ctx, g, rep))
+	}
+	return errors.Join(errs...)
+}
+
+// Resync consulta OrderStatus de cada pata activa de los grupos abiertos y aplica el resultado.
+// Se usa al recuperar el estado persistido luego de un reinicio.
+func (m *ContingentManager) Resync(ctx context.Context) error {
+	m.mu.Lock()
+	entries := make([]*contingentEntry, 0, len(m.groups))
+	for _, e := range m.groups {
+		entries = append(entries, e)
+	}
+	m.mu.Unlock()
+	var errs []error
+	for _, e := range entries {
+		errs = append(errs, m.resyncGroup(ctx, e))
+	}
+	return errors.Join(errs...)
+}
+
+func (m *ContingentManager) resyncGroup(ctx context.Context, e *contingentEntry) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	g := e.g
+	if g.Done {
+		return nil
+	}
+	var errs []error
+	for i := range g.Legs {
+		l := &g.Legs[i]
+		if !l.sent() || l.Status.IsTerminal() {
+			continue
+		}
+		res, err := m.client.OrderStatus(ctx, l.ClOrdID, "")
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := m.applyLocked(ctx, g, res.Order.AsReport()); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if !g.Done {
+		errs = append(errs, m.rebalanceLocked(ctx, g))
+	}
+	errs = append(errs, m.applyEarlyLocked(ctx, g))
+	m.commitLocked(e)
+	return errors.Join(errs...)
+}
+
+func (m *ContingentManager) applyLocked(ctx context.Context, g *ContingentGroup, rep model.OrderDetails) error {
+	for _, stale := range g.Stale {
+		if stale == rep.ClOrdID {
+			return nil
+		}
+	}
+	var leg *ContingentLeg
+	for i := range g.Legs {
+		if g.Legs[i].ClOrdID == rep.ClOrdID {
+			leg = &g.Legs[i]
+			break
+		}
+	}
+	if leg == nil {
+		return nil
+	}
+	status := model.OrderStatus(rep.Status)
+	leg.Status = status
+	if rep.OrderQty > 0 {
+		leg.Qty = int64(rep.OrderQty)
+	}
+	if rep.CumQty != nil && int64(*rep.CumQty) > leg.CumQty {
+		leg.CumQty = int64(*rep.CumQty)
+	}
+
+	var err error
+	external := (status == model.StatusCancelled || status == model.StatusRejected) && !leg.Cancelling
+	if external && g.Kind != ContingentOTO && leg != g.trigger() && !g.Done {
+		// Una pata vinculada fue cancelada o rechazada por fuera del manager: se cancela el resto del grupo.
+		var errs []error
+		for i := range g.Legs {
+			other := &g.Legs[i]
+			if other != leg && other.sent() && !other.Status.IsTerminal() {
+				errs = append(errs, m.cancelLocked(ctx, other))
+			}
+		}
+		g.Done = true
+		err = errors.Join(errs...)
+	} else if !g.Done {
+		err = m.rebalanceLocked(ctx, g)
+	}
+	return err
+}
+
+// rebalanceLocked lleva cada pata vinculada a su cantidad objetivo: proporcional a lo
+// operado por la pata disparadora y, si el grupo es OCO, descontando lo operado por las hermanas.
+func (m *ContingentManager) rebalanceLocked(ctx context.Context, g *ContingentGroup) error {
+	trig := g.trigger()
+	var errs []error
+	for i := g.firstLinked(); i < len(g.Legs); i++ {
+		l := &g.Legs[i]
+		if l.Cancelling || (l.sent() && l.Status.IsTerminal()) {
+			continue
+		}
+		target := l.Order.Qty
+		if trig != nil {
+			if trig.Order.Qty <= 0 {
+				continue
+			}
+			target = l.Order.Qty * trig.CumQty / trig.Order.Qty
+		}
+		if g.Kind != ContingentOTO {
+			for j := g.firstLinked(); j < len(g.Legs); j++ {
+				if j != i {
+					target -= g.Legs[j].CumQty
+				}
+			}
+		}
+		switch {
+		case !l.sent():
+			if target > 0 {
+				errs = append(errs, m.sendLocked(ctx, g, l, target))
+			}
+		case target <= l.CumQty:
+			errs = append(errs, m.cancelLocked(ctx, l))
+		case target != l.Qty:
+			errs = append(errs, m.replaceLocked(ctx, g, l, target))
+		}
+	}
+	g.Done = m.finishedLocked(g)
+	return errors.Join(errs...)
+}
+
+// finishedLocked indica si no queda nada por operar ni por enviar en el grupo.
+func (m *ContingentManager) finishedLocked(g *ContingentGroup) bool {
+	trig := g.trigger()
+	for i := range g.Legs {
+		l := &g.Legs[i]
+		if l.sent() {
+			if !l.Status.IsTerminal() {
+				return false
+			}
+			continue
+		}
+		// Pata sin enviar: pendiente mientras la disparadora siga activa.
+		if trig != nil && trig.sent() && !trig.Status.IsTerminal() {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *ContingentManager) sendLocked(ctx context.Context, g *ContingentGroup, l *ContingentLeg, qty int64) error {
+	o := l.Order
+	o.Qty = qty
+	res, err := m.client.SendOrder(ctx, o)
+	if err != nil {
+		return fmt.Errorf("contingent %s: send %s: %w", g.ID, l.Role, err)
+	}
+	if (res.Status != "" && !strings.EqualFold(res.Status, "OK")) || res.Order.ClientID == "" {
+		return fmt.Errorf("contingent %s: send %s: status %s", g.ID, l.Role, res.Status)
+	}
+	l.ClOrdID = res.Order.ClientID
+	l.Qty = qty
+	l.Status = model.StatusPendingNew
+	m.index(g, l.ClOrdID)
+	return nil
+}
+
+func (m *ContingentManager) replaceLocked(ctx context.Context, g *ContingentGroup, l *ContingentLeg, qty int64) error {
+	res, err := m.client.ReplaceOrder(ctx, l.ClOrdID, "", &qty, nil)
+	if err != nil {
+		return fmt.Errorf("contingent %s: replace %s: %w", g.ID, l.Role, err)
+	}
+	if res.Status != "" && !strings.EqualFold(res.Status, "OK") {
+		return fmt.Errorf("contingent %s: replace %s: status %s", g.ID, l.Role, res.Status)
+	}
+	if res.Order.ClientID != "" && res.Order.ClientID != l.ClOrdID {
+		g.Stale = append(g.Stale, l.ClOrdID)
+		l.ClOrdID = res.Order.ClientID
+		m.index(g, l.ClOrdID)
+	}
+	l.Qty = qty
+	return nil
+}
+
+func (m *ContingentManager) cancelLocked(ctx context.Context, l *ContingentLeg) error {
+	res, err := m.client.CancelOrder(ctx, l.ClOrdID, "")
+	if err != nil {
+		return fmt.Errorf("contingent: cancel %s: %w", l.ClOrdID, err)
+	}
+	if res.Status != "" && !strings.EqualFold(res.Status, "OK") {
+		return fmt.Errorf("contingent: cancel %s: status %s", l.ClOrdID, res.Status)
+	}
+	l.Cancelling = true
+	return nil
+}
+
+// index asocia un clOrdID al grupo y le pasa los reportes que llegaron antes; se
+// aplican con applyEarlyLocked.
+func (m *ContingentManager) index(g *ContingentGroup, clOrdID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byClOrd[clOrdID] = g.ID
+	if b, ok := m.early[clOrdID]; ok {
+		g.early = append(g.early, b.reps...)
+		delete(m.early, clOrdID)
+	}
+}
+
+func (m *ContingentManager) indexGroupLocked(g *ContingentGroup) {
+	for _, l := range g.Legs {
+		if l.ClOrdID != "" {
+			m.byClOrd[l.ClOrdID] = g.ID
+		}
+	}
+	for _, id := range g.Stale {
+		m.byClOrd[id] = g.ID
+	}
+}
+
+// commitLocked publica la copia del grupo, descarta los grupos terminados hace más de
+// ContingentRetention y persiste los grupos abiertos.
+func (m *ContingentManager) commitLocked(e *contingentEntry) {
+	now := time.Now()
+	if e.g.Done && e.g.Finished.IsZero() {
+		e.g.Finished = now
+	}
+	m.mu.Lock()
+	e.snap = e.g.clone()
+	for id, other := range m.groups {
+		if other.snap.Done && now.Sub(other.snap.Finished) > ContingentRetention {
+			for _, l := range other.snap.Legs {
+				delete(m.byClOrd, l.ClOrdID)
+			}
+			for _, stale := range other.snap.Stale {
+				delete(m.byClOrd, stale)
+			}
+			delete(m.groups, id)
+		}
+	}
+	m.mu.Unlock()
+	m.persist()
+}
+
+// snapshotLocked copia los grupos publicados (solo los abiertos si open) ordenados por
+// fecha de creación. Requiere m.mu.
+func (m *ContingentManager) snapshotLocked(open bool) []ContingentGroup {
+	out := make([]ContingentGroup, 0, len(m.groups))
+	for _, e := range m.groups {
+		if open && e.snap.Done {
+			continue
+		}
+		out = append(out, e.snap.clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.Before(out[j].Created) })
+	return out
+}
+
+// persist guarda los grupos abiertos: los terminados ya no se necesitan al reiniciar.
+func (m *ContingentManager) persist() {
+	if m.store == nil {
+		return
+	}
+	m.saveMu.Lock()
+	defer m.saveMu.Unlock()
+	m.mu.Lock()
+	groups := m.snapshotLocked(true)
+	m.mu.Unlock()
+	if err := m.store.Save(groups); err != nil && m.client.logger != nil {
+		m.client.logger.Error("contingent store save failed", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,206 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// orderServer simula los endpoints de ingreso de órdenes y registra las llamadas.
+type orderServer struct {
+	mu    sync.Mutex
+	seq   int
+	calls []string
+
+	reject map[string]bool // Paths que responden status ERROR
+	onNew  func(id string) // Se llama antes de responder un newSingleOrder
+}
+
+func (s *orderServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		q := r.URL.Query()
+		var id string
+		switch r.URL.Path {
+		case "/rest/order/newSingleOrder":
+			s.seq++
+			id = fmt.Sprintf("ord%d", s.seq)
+			s.calls = append(s.calls, fmt.Sprintf("new %s %s %s", q.Get("side"), q.Get("orderQty"), id))
+		case "/rest/order/replaceById":
+			s.seq++
+			id = fmt.Sprintf("ord%d", s.seq)
+			s.calls = append(s.calls, fmt.Sprintf("replace %s %s %s", q.Get("clOrdId"), q.Get("orderQty"), id))
+		case "/rest/order/cancelById":
+			id = q.Get("clOrdId")
+			s.calls = append(s.calls, "cancel "+id)
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if s.reject[r.URL.Path] {
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "ERROR", "description": "rejected"})
+			return
+		}
+		if s.onNew != nil && r.URL.Path == "/rest/order/newSingleOrder" {
+			s.onNew(id)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"status": "OK",
+			"order":  map[string]any{"clientId": id, "proprietary": "PBCP"},
+		})
+	}
+}
+
+func (s *orderServer) log() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func fillReport(clOrdID string, side model.Side, qty, cum int, status model.OrderStatus) *model.OrderReportEvent {
+	return &model.OrderReportEvent{OrderReport: model.OrderDetails{
+		ClOrdID:      clOrdID,
+		InstrumentID: model.InstrumentID{Symbol: "DLR/DIC23", MarketID: "ROFX"},
+		Side:         string(side),
+		OrderQty:     qty,
+		CumQty:       &cum,
+		Status:       string(status),
+	}}
+}
+
+func TestContingentManager_BracketAndRecovery(t *testing.T) {
+	srv := &orderServer{}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	store := FileContingentStore{Path: filepath.Join(t.TempDir(), "groups.json")}
+	cm, err := NewContingentManager(c, store)
+	if err != nil {
+		t.Fatalf("NewContingentManager: %v", err)
+	}
+
+	entryPx, tpPx, slPx := 200.0, 205.0, 195.0
+	base := NewOrder{Symbol: "DLR/DIC23", Type: model.OrderTypeLimit, TIF: model.Day, Account: "REM6771"}
+	entry, tp, sl := base, base, base
+	entry.Side, entry.Qty, entry.Price = model.Buy, 10, &entryPx
+	tp.Side, tp.Price = model.Sell, &tpPx
+	sl.Side, sl.Price = model.Sell, &slPx
+
+	grp, err := cm.PlaceBracket(ctx, entry, tp, sl)
+	if err != nil {
+		t.Fatalf("PlaceBracket: %v", err)
+	}
+
+	// Entrada parcial: se envían TP y SL por 4
+	_ = cm.OnOrderReport(ctx, fillReport("ord1", model.Buy, 10, 4, model.StatusPartiallyFilled))
+	// Entrada completa: TP y SL se ajustan a 10 (reemplazo => nuevos clOrdID)
+	_ = cm.OnOrderReport(ctx, fillReport("ord1", model.Buy, 10, 10, model.StatusFilled))
+
+	// Reinicio: un nuevo manager recupera el grupo desde el archivo
+	cm2, err := NewContingentManager(c, store)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	// El reporte del clOrdID reemplazado se ignora
+	_ = cm2.OnOrderReport(ctx, fillReport("ord2", model.Sell, 4, 0, model.StatusCancelled))
+	// TP operado por completo: se cancela el SL vigente
+	_ = cm2.OnOrderReport(ctx, fillReport("ord4", model.Sell, 10, 10, model.StatusFilled))
+
+	want := []string{
+		"new BUY 10 ord1",
+		"new SELL 4 ord2",
+		"new SELL 4 ord3",
+		"replace ord2 10 ord4",
+		"replace ord3 10 ord5",
+		"cancel ord5",
+	}
+	got := srv.log()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("calls:\nwant %v\ngot  %v", want, got)
+	}
+
+	g, ok := cm2.Group(grp.ID)
+	if !ok {
+		t.Fatalf("group not recovered")
+	}
+	_ = cm2.OnOrderReport(ctx, fillReport("ord5", model.Sell, 10, 0, model.StatusCancelled))
+	if g, _ = cm2.Group(grp.ID); !g.Done || g.Finished.IsZero() {
+		t.Fatalf("group should be done: %+v", g)
+	}
+
+	// Los grupos terminados no se persisten
+	cm3, err := NewContingentManager(c, store)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, ok := cm3.Group(grp.ID); ok {
+		t.Fatalf("finished group still in store")
+	}
+}
+
+func TestContingentManager_EarlyReportsAndErrorStatus(t *testing.T) {
+	srv := &orderServer{reject: map[string]bool{}}
+	ts := httptest.NewServer(srv.handler(t))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cm, err := NewContingentManager(c, nil)
+	if err != nil {
+		t.Fatalf("NewContingentManager: %v", err)
+	}
+
+	entryPx, tpPx, slPx := 200.0, 205.0, 195.0
+	base := NewOrder{Symbol: "DLR/DIC23", Type: model.OrderTypeLimit, TIF: model.Day, Account: "REM6771"}
+	entry, tp, sl := base, base, base
+	entry.Side, entry.Qty, entry.Price = model.Buy, 10, &entryPx
+	tp.Side, tp.Price = model.Sell, &tpPx
+	sl.Side, sl.Price = model.Sell, &slPx
+
+	// La entrada se opera antes de que llegue la respuesta de SendOrder
+	srv.onNew = func(id string) {
+		if id == "ord1" {
+			_ = cm.OnOrderReport(ctx, fillReport(id, model.Buy, 10, 10, model.StatusFilled))
+		}
+	}
+	grp, err := cm.PlaceBracket(ctx, entry, tp, sl)
+	if err != nil {
+		t.Fatalf("PlaceBracket: %v", err)
+	}
+	want := []string{"new BUY 10 ord1", "new SELL 10 ord2", "new SELL 10 ord3"}
+	if got := srv.log(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("calls:\nwant %v\ngot  %v", want, got)
+	}
+
+	// Un reemplazo o una cancelación con status ERROR no cambian la pata
+	srv.mu.Lock()
+	srv.reject["/rest/order/replaceById"] = true
+	srv.reject["/rest/order/cancelById"] = true
+	srv.mu.Unlock()
+	if err := cm.OnOrderReport(ctx, fillReport("ord2", model.Sell, 10, 4, model.StatusPartiallyFilled)); err == nil {
+		t.Fatalf("want replace error")
+	}
+	g, _ := cm.Group(grp.ID)
+	if sl := g.Legs[2]; sl.ClOrdID != "ord3" || sl.Qty != 10 {
+		t.Fatalf("stop-loss changed by rejected replace: %+v", sl)
+	}
+	if err := cm.Cancel(ctx, grp.ID); err == nil {
+		t.Fatalf("want cancel error")
+	}
+	if g, _ = cm.Group(grp.ID); g.Legs[1].Cancelling || g.Legs[2].Cancelling {
+		t.Fatalf("legs marked cancelling after rejected cancel: %+v", g.Legs)
+	}
+}
@@ -0,0 +1,110 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// MarketLocation es la zona horaria del mercado (Argentina, UTC-3 sin horario de verano).
+// Las fechas de las órdenes (expireDate) y de los instrumentos (maturityDate) se
+// interpretan en esta zona.
+var MarketLocation = time.FixedZone("ART", -3*60*60)
+
+const (
+	// dateLayout es el formato de fecha de la API tanto en REST (query) como en
+	// WebSocket (mensaje "no"): expireDate=20230720, maturityDate=20231123.
+	dateLayout = "20060102"
+	// transactTimeLayout es el formato de transactTime de los Execution Reports.
+	transactTimeLayout = "20060102-15:04:05"
+)
+
+// formatDate serializa el día de t en el mercado con el formato de la API.
+func formatDate(t time.Time) string {
+	return t.In(MarketLocation).Format(dateLayout)
+}
+
+// parseDate interpreta una fecha de la API (ej. maturityDate) en la zona del mercado.
+func parseDate(s string) (time.Time, error) {
+	return time.ParseInLocation(dateLayout, s, MarketLocation)
+}
+
+// marketDay devuelve el comienzo del día de t en la zona del mercado.
+func marketDay(t time.Time) time.Time {
+	y, m, d := t.In(MarketLocation).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, MarketLocation)
+}
+
+// IsTradingDay indica si t cae en un día hábil del mercado: de lunes a viernes y
+// fuera de los feriados configurados con WithHolidays.
+func (c *Client) IsTradingDay(t time.Time) bool {
+	switch t.In(MarketLocation).Weekday() {
+	case time.Saturday, time.Sunday:
+		return false
+	}
+	_, holiday := c.holidays[formatDate(t)]
+	return !holiday
+}
+
+// validateOrder aplica a una orden las validaciones locales (validate) y las que
+// dependen del cliente: cuenta conocida y fecha de vencimiento GTD.
+func (c *Client) validateOrder(ctx context.Context, o NewOrder) error {
+	if err := o.validate(); err != nil {
+		return err
+	}
+	if err := c.validateAccount(o.Account); err != nil {
+		return err
+	}
+	if err := c.validateStopType(ctx, o); err != nil {
+		return err
+	}
+	return c.validateExpireDate(ctx, o)
+}
+
+// validateStopType verifica que el instrumento acepte de forma nativa el tipo stop de
+// la orden (orderTypes); si no, la orden debe emularse con StopManager.
+func (c *Client) validateStopType(ctx context.Context, o NewOrder) error {
+	if !o.Type.IsStop() {
+		return nil
+	}
+	inst, err := c.Instrument(ctx, o.Symbol, o.Market)
+	if err != nil {
+		return fmt.Errorf("validate type: instrument detail: %w", err)
+	}
+	if !inst.SupportsOrderType(o.Type) {
+		return &ValidationError{Field: "type", Msg: fmt.Sprintf("%s not supported by %s, use StopManager", o.Type, o.Symbol)}
+	}
+	return nil
+}
+
+// validateExpireDate verifica que la fecha de una orden GTD sea futura, hábil y no
+// posterior al vencimiento del instrumento (si el instrumento informa maturityDate).
+func (c *Client) validateExpireDate(ctx context.Context, o NewOrder) error {
+	if o.TIF != model.GoodTillDate {
+		return nil
+	}
+	day := marketDay(o.ExpireDate)
+	if !day.After(marketDay(time.Now())) {
+		return &ValidationError{Field: "expireDate", Msg: "must be in the future"}
+	}
+	if !c.IsTradingDay(day) {
+		return &ValidationError{Field: "expireDate", Msg: fmt.Sprintf("%s is not a trading day", formatDate(day))}
+	}
+	inst, err := c.Instrument(ctx, o.Symbol, o.Market)
+	if err != nil {
+		return fmt.Errorf("validate expireDate: instrument detail: %w", err)
+	}
+	if inst.MaturityDate == nil || *inst.MaturityDate == "" {
+		return nil
+	}
+	maturity, err := parseDate(*inst.MaturityDate)
+	if err != nil {
+		return fmt.Errorf("validate expireDate: maturityDate %q: %w", *inst.MaturityDate, err)
+	}
+	if day.After(maturity) {
+		return &ValidationError{Field: "expireDate", Msg: fmt.Sprintf("after instrument maturity %s", *inst.MaturityDate)}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// nextTradingDay devuelve el primer día de semana posterior a t, en la zona del mercado.
+func nextTradingDay(t time.Time) time.Time {
+	d := marketDay(t).AddDate(0, 0, 1)
+	for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+func TestValidateExpireDate(t *testing.T) {
+	// Constantes
+	day := nextTradingDay(time.Now())
+	holiday := nextTradingDay(day)
+	maturity := nextTradingDay(holiday)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/instruments/detail" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "instrument": map[string]any{"maturityDate": formatDate(maturity)}})
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithHolidays(holiday))
+	price := 100.0
+	gtd := func(expire time.Time) NewOrder {
+		return NewOrder{Symbol: "DLR/DIC23", Side: model.Buy, Type: model.OrderTypeLimit, Qty: 1, Price: &price, TIF: model.GoodTillDate, ExpireDate: expire, Account: "REM6771"}
+	}
+	ctx := context.Background()
+
+	// Cualquier hora del día hábil es válida: se toma la fecha en la zona del mercado
+	if err := c.validateOrder(ctx, gtd(day.Add(23*time.Hour))); err != nil {
+		t.Fatalf("valid expireDate: %v", err)
+	}
+	if err := c.validateOrder(ctx, gtd(maturity)); err != nil {
+		t.Fatalf("expireDate on maturity: %v", err)
+	}
+
+	saturday := day
+	for saturday.Weekday() != time.Saturday {
+		saturday = saturday.AddDate(0, 0, 1)
+	}
+	nonGTD := gtd(day)
+	nonGTD.TIF = model.Day
+	for name, o := range map[string]NewOrder{
+		"missing":        gtd(time.Time{}),
+		"not GTD":        nonGTD,
+		"today":          gtd(time.Now()),
+		"weekend":        gtd(saturday),
+		"holiday":        gtd(holiday),
+		"after maturity": gtd(nextTradingDay(maturity)),
+	} {
+		var ve *ValidationError
+		if err := c.validateOrder(ctx, o); !errors.As(err, &ve) || ve.Field != "expireDate" {
+			t.Errorf("%s: want expireDate ValidationError, got %v", name, err)
+		}
+	}
+}
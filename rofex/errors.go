@@ -3,6 +3,9 @@ package rofex
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/carvalab/rofex-go/rofex/model"
 )
 
 // HTTPError representa una respuesta HTTP no-2xx.
@@ -36,9 +39,69 @@ type TemporaryError struct{ Err error }
 func (e *TemporaryError) Error() string { return e.Err.Error() }
 func (e *TemporaryError) Unwrap() error { return e.Err }
 
+// RiskError representa una orden rechazada localmente por un control pre-trade.
+type RiskError struct {
+	Limit   RiskLimit // Límite violado
+	Account string    // Cuenta de la orden
+	Symbol  string    // Instrumento de la orden
+	Value   float64   // Valor que tendría la métrica si la orden se enviara
+	Max     float64   // Máximo configurado
+	Msg     string    // Motivo cuando la métrica no se puede calcular
+}
+
+func (e *RiskError) Error() string {
+	if e.Msg != "" {
+		return fmt.Sprintf("risk check failed: %s account=%s symbol=%s: %s", e.Limit, e.Account, e.Symbol, e.Msg)
+	}
+	return fmt.Sprintf("risk check failed: %s account=%s symbol=%s value=%v max=%v", e.Limit, e.Account, e.Symbol, e.Value, e.Max)
+}
+
+// OrderRejectedError representa una orden rechazada por el mercado.
+type OrderRejectedError struct {
+	ClOrdID   string // clOrdId asignado por el mercado
+	WSClOrdID string // wsClOrdId de la orden (órdenes enviadas por WebSocket)
+	Text      string // Motivo informado en el Execution Report
+}
+
+func (e *OrderRejectedError) Error() string {
+	return fmt.Sprintf("order rejected: clOrdId=%s wsClOrdId=%s: %s", e.ClOrdID, e.WSClOrdID, e.Text)
+}
+
+// UnknownInstrumentsError lista los instrumentos que no existen en los datos de referencia.
+type UnknownInstrumentsError struct {
+	Instruments []model.InstrumentID
+}
+
+func (e *UnknownInstrumentsError) Error() string {
+	names := make([]string, 0, len(e.Instruments))
+	for _, id := range e.Instruments {
+		names = append(names, id.Symbol+":"+id.MarketID)
+	}
+	return fmt.Sprintf("unknown instruments: %s", strings.Join(names, ", "))
+}
+
+func (e *UnknownInstrumentsError) Unwrap() error { return ErrUnknownInstrument }
+
 var (
 	// ErrUnauthorized indicates missing/expired credentials.
 	ErrUnauthorized = &AuthError{Msg: "unauthorized"}
 	// ErrClosed indicates a closed resource.
 	ErrClosed = errors.New("closed")
+	// ErrTradingHalted indicates order entry is disabled by Halt/CancelAll until Resume.
+	ErrTradingHalted = errors.New("trading halted")
+	// ErrOrderAckTimeout indicates an order was not acknowledged (WebSocket execution report or batch confirmation) in time.
+	ErrOrderAckTimeout = errors.New("order acknowledgement timeout")
+	// ErrBatchAborted marks batch orders not sent because another order failed in AllOrNone mode.
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrUnknownInstrument indicates the instrument does not exist in the reference data.
+	ErrUnknownInstrument = errors.New("unknown instrument")
 )
+
+// responseStatusError devuelve un error si la API respondió con HTTP 200 pero un status
+// distinto de OK: los datos de la respuesta vienen vacíos y no deben usarse.
+func responseStatusError(status string) error {
+	if status == "" || strings.EqualFold(status, "OK") {
+		return nil
+	}
+	return fmt.Errorf("status %s", status)
+}
@@ -0,0 +1,174 @@
+package rofex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// CancelAllFilter selecciona qué órdenes activas cancela CancelAll.
+type CancelAllFilter struct {
+	Symbol      string     // Solo órdenes de este símbolo (vacío = todos)
+	Side        model.Side // Solo órdenes de este lado (vacío = ambos)
+	UseWS       bool       // Cancelar con CancelOrderWS en lugar de REST
+	Proprietary string     // Proprietary para la cancelación (vacío = el de la orden o el del cliente)
+	MaxAttempts int        // Intentos por orden ante errores transitorios (por defecto 3)
+}
+
+func (f CancelAllFilter) match(o model.Order) bool {
+	if f.Symbol != "" && o.InstrumentID.Symbol != f.Symbol {
+		return false
+	}
+	if f.Side != "" && !strings.EqualFold(string(o.Side), string(f.Side)) {
+		return false
+	}
+	return true
+}
+
+// CancelResult es el resultado de cancelar una orden dentro de CancelAll.
+type CancelResult struct {
+	ClOrdID  string
+	Symbol   string
+	Side     model.Side
+	Attempts int
+	Err      error // nil si la cancelación fue aceptada
+}
+
+// Halt detiene el ingreso de órdenes: SendOrder, SendOrderWS y ReplaceOrder
+// devuelven ErrTradingHalted hasta que se llame a Resume. Las cancelaciones siguen permitidas.
+func (c *Client) Halt() {
+	if !c.halted.Swap(true) && c.logger != nil {
+		c.logger.Warn("trading halted")
+	}
+}
+
+// Resume rehabilita el ingreso de órdenes luego de Halt o CancelAll.
+func (c *Client) Resume() {
+	if c.halted.Swap(false) && c.logger != nil {
+		c.logger.Info("trading resumed")
+	}
+}
+
+// Halted indica si el ingreso de órdenes está detenido.
+func (c *Client) Halted() bool { return c.halted.Load() }
+
+// CancelAll es el kill switch del cliente: detiene el ingreso de órdenes y cancela
+// todas las órdenes activas de la cuenta que coincidan con el filtro.
+//
+// Las órdenes se obtienen con ActiveOrders y se cancelan de a una con CancelOrder
+// (o CancelOrderWS si filter.UseWS), respetando el límite de 1 cancelación por segundo.
+// Los errores transitorios (red, HTTP 5xx/429, TemporaryError) se reintentan hasta
+// filter.MaxAttempts veces. El ingreso de órdenes queda detenido hasta llamar a Resume.
+//
+// ⚠️ Rate Limit: Máximo 1 request por segundo para cancelaciones según documentación oficial.
+//
+// Ejemplo:
+//
+//	report, err := client.CancelAll(ctx, "REM6771", rofex.CancelAllFilter{Symbol: "DLR/DIC23"})
+//	for _, r := range report {
+//		if r.Err != nil {
+//			log.Printf("no se pudo cancelar %s: %v", r.ClOrdID, r.Err)
+//		}
+//	}
+//
+// Referencia: docs/primary-api.md - "Consultar órdenes activas", "Cancelar una orden"
+func (c *Client) CancelAll(ctx context.Context, account string, filter CancelAllFilter) ([]CancelResult, error) {
+	if account == "" {
+		return nil, &ValidationError{Field: "account", Msg: "required"}
+	}
+	c.Halt()
+
+	active, err := c.ActiveOrders(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("cancel all: active orders: %w", err)
+	}
+	attempts := filter.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+
+	results := make([]CancelResult, 0, len(active.Orders))
+	for _, o := range active.Orders {
+		if !filter.match(o) {
+			continue
+		}
+		res := CancelResult{ClOrdID: o.ClOrdID, Symbol: o.InstrumentID.Symbol, Side: o.Side}
+		proprietary := filter.Proprietary
+		if proprietary == "" {
+			proprietary = o.Proprietary
+		}
+		for res.Attempts < attempts {
+			if err := c.waitCancelSlot(ctx); err != nil {
+				res.Err = err
+				results = append(results, res)
+				return results, err
+			}
+			res.Attempts++
+			res.Err = c.cancelOne(ctx, o.ClOrdID, proprietary, filter.UseWS)
+			if res.Err == nil || !isTransient(res.Err) {
+				break
+			}
+			if c.logger != nil {
+				c.logger.Warn("cancel failed, retrying", slog.String("clOrdId", o.ClOrdID), slog.Int("attempt", res.Attempts), slog.Any("err", res.Err))
+			}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// cancelOne cancela una orden por REST o WebSocket y traduce un status no-OK en error.
+func (c *Client) cancelOne(ctx context.Context, clOrdID, proprietary string, useWS bool) error {
+	if useWS {
+		return c.CancelOrderWS(ctx, clOrdID, proprietary)
+	}
+	res, err := c.CancelOrder(ctx, clOrdID, proprietary)
+	if err != nil {
+		return err
+	}
+	if res.Status != "" && !strings.EqualFold(res.Status, "OK") {
+		return fmt.Errorf("cancel %s: status %s", clOrdID, res.Status)
+	}
+	return nil
+}
+
+// waitCancelSlot espacia las cancelaciones según cancelInterval (1 por segundo por defecto).
+func (c *Client) waitCancelSlot(ctx context.Context) error {
+	c.cancelMu.Lock()
+	defer c.cancelMu.Unlock()
+	if wait := c.cancelInterval - time.Since(c.lastCancel); wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	c.lastCancel = time.Now()
+	return nil
+}
+
+// isTransient indica si un error justifica reintentar la operación.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var tmp *TemporaryError
+	if errors.As(err, &tmp) {
+		return true
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
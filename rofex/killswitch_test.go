@@ -0,0 +1,77 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestCancelAll_FilterRetryAndHalt(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	var cancelCalls int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/order/actives":
+			mustEq(t, r.URL.Query(), "accountId", account)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "OK",
+				"orders": []any{
+					map[string]any{"clOrdId": "a1", "proprietary": "PBCP", "side": "BUY", "instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/DIC23"}},
+					map[string]any{"clOrdId": "a2", "proprietary": "PBCP", "side": "SELL", "instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/DIC23"}},
+					map[string]any{"clOrdId": "a3", "proprietary": "PBCP", "side": "BUY", "instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/ENE24"}},
+				},
+			})
+		case "/rest/order/cancelById":
+			// El primer intento falla con 503 para forzar el reintento
+			if atomic.AddInt32(&cancelCalls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "OK",
+				"order":  map[string]any{"clientId": r.URL.Query().Get("clOrdId"), "proprietary": "PBCP"},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	c.cancelInterval = 10 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	report, err := c.CancelAll(ctx, account, CancelAllFilter{Symbol: "DLR/DIC23"})
+	if err != nil {
+		t.Fatalf("CancelAll: %v", err)
+	}
+	if len(report) != 2 {
+		t.Fatalf("report: want 2 orders got %d", len(report))
+	}
+	if report[0].Err != nil || report[0].Attempts != 2 {
+		t.Fatalf("retry: got attempts=%d err=%v", report[0].Attempts, report[0].Err)
+	}
+	if report[1].Err != nil || report[1].ClOrdID != "a2" {
+		t.Fatalf("second order: %+v", report[1])
+	}
+
+	price := 200.0
+	order := NewOrder{Symbol: "DLR/DIC23", Side: model.Buy, Type: model.OrderTypeLimit, Qty: 1, Price: &price, TIF: model.Day, Account: account}
+	if _, err := c.SendOrder(ctx, order); !errors.Is(err, ErrTradingHalted) {
+		t.Fatalf("want ErrTradingHalted, got %v", err)
+	}
+	c.Resume()
+	if c.Halted() {
+		t.Fatalf("still halted after Resume")
+	}
+}
@@ -0,0 +1,399 @@
+package rofex
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Fill es una ejecución individual de una orden.
+type Fill struct {
+	ExecID     string       `json:"execId"`
+	OrderID    string       `json:"orderId,omitempty"`
+	ClOrdID    string       `json:"clOrdId"`
+	Account    string       `json:"account"`
+	Symbol     string       `json:"symbol"`
+	Market     model.Market `json:"marketId"`
+	Side       model.Side   `json:"side"`
+	Qty        int64        `json:"qty"`
+	Price      float64      `json:"price"`
+	Time       time.Time    `json:"time"`
+	Aggregated bool         `json:"aggregated,omitempty"` // Reconstruida desde FilledOrders: puede agrupar varias ejecuciones
+}
+
+// Notional devuelve Qty * Price (sin multiplicador del contrato).
+func (f Fill) Notional() float64 { return float64(f.Qty) * f.Price }
+
+// FillFilter selecciona fills del Ledger. Los campos vacíos no filtran.
+type FillFilter struct {
+	Account string
+	Symbol  string
+	From    time.Time // Inclusive
+	To      time.Time // Exclusive
+}
+
+func (f FillFilter) match(fl Fill) bool {
+	return (f.Account == "" || fl.Account == f.Account) &&
+		(f.Symbol == "" || fl.Symbol == f.Symbol) &&
+		(f.From.IsZero() || !fl.Time.Before(f.From)) &&
+		(f.To.IsZero() || fl.Time.Before(f.To))
+}
+
+// DailySummary resume los fills de un día de mercado por cuenta e instrumento.
+type DailySummary struct {
+	Date         string // yyyymmdd en MarketLocation
+	Account      string
+	Symbol       string
+	Fills        int
+	BuyQty       int64
+	BuyNotional  float64
+	SellQty      int64
+	SellNotional float64
+}
+
+// NetQty devuelve la cantidad neta operada (compras - ventas).
+func (s DailySummary) NetQty() int64 { return s.BuyQty - s.SellQty }
+
+// AvgBuyPx devuelve el precio promedio de compra (0 sin compras).
+func (s DailySummary) AvgBuyPx() float64 {
+	if s.BuyQty == 0 {
+		return 0
+	}
+	return s.BuyNotional / float64(s.BuyQty)
+}
+
+// AvgSellPx devuelve el precio promedio de venta (0 sin ventas).
+func (s DailySummary) AvgSellPx() float64 {
+	if s.SellQty == 0 {
+		return 0
+	}
+	return s.SellNotional / float64(s.SellQty)
+}
+
+// Ledger es el registro de fills (blotter) de una o más cuentas.
+//
+// Los fills se derivan de los Execution Reports (OnOrderReport, Watch) y de las órdenes
+// operadas consultadas por REST (Load), deduplicando por execId. FilledOrders solo trae
+// el último estado de cada orden: la cantidad operada que los reportes no explican se
+// registra como un fill agregado (Aggregated) al precio que completa el avgPx de la orden.
+//
+// Lo registrado de cada orden lógica (la cadena de reemplazos, ver Lineage) nunca supera
+// el mayor cumQty informado por sus órdenes, que conservan lo operado al reemplazarse:
+// un Execution Report que llega después de un fill agregado solo suma lo que este no
+// explicaba.
+//
+// Es la fuente de datos para PnL y conciliación.
+type Ledger struct {
+	mu      sync.RWMutex
+	fills   []Fill
+	execs   map[string]struct{}
+	orders  map[string]*ledgerOrder // Por clOrdId original de la cadena de reemplazos
+	lineage *Lineage                // Reemplazos deducidos de los reportes
+}
+
+// ledgerOrder acumula lo registrado de una orden lógica para detectar ejecuciones
+// faltantes y descartar las ya explicadas.
+type ledgerOrder struct {
+	qty      int64
+	notional float64
+	cum      int64 // Mayor cumQty informado por la cadena
+}
+
+// NewLedger crea un Ledger vacío.
+func NewLedger() *Ledger {
+	return &Ledger{execs: make(map[string]struct{}), orders: make(map[string]*ledgerOrder), lineage: NewLineage()}
+}
+
+// Add registra un fill. Devuelve false si su execId ya estaba registrado.
+func (l *Ledger) Add(f Fill) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.addLocked(f, l.orderLocked(f.ClOrdID, nil), 0)
+}
+
+// orderLocked devuelve lo registrado de la orden lógica de clOrdID, resuelta con el
+// Lineage propio y con lin (opcional, el del cliente).
+func (l *Ledger) orderLocked(clOrdID string, lin *Lineage) *ledgerOrder {
+	key := clOrdID
+	if lin != nil {
+		key = lin.Origin(key)
+	}
+	key = l.lineage.Origin(key)
+	o, ok := l.orders[key]
+	if !ok {
+		o = &ledgerOrder{}
+		l.orders[key] = o
+	}
+	return o
+}
+
+// addLocked registra f en la orden lógica o. Con cum > 0 (cumQty del reporte) la
+// cantidad se recorta a lo que lo ya registrado de la orden no explica.
+func (l *Ledger) addLocked(f Fill, o *ledgerOrder, cum int64) bool {
+	if f.ExecID == "" || f.Qty <= 0 {
+		return false
+	}
+	if _, dup := l.execs[f.ExecID]; dup {
+		return false
+	}
+	l.execs[f.ExecID] = struct{}{}
+	if cum > 0 {
+		if cum > o.cum {
+			o.cum = cum
+		}
+		if left := o.cum - o.qty; left < f.Qty {
+			f.Qty = left
+		}
+		if f.Qty <= 0 {
+			return false
+		}
+	}
+	l.fills = append(l.fills, f)
+	o.qty += f.Qty
+	o.notional += f.Notional()
+	return true
+}
+
+// OnOrderReport registra el fill de un Execution Report (status PARTIALLY_FILLED o
+// FILLED con lastQty). Devuelve el fill y si fue nuevo.
+func (l *Ledger) OnOrderReport(ev *model.OrderReportEvent) (Fill, bool) {
+	if ev == nil {
+		return Fill{}, false
+	}
+	l.lineage.OnOrderReport(ev)
+	rep := ev.OrderReport
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	o := l.orderLocked(rep.ClOrdID, nil)
+	cum := int64(0)
+	if rep.CumQty != nil {
+		cum = int64(*rep.CumQty)
+		if cum > o.cum {
+			o.cum = cum
+		}
+	}
+	f, ok := fillFromReport(ev)
+	if !ok || !l.addLocked(f, o, cum) {
+		return Fill{}, false
+	}
+	return l.fills[len(l.fills)-1], true
+}
+
+// fillFromReport arma el Fill de un Execution Report con ejecución.
+func fillFromReport(ev *model.OrderReportEvent) (Fill, bool) {
+	if ev == nil {
+		return Fill{}, false
+	}
+	rep := ev.OrderReport
+	status := model.OrderStatus(rep.Status)
+	if status != model.StatusPartiallyFilled && status != model.StatusFilled {
+		return Fill{}, false
+	}
+	if rep.ExecID == nil || rep.LastQty == nil || *rep.LastQty <= 0 || rep.LastPx == nil {
+		return Fill{}, false
+	}
+	f := Fill{
+		ExecID:  *rep.ExecID,
+		ClOrdID: rep.ClOrdID,
+		Symbol:  rep.InstrumentID.Symbol,
+		Market:  model.Market(rep.InstrumentID.MarketID),
+		Side:    model.Side(rep.Side),
+		Qty:     int64(*rep.LastQty),
+		Price:   *rep.LastPx,
+		Time:    fillTime(rep.TransactTime, ev.Timestamp),
+	}
+	if rep.OrderID != nil {
+		f.OrderID = *rep.OrderID
+	}
+	if rep.AccountID != nil {
+		f.Account = rep.AccountID.ID
+	}
+	return f, true
+}
+
+// Watch registra los fills de events hasta que ctx se cancele o el canal se cierre.
+func (l *Ledger) Watch(ctx context.Context, events <-chan *model.OrderReportEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			l.OnOrderReport(ev)
+		}
+	}
+}
+
+// Load consulta FilledOrders de la cuenta y registra la cantidad operada que no esté
+// explicada por fills ya registrados. Devuelve la cantidad de fills agregados.
+func (l *Ledger) Load(ctx context.Context, c *Client, account string) (int, error) {
+	res, err := c.FilledOrders(ctx, account)
+	if err == nil {
+		err = responseStatusError(res.Status)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ledger: filled orders: %w", err)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	added := 0
+	for _, o := range res.Orders {
+		lo := l.orderLocked(o.ClOrdID, c.Lineage())
+		if f, ok := l.missingFillLocked(o, lo, account); ok && l.addLocked(f, lo, 0) {
+			added++
+		}
+	}
+	return added, nil
+}
+
+// missingFillLocked arma el fill agregado con la cantidad operada de o que falta
+// registrar en su orden lógica lo.
+func (l *Ledger) missingFillLocked(o model.Order, lo *ledgerOrder, account string) (Fill, bool) {
+	if o.CumQty == nil || *o.CumQty <= 0 {
+		return Fill{}, false
+	}
+	if *o.CumQty > lo.cum {
+		lo.cum = *o.CumQty
+	}
+	known := *lo
+	missing := known.cum - known.qty
+	if missing <= 0 {
+		return Fill{}, false
+	}
+	var px float64
+	switch {
+	case o.AvgPx != nil:
+		px = (*o.AvgPx*float64(known.cum) - known.notional) / float64(missing)
+	case o.LastPx != nil:
+		px = *o.LastPx
+	case o.Price != nil:
+		px = *o.Price
+	default:
+		return Fill{}, false
+	}
+	execID := o.ExecID
+	if _, seen := l.execs[execID]; execID == "" || seen {
+		execID = fmt.Sprintf("%s-%d", o.ClOrdID, known.cum)
+	}
+	if o.AccountID != nil && o.AccountID.ID != "" {
+		account = o.AccountID.ID
+	}
+	// Es una ejecución exacta solo si la orden se operó en un único fill (lastQty == cumQty)
+	return Fill{
+		ExecID:     execID,
+		OrderID:    o.OrderID,
+		ClOrdID:    o.ClOrdID,
+		Account:    account,
+		Symbol:     o.InstrumentID.Symbol,
+		Market:     model.Market(o.InstrumentID.MarketID),
+		Side:       o.Side,
+		Qty:        missing,
+		Price:      px,
+		Time:       fillTime(o.TransactTime, nil),
+		Aggregated: known.qty > 0 || o.LastQty == nil || *o.LastQty != missing,
+	}, true
+}
+
+// Fills devuelve los fills que cumplen f, ordenados por hora.
+func (l *Ledger) Fills(f FillFilter) []Fill {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	out := make([]Fill, 0, len(l.fills))
+	for _, fl := range l.fills {
+		if f.match(fl) {
+			out = append(out, fl)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// DailySummaries agrupa los fills que cumplen f por día, cuenta e instrumento.
+func (l *Ledger) DailySummaries(f FillFilter) []DailySummary {
+	type key struct{ date, account, symbol string }
+	byKey := make(map[key]*DailySummary)
+	for _, fl := range l.Fills(f) {
+		k := key{formatDate(fl.Time), fl.Account, fl.Symbol}
+		s, ok := byKey[k]
+		if !ok {
+			s = &DailySummary{Date: k.date, Account: k.account, Symbol: k.symbol}
+			byKey[k] = s
+		}
+		s.Fills++
+		if fl.Side == model.Buy {
+			s.BuyQty += fl.Qty
+			s.BuyNotional += fl.Notional()
+		} else {
+			s.SellQty += fl.Qty
+			s.SellNotional += fl.Notional()
+		}
+	}
+	out := make([]DailySummary, 0, len(byKey))
+	for _, s := range byKey {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Account != b.Account {
+			return a.Account < b.Account
+		}
+		return a.Symbol < b.Symbol
+	})
+	return out
+}
+
+// WriteCSV exporta el blotter de los fills que cumplen f en CSV, con encabezado.
+func (l *Ledger) WriteCSV(w io.Writer, f FillFilter) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time", "account", "symbol", "market", "side", "qty", "price", "exec_id", "order_id", "cl_ord_id", "aggregated"})
+	for _, fl := range l.Fills(f) {
+		_ = cw.Write([]string{
+			fl.Time.In(MarketLocation).Format(time.RFC3339),
+			fl.Account,
+			fl.Symbol,
+			string(fl.Market),
+			string(fl.Side),
+			strconv.FormatInt(fl.Qty, 10),
+			strconv.FormatFloat(fl.Price, 'f', -1, 64),
+			fl.ExecID,
+			fl.OrderID,
+			fl.ClOrdID,
+			strconv.FormatBool(fl.Aggregated),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON exporta el blotter de los fills que cumplen f como un array JSON.
+func (l *Ledger) WriteJSON(w io.Writer, f FillFilter) error {
+	return json.NewEncoder(w).Encode(l.Fills(f))
+}
+
+// fillTime interpreta el transactTime de un reporte (se ignoran milisegundos o zona
+// al final); si falta usa el timestamp del mensaje (ms) o, en último caso, la hora actual.
+func fillTime(transactTime string, timestamp *int64) time.Time {
+	if len(transactTime) > len(transactTimeLayout) {
+		transactTime = transactTime[:len(transactTimeLayout)]
+	}
+	if t, err := time.ParseInLocation(transactTimeLayout, transactTime, MarketLocation); err == nil {
+		return t
+	}
+	if timestamp != nil {
+		return time.UnixMilli(*timestamp)
+	}
+	return time.Now()
+}
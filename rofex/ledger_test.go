@@ -0,0 +1,171 @@
+package rofex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestLedger(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	fill := func(clOrdID, status, execID, side, symbol, at string, last int, px float64) *model.OrderReportEvent {
+		rep := model.OrderDetails{
+			ClOrdID:      clOrdID,
+			Status:       status,
+			Side:         side,
+			AccountID:    &model.AccountReference{ID: account},
+			InstrumentID: model.InstrumentID{Symbol: symbol, MarketID: "ROFX"},
+			TransactTime: at,
+		}
+		if last > 0 {
+			rep.ExecID, rep.LastQty, rep.LastPx = &execID, &last, &px
+		}
+		return &model.OrderReportEvent{Type: model.WSMessageOrderReport, OrderReport: rep}
+	}
+
+	l := NewLedger()
+	l.OnOrderReport(fill("o1", "NEW", "", "BUY", "DLR/MAR26", "20260302-11:00:00", 0, 0))
+	if _, ok := l.OnOrderReport(fill("o1", "PARTIALLY_FILLED", "e1", "BUY", "DLR/MAR26", "20260302-11:00:01", 4, 1000)); !ok {
+		t.Fatalf("fill not recorded")
+	}
+	if _, ok := l.OnOrderReport(fill("o1", "PARTIALLY_FILLED", "e1", "BUY", "DLR/MAR26", "20260302-11:00:01", 4, 1000)); ok {
+		t.Fatalf("duplicate execId recorded")
+	}
+	l.OnOrderReport(fill("o2", "FILLED", "e2", "SELL", "DLR/MAR26", "20260302-15:00:00.250-0300", 3, 1010))
+	l.OnOrderReport(fill("o3", "FILLED", "e3", "BUY", "GGAL/ABR26", "20260303-12:00:00", 10, 5000))
+
+	// FilledOrders: o1 terminó con 10 operados a promedio 1003 (faltan 6 a 1005),
+	// o2 ya está completo y o4 no tiene reportes
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/filleds") {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		inst := map[string]any{"marketId": "ROFX", "symbol": "DLR/MAR26"}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "orders": []map[string]any{
+			{"clOrdId": "o1", "execId": "e9", "side": "BUY", "instrumentId": inst, "cumQty": 10, "avgPx": 1003, "transactTime": "20260302-11:05:00"},
+			{"clOrdId": "o2", "execId": "e2", "side": "SELL", "instrumentId": inst, "cumQty": 3, "avgPx": 1010, "transactTime": "20260302-15:00:00"},
+			{"clOrdId": "o4", "execId": "e7", "side": "SELL", "instrumentId": inst, "cumQty": 1, "lastQty": 1, "avgPx": 1001, "transactTime": "20260303-10:00:00"},
+		}})
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	added, err := l.Load(context.Background(), c, account)
+	if err != nil || added != 2 {
+		t.Fatalf("Load: added=%d err=%v", added, err)
+	}
+
+	fills := l.Fills(FillFilter{Account: account, Symbol: "DLR/MAR26"})
+	if len(fills) != 4 {
+		t.Fatalf("fills: %+v", fills)
+	}
+	if f := fills[1]; f.ExecID != "e9" || f.Qty != 6 || f.Price != 1005 || !f.Aggregated {
+		t.Fatalf("aggregated fill: %+v", f)
+	}
+	if f := fills[3]; f.ExecID != "e7" || f.Account != account || f.Aggregated {
+		t.Fatalf("single fill from REST: %+v", f)
+	}
+
+	days := l.DailySummaries(FillFilter{Account: account})
+	if len(days) != 3 {
+		t.Fatalf("summaries: %+v", days)
+	}
+	if d := days[0]; d.Date != "20260302" || d.Symbol != "DLR/MAR26" || d.BuyQty != 10 || d.AvgBuyPx() != 1003 || d.SellQty != 3 || d.NetQty() != 7 || d.Fills != 3 {
+		t.Fatalf("summary: %+v", d)
+	}
+
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf, FillFilter{Symbol: "GGAL/ABR26"}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	want := "time,account,symbol,market,side,qty,price,exec_id,order_id,cl_ord_id,aggregated\n" +
+		"2026-03-03T12:00:00-03:00,REM6771,GGAL/ABR26,ROFX,BUY,10,5000,e3,,o3,false\n"
+	if buf.String() != want {
+		t.Fatalf("csv:\nwant %q\ngot  %q", want, buf.String())
+	}
+	buf.Reset()
+	if err := l.WriteJSON(&buf, FillFilter{Symbol: "GGAL/ABR26"}); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var decoded []Fill
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].ExecID != "e3" {
+		t.Fatalf("json: %s (%v)", buf.String(), err)
+	}
+}
+
+func TestLedger_CapsAtCumQty(t *testing.T) {
+	account := "REM6771"
+	inst := map[string]any{"marketId": "ROFX", "symbol": "DLR/MAR26"}
+	report := func(clOrdID, status, execID, at string, last, cum int, text string) *model.OrderReportEvent {
+		px := 1000.0
+		rep := model.OrderDetails{
+			ClOrdID:      clOrdID,
+			Status:       status,
+			Side:         "BUY",
+			AccountID:    &model.AccountReference{ID: account},
+			InstrumentID: model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"},
+			TransactTime: at,
+			CumQty:       &cum,
+		}
+		if last > 0 {
+			rep.ExecID, rep.LastQty, rep.LastPx = &execID, &last, &px
+		}
+		if text != "" {
+			rep.Text = &text
+		}
+		return &model.OrderReportEvent{Type: model.WSMessageOrderReport, OrderReport: rep}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "orders": []map[string]any{
+			{"clOrdId": "a1", "side": "BUY", "instrumentId": inst, "cumQty": 6, "avgPx": 1000, "transactTime": "20260302-11:00:00"},
+			{"clOrdId": "b1", "side": "BUY", "instrumentId": inst, "status": "CANCELLED", "text": "Reemplazada", "cumQty": 3, "avgPx": 1000, "transactTime": "20260302-12:00:00"},
+			{"clOrdId": "b2", "side": "BUY", "instrumentId": inst, "cumQty": 5, "avgPx": 1000, "transactTime": "20260302-12:05:00"},
+		}})
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+
+	l := NewLedger()
+	// b1 se reemplaza por b2, que conserva lo operado por b1
+	l.OnOrderReport(report("b1", "PARTIALLY_FILLED", "x1", "20260302-11:30:00", 3, 3, ""))
+	l.OnOrderReport(report("b1", "CANCELLED", "", "20260302-12:00:00", 0, 3, "Reemplazada"))
+	l.OnOrderReport(report("b2", "NEW", "", "20260302-12:00:00", 0, 3, ""))
+	if _, err := l.Load(context.Background(), c, account); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// Los reportes reales de a1 llegan después del fill agregado de Load
+	l.OnOrderReport(report("a1", "PARTIALLY_FILLED", "r1", "20260302-11:00:00", 4, 4, ""))
+	l.OnOrderReport(report("a1", "FILLED", "r2", "20260302-11:00:00", 2, 6, ""))
+	// Un fill nuevo de b2 más allá de lo que informó Load sí se registra
+	if f, ok := l.OnOrderReport(report("b2", "FILLED", "x3", "20260302-12:10:00", 1, 6, "")); !ok || f.Qty != 1 {
+		t.Fatalf("new fill: %+v %v", f, ok)
+	}
+
+	var a, b int64
+	for _, f := range l.Fills(FillFilter{}) {
+		if f.ClOrdID == "a1" {
+			a += f.Qty
+		} else {
+			b += f.Qty
+		}
+	}
+	if a != 6 || b != 6 {
+		t.Fatalf("double counted: a1=%d b=%d fills=%+v", a, b, l.Fills(FillFilter{}))
+	}
+
+	// Una respuesta con status ERROR es un error, no un listado vacío
+	errTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "ERROR", "description": "unavailable"})
+	}))
+	defer errTS.Close()
+	c2, _ := NewClient(WithBaseURL(errTS.URL + "/"))
+	if _, err := l.Load(context.Background(), c2, account); err == nil {
+		t.Fatalf("Load with status ERROR: want error")
+	}
+}
@@ -0,0 +1,311 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Lineage registra la cadena de reemplazos de cada orden lógica.
+//
+// ReplaceOrder devuelve un clientId nuevo y la orden anterior termina CANCELLED con
+// text "Reemplazada"; el mercado no informa la relación entre ambas. El cliente registra
+// cada reemplazo exitoso en su Lineage (ver Client.Lineage), que permite resolver el
+// clOrdID vigente de cualquier ancestro y reconstruir la cadena completa.
+//
+// Los reemplazos hechos por otras vías (ReplaceOrderWS, otra sesión) se deducen de los
+// Execution Reports (OnOrderReport, Watch): el cierre "Reemplazada" de una orden se
+// vincula con el NEW del mismo orderId o, si el reporte no lo trae, de la misma cuenta,
+// instrumento, lado y transactTime. Como transactTime tiene resolución de segundos, esta
+// última clave solo vincula cuando no hay otro reemplazo pendiente con la misma clave.
+// El cliente alimenta su Lineage con sus suscripciones a Execution Reports. Las cadenas
+// cuya orden vigente terminó se descartan luego de LineageRetention.
+type Lineage struct {
+	mu      sync.RWMutex
+	prev    map[string]string         // clOrdID -> clOrdID reemplazado
+	next    map[string]string         // clOrdID -> clOrdID que lo reemplazó
+	pending map[string][]*lineageHalf // clave de correlación -> mitades de reemplazos sin pareja
+	done    map[string]time.Time      // clOrdID vigente terminado -> cuándo
+	now     func() time.Time
+}
+
+// LineageRetention es el tiempo que una cadena cuya orden vigente terminó sigue
+// disponible antes de descartarse.
+const LineageRetention = time.Hour
+
+// lineagePairWindow es la espera máxima entre el cierre "Reemplazada" y el NEW del
+// reemplazo (en cualquier orden) para vincularlos.
+const lineagePairWindow = time.Minute
+
+// lineageHalf es un cierre "Reemplazada" (replaced) o un NEW esperando su pareja.
+type lineageHalf struct {
+	id       string
+	replaced bool
+	keys     []string
+	at       time.Time
+}
+
+// NewLineage crea un Lineage vacío.
+func NewLineage() *Lineage {
+	return &Lineage{
+		prev:    make(map[string]string),
+		next:    make(map[string]string),
+		pending: make(map[string][]*lineageHalf),
+		done:    make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Record registra que oldID fue reemplazado por newID.
+func (l *Lineage) Record(oldID, newID string) {
+	if oldID == "" || newID == "" || oldID == newID {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pruneLocked(l.now())
+	l.recordLocked(oldID, newID)
+}
+
+func (l *Lineage) recordLocked(oldID, newID string) {
+	l.next[oldID] = newID
+	l.prev[newID] = oldID
+}
+
+// OnOrderReport deduce reemplazos de los Execution Reports y marca como terminadas
+// las cadenas cuya orden vigente alcanzó un estado final.
+func (l *Lineage) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil || ev.OrderReport.ClOrdID == "" {
+		return
+	}
+	rep := ev.OrderReport
+	id := rep.ClOrdID
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	l.pruneLocked(now)
+
+	switch {
+	case rep.IsReplaced():
+		if _, ok := l.next[id]; !ok {
+			l.pairLocked(&lineageHalf{id: id, replaced: true, keys: lineageKeys(rep), at: now})
+		}
+	case model.OrderStatus(rep.Status) == model.StatusNew:
+		if _, ok := l.prev[id]; !ok {
+			l.pairLocked(&lineageHalf{id: id, keys: lineageKeys(rep), at: now})
+		}
+	case model.OrderStatus(rep.Status).IsTerminal():
+		if _, ok := l.prev[id]; ok {
+			l.done[id] = now
+		}
+	}
+}
+
+// pairLocked vincula h con la mitad opuesta que comparta alguna clave o la deja
+// pendiente. Por orderId se vincula con la primera mitad opuesta; por transactTime,
+// solo si es la única mitad pendiente con esa clave (si hay más, el vínculo es ambiguo).
+func (l *Lineage) pairLocked(h *lineageHalf) {
+	for _, k := range h.keys {
+		var match *lineageHalf
+		others := 0
+		for _, o := range l.pending[k] {
+			if o.id == h.id {
+				continue
+			}
+			others++
+			if o.replaced != h.replaced && match == nil {
+				match = o
+			}
+		}
+		if match == nil || (!strings.HasPrefix(k, "id:") && others > 1) {
+			continue
+		}
+		l.dropLocked(match)
+		if h.replaced {
+			l.recordLocked(h.id, match.id)
+		} else {
+			l.recordLocked(match.id, h.id)
+		}
+		return
+	}
+	for _, k := range h.keys {
+		l.pending[k] = append(l.pending[k], h)
+	}
+}
+
+func (l *Lineage) dropLocked(h *lineageHalf) {
+	for _, k := range h.keys {
+		kept := l.pending[k][:0]
+		for _, o := range l.pending[k] {
+			if o != h {
+				kept = append(kept, o)
+			}
+		}
+		if len(kept) == 0 {
+			delete(l.pending, k)
+		} else {
+			l.pending[k] = kept
+		}
+	}
+}
+
+// pruneLocked descarta las mitades sin pareja vencidas y las cadenas terminadas hace
+// más de LineageRetention.
+func (l *Lineage) pruneLocked(now time.Time) {
+	var expired []*lineageHalf
+	for _, halves := range l.pending {
+		for _, h := range halves {
+			if now.Sub(h.at) > lineagePairWindow {
+				expired = append(expired, h)
+			}
+		}
+	}
+	for _, h := range expired {
+		l.dropLocked(h)
+	}
+	for id, at := range l.done {
+		if now.Sub(at) <= LineageRetention {
+			continue
+		}
+		delete(l.done, id)
+		for n := 0; n <= len(l.prev); n++ {
+			pv, ok := l.prev[id]
+			delete(l.prev, id)
+			if !ok {
+				break
+			}
+			delete(l.next, pv)
+			id = pv
+		}
+	}
+}
+
+// lineageKeys devuelve las claves que correlacionan el cierre de una orden reemplazada
+// con el NEW de su reemplazo.
+func lineageKeys(rep model.OrderDetails) []string {
+	var keys []string
+	if rep.OrderID != nil && *rep.OrderID != "" {
+		keys = append(keys, "id:"+*rep.OrderID)
+	}
+	if rep.TransactTime != "" {
+		account := ""
+		if rep.AccountID != nil {
+			account = rep.AccountID.ID
+		}
+		keys = append(keys, strings.Join([]string{"at", account, rep.InstrumentID.MarketID,
+			rep.InstrumentID.Symbol, rep.Side, rep.TransactTime}, "|"))
+	}
+	return keys
+}
+
+// Watch consume Execution Reports hasta que ctx se cancele o el canal se cierre.
+func (l *Lineage) Watch(ctx context.Context, orders <-chan *model.OrderReportEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-orders:
+			if !ok {
+				return
+			}
+			l.OnOrderReport(ev)
+		}
+	}
+}
+
+// Live devuelve el clOrdID vigente de la orden lógica a la que pertenece id
+// (id mismo si nunca fue reemplazado).
+func (l *Lineage) Live(id string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for n := 0; n <= len(l.next); n++ {
+		nx, ok := l.next[id]
+		if !ok {
+			break
+		}
+		id = nx
+	}
+	return id
+}
+
+// Origin devuelve el clOrdID original de la orden lógica a la que pertenece id.
+func (l *Lineage) Origin(id string) string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for n := 0; n <= len(l.prev); n++ {
+		pv, ok := l.prev[id]
+		if !ok {
+			break
+		}
+		id = pv
+	}
+	return id
+}
+
+// Chain devuelve la cadena de clOrdIDs de la orden lógica, del original al vigente.
+func (l *Lineage) Chain(id string) []string {
+	id = l.Origin(id)
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	chain := []string{id}
+	for n := 0; n < len(l.next); n++ {
+		nx, ok := l.next[id]
+		if !ok {
+			break
+		}
+		chain = append(chain, nx)
+		id = nx
+	}
+	return chain
+}
+
+// Lineage devuelve el registro de reemplazos del cliente.
+func (c *Client) Lineage() *Lineage { return c.lineage }
+
+// liveID resuelve el clOrdID vigente si la redirección de ids reemplazados está habilitada.
+func (c *Client) liveID(clOrdID string) string {
+	if !c.redirectStale {
+		return clOrdID
+	}
+	live := c.lineage.Live(clOrdID)
+	if live != clOrdID && c.logger != nil {
+		c.logger.Debug("stale clOrdId redirected", slog.String("clOrdId", clOrdID), slog.String("live", live))
+	}
+	return live
+}
+
+// OrderTimeline devuelve todos los estados de la orden lógica a la que pertenece clOrdID,
+// uniendo los historiales de OrderHistoryByClOrdID de cada eslabón de la cadena de
+// reemplazos en una única línea de tiempo ordenada por transactTime.
+//
+// Ejemplo:
+//
+//	res, _ := client.ReplaceOrder(ctx, "user123", "", nil, &newPrice)
+//	timeline, err := client.OrderTimeline(ctx, "user123", "")
+//	for _, o := range timeline {
+//		fmt.Println(o.ClOrdID, o.Status, o.TransactTime)
+//	}
+//
+// Referencia: docs/primary-api.md - "Consultar todos los estados por Client Order ID"
+func (c *Client) OrderTimeline(ctx context.Context, clOrdID, proprietary string) ([]model.Order, error) {
+	if clOrdID == "" {
+		return nil, &ValidationError{Field: "clOrdID", Msg: "required"}
+	}
+	var timeline []model.Order
+	for _, id := range c.lineage.Chain(clOrdID) {
+		res, err := c.OrderHistoryByClOrdID(ctx, id, proprietary)
+		if err != nil {
+			return nil, fmt.Errorf("order timeline %s: %w", id, err)
+		}
+		timeline = append(timeline, res.Orders...)
+	}
+	// transactTime (20060102-15:04:05) ordena lexicográficamente; a igual hora se conserva el orden de la cadena
+	sort.SliceStable(timeline, func(i, j int) bool { return timeline[i].TransactTime < timeline[j].TransactTime })
+	return timeline, nil
+}
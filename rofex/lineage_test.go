@@ -0,0 +1,174 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestLineage_ReplaceChainRedirectAndTimeline(t *testing.T) {
+	// Constantes
+	var cancelled, queried []string
+	history := map[string][]any{
+		"a1": {
+			map[string]any{"clOrdId": "a1", "status": "PENDING_NEW", "transactTime": "20230720-11:00:00"},
+			map[string]any{"clOrdId": "a1", "status": "NEW", "transactTime": "20230720-11:00:01"},
+			map[string]any{"clOrdId": "a1", "status": "CANCELLED", "text": "Reemplazada", "transactTime": "20230720-11:05:00"},
+		},
+		"a2": {
+			map[string]any{"clOrdId": "a2", "status": "NEW", "transactTime": "20230720-11:05:00"},
+			map[string]any{"clOrdId": "a2", "status": "CANCELLED", "text": "Reemplazada", "transactTime": "20230720-11:10:00"},
+		},
+		"a3": {
+			map[string]any{"clOrdId": "a3", "status": "NEW", "transactTime": "20230720-11:10:00"},
+		},
+	}
+	next := map[string]string{"a1": "a2", "a2": "a3"}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/rest/order/replaceById":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": next[q.Get("clOrdId")]}})
+		case "/rest/order/cancelById":
+			cancelled = append(cancelled, q.Get("clOrdId"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": q.Get("clOrdId")}})
+		case "/rest/order/id":
+			queried = append(queried, q.Get("clOrdId"))
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clOrdId": q.Get("clOrdId"), "status": "NEW"}})
+		case "/rest/order/allById":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "orders": history[q.Get("clOrdId")]})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithLineageRedirect(true))
+	ctx := context.Background()
+
+	price := 200.5
+	if _, err := c.ReplaceOrder(ctx, "a1", "", nil, &price); err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	// Reemplazo con el id original: se redirige a a2 y la cadena sigue a a3
+	if _, err := c.ReplaceOrder(ctx, "a1", "", nil, &price); err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+
+	l := c.Lineage()
+	if got := l.Live("a1"); got != "a3" {
+		t.Fatalf("live: want a3 got %s", got)
+	}
+	if got := l.Origin("a3"); got != "a1" {
+		t.Fatalf("origin: want a1 got %s", got)
+	}
+	if got := l.Chain("a2"); len(got) != 3 || got[0] != "a1" || got[2] != "a3" {
+		t.Fatalf("chain: %v", got)
+	}
+
+	if _, err := c.OrderStatus(ctx, "a1", ""); err != nil {
+		t.Fatalf("OrderStatus: %v", err)
+	}
+	if _, err := c.CancelOrder(ctx, "a2", ""); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if len(queried) != 1 || queried[0] != "a3" || len(cancelled) != 1 || cancelled[0] != "a3" {
+		t.Fatalf("redirect: status=%v cancel=%v", queried, cancelled)
+	}
+
+	timeline, err := c.OrderTimeline(ctx, "a3", "")
+	if err != nil {
+		t.Fatalf("OrderTimeline: %v", err)
+	}
+	want := []string{"a1 PENDING_NEW", "a1 NEW", "a1 CANCELLED", "a2 NEW", "a2 CANCELLED", "a3 NEW"}
+	if len(timeline) != len(want) {
+		t.Fatalf("timeline: want %d entries got %d", len(want), len(timeline))
+	}
+	for i, o := range timeline {
+		if got := o.ClOrdID + " " + o.Status; got != want[i] {
+			t.Fatalf("timeline[%d]: want %q got %q", i, want[i], got)
+		}
+	}
+
+	// Sin WithLineageRedirect se usa el id indicado
+	c2, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	c2.Lineage().Record("a1", "a2")
+	if _, err := c2.CancelOrder(ctx, "a1", ""); err != nil || cancelled[len(cancelled)-1] != "a1" {
+		t.Fatalf("no redirect expected: %v %v", err, cancelled)
+	}
+}
+
+func TestLineage_FromOrderReports(t *testing.T) {
+	l := NewLineage()
+	now := time.Date(2023, 7, 20, 11, 0, 0, 0, MarketLocation)
+	l.now = func() time.Time { return now }
+	report := func(id, orderID, status, text, at string) *model.OrderReportEvent {
+		rep := model.OrderDetails{
+			ClOrdID:      id,
+			AccountID:    &model.AccountReference{ID: "REM6771"},
+			InstrumentID: model.InstrumentID{Symbol: "DLR/DIC23", MarketID: "ROFX"},
+			Side:         "BUY",
+			Status:       status,
+			TransactTime: at,
+		}
+		if orderID != "" {
+			rep.OrderID = &orderID
+		}
+		if text != "" {
+			rep.Text = &text
+		}
+		return &model.OrderReportEvent{OrderReport: rep}
+	}
+
+	// Cierre y NEW con el mismo orderId
+	l.OnOrderReport(report("a1", "1", "NEW", "", "20230720-11:00:00"))
+	l.OnOrderReport(report("a1", "1", "CANCELLED", "Reemplazada", "20230720-11:05:00"))
+	l.OnOrderReport(report("a2", "1", "NEW", "", "20230720-11:05:00"))
+	// NEW antes del cierre, correlacionados por cuenta, instrumento, lado y transactTime
+	l.OnOrderReport(report("a3", "", "NEW", "", "20230720-11:10:00"))
+	l.OnOrderReport(report("a2", "", "CANCELLED", "Reemplazada", "20230720-11:10:00"))
+	// Una orden nueva sin relación no se vincula
+	l.OnOrderReport(report("b1", "2", "NEW", "", "20230720-11:11:00"))
+
+	if got := l.Chain("a1"); fmt.Sprint(got) != "[a1 a2 a3]" {
+		t.Fatalf("chain: %v", got)
+	}
+	if got := l.Origin("b1"); got != "b1" {
+		t.Fatalf("unrelated order linked to %s", got)
+	}
+
+	// La cadena terminada se descarta después de LineageRetention
+	l.OnOrderReport(report("a3", "", "FILLED", "", "20230720-11:20:00"))
+	if got := l.Live("a1"); got != "a3" {
+		t.Fatalf("live before retention: %s", got)
+	}
+	now = now.Add(LineageRetention + time.Minute)
+	l.OnOrderReport(report("b1", "2", "CANCELLED", "", "20230720-12:30:00"))
+	if got := l.Live("a1"); got != "a1" || len(l.prev) != 0 || len(l.next) != 0 || len(l.pending) != 0 {
+		t.Fatalf("chain not pruned: live=%s prev=%v next=%v pending=%d", got, l.prev, l.next, len(l.pending))
+	}
+	// Dos reemplazos sin orderId en el mismo segundo: el vínculo es ambiguo y no se deduce
+	l.OnOrderReport(report("c1", "", "CANCELLED", "Reemplazada", "20230720-12:31:00"))
+	l.OnOrderReport(report("d1", "", "CANCELLED", "Reemplazada", "20230720-12:31:00"))
+	l.OnOrderReport(report("d2", "", "NEW", "", "20230720-12:31:00"))
+	l.OnOrderReport(report("c2", "", "NEW", "", "20230720-12:31:00"))
+	if l.Live("c1") != "c1" || l.Live("d1") != "d1" {
+		t.Fatalf("ambiguous replaces linked: c1->%s d1->%s", l.Live("c1"), l.Live("d1"))
+	}
+
+	// Record también descarta lo vencido, sin esperar otro Execution Report
+	l.Record("e1", "e2")
+	l.OnOrderReport(report("e2", "", "FILLED", "", "20230720-12:32:00"))
+	now = now.Add(LineageRetention + time.Minute)
+	l.Record("f1", "f2")
+	if got := l.Live("e1"); got != "e1" || len(l.pending) != 0 || len(l.done) != 0 {
+		t.Fatalf("not pruned on Record: live=%s pending=%d done=%d", got, len(l.pending), len(l.done))
+	}
+}
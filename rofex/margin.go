@@ -0,0 +1,240 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// MarginAlert identifica un umbral del MarginMonitor.
+type MarginAlert string
+
+const (
+	AlertUncoveredMargin    MarginAlert = "UNCOVERED_MARGIN"     // uncoveredMargin > 0
+	AlertAvailableToOperate MarginAlert = "AVAILABLE_TO_OPERATE" // Disponible para operar por debajo del mínimo
+	AlertMarginUsage        MarginAlert = "MARGIN_USAGE"         // Uso de margen por encima del máximo
+)
+
+// MarginThresholds son los umbrales de alerta de una cuenta. Los valores en cero no
+// generan alertas; el margen descubierto siempre genera una.
+type MarginThresholds struct {
+	MinAvailableToOperate float64 // Mínimo de AvailableToOperate
+	MaxMarginUsage        float64 // Máximo de MarginUsage (0..1)
+}
+
+// MarginOptions configura un MarginMonitor.
+type MarginOptions struct {
+	Accounts   []string                    // Cuentas a monitorear (requerido)
+	Interval   time.Duration               // Separación entre consultas a AccountReport (mínimo y por defecto 5s)
+	Thresholds MarginThresholds            // Umbrales por defecto
+	PerAccount map[string]MarginThresholds // Umbrales por cuenta (reemplazan a Thresholds)
+	Buffer     int                         // Capacidad del canal de Events (por defecto 64)
+}
+
+// MarginSnapshot es el último estado de margen conocido de una cuenta.
+type MarginSnapshot struct {
+	Account               string
+	Collateral            float64
+	Margin                float64
+	UncoveredMargin       float64
+	AvailableToCollateral float64
+	AvailableToOperate    float64       // Total disponible para operar en la liquidación más próxima
+	MarginUsage           float64       // Margin / (Margin + AvailableToCollateral)
+	Alerts                []MarginAlert // Umbrales superados actualmente
+	Report                model.AccountData
+	UpdatedAt             time.Time
+	Err                   error // Error de la última consulta; los valores son los de la anterior exitosa
+}
+
+// Breached indica si la cuenta supera algún umbral.
+func (s MarginSnapshot) Breached() bool { return len(s.Alerts) > 0 }
+
+// MarginEvent informa que una cuenta cruzó un umbral (Triggered) o volvió a estar
+// dentro de él.
+type MarginEvent struct {
+	Account   string
+	Alert     MarginAlert
+	Triggered bool
+	Value     float64
+	Threshold float64
+	Snapshot  MarginSnapshot
+}
+
+// MarginMonitor consulta AccountReport de un conjunto de cuentas y emite eventos cuando
+// se cruzan los umbrales de margen y disponible.
+//
+// La API admite una consulta de reportes cada 5 segundos: el monitor consulta una
+// cuenta por Interval en forma rotativa, por lo que cada cuenta se actualiza cada
+// Interval * len(Accounts).
+//
+// Snapshot es seguro para uso concurrente y puede consultarse desde los controles
+// previos al envío de órdenes.
+//
+// Ejemplo:
+//
+//	mon := rofex.NewMarginMonitor(client, rofex.MarginOptions{
+//		Accounts:   []string{"REM6771"},
+//		Thresholds: rofex.MarginThresholds{MinAvailableToOperate: 1_000_000, MaxMarginUsage: 0.8},
+//	})
+//	go mon.Run(ctx)
+//	for ev := range mon.Events() {
+//		log.Printf("%s %s triggered=%v value=%v", ev.Account, ev.Alert, ev.Triggered, ev.Value)
+//	}
+type MarginMonitor struct {
+	client *Client
+	opts   MarginOptions
+	events chan MarginEvent
+
+	mu        sync.RWMutex
+	snapshots map[string]MarginSnapshot
+}
+
+// NewMarginMonitor crea un MarginMonitor.
+func NewMarginMonitor(c *Client, opts MarginOptions) *MarginMonitor {
+	if opts.Interval < 5*time.Second {
+		opts.Interval = 5 * time.Second
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 64
+	}
+	return &MarginMonitor{
+		client:    c,
+		opts:      opts,
+		events:    make(chan MarginEvent, opts.Buffer),
+		snapshots: make(map[string]MarginSnapshot),
+	}
+}
+
+// Events devuelve el canal de cruces de umbral.
+func (m *MarginMonitor) Events() <-chan MarginEvent { return m.events }
+
+// Snapshot devuelve el último estado conocido de la cuenta.
+func (m *MarginMonitor) Snapshot(account string) (MarginSnapshot, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.snapshots[account]
+	return s, ok
+}
+
+// Snapshots devuelve el último estado de todas las cuentas consultadas, por cuenta.
+func (m *MarginMonitor) Snapshots() []MarginSnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]MarginSnapshot, 0, len(m.snapshots))
+	for _, s := range m.snapshots {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Account < out[j].Account })
+	return out
+}
+
+// Run consulta las cuentas en forma rotativa hasta que ctx se cancele.
+func (m *MarginMonitor) Run(ctx context.Context) error {
+	if len(m.opts.Accounts) == 0 {
+		return &ValidationError{Field: "accounts", Msg: "required"}
+	}
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for i := 0; ; i = (i + 1) % len(m.opts.Accounts) {
+		if _, err := m.Poll(ctx, m.opts.Accounts[i]); err != nil && ctx.Err() == nil && m.client.logger != nil {
+			m.client.logger.Warn("margin monitor: account report failed", slog.String("account", m.opts.Accounts[i]), slog.Any("err", err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll consulta AccountReport de una cuenta, actualiza su Snapshot y emite los eventos
+// de los umbrales cruzados. No controla el límite de 5 segundos entre consultas.
+func (m *MarginMonitor) Poll(ctx context.Context, account string) (MarginSnapshot, error) {
+	res, err := m.client.AccountReport(ctx, account)
+	if err == nil {
+		err = responseStatusError(res.Status)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev := m.snapshots[account]
+	if err != nil {
+		prev.Account, prev.Err = account, fmt.Errorf("margin monitor: %w", err)
+		m.snapshots[account] = prev
+		return prev, prev.Err
+	}
+
+	s := newMarginSnapshot(account, res.AccountData)
+	th := m.opts.Thresholds
+	if t, ok := m.opts.PerAccount[account]; ok {
+		th = t
+	}
+	checks := []struct {
+		alert            MarginAlert
+		on               bool
+		value, threshold float64
+	}{
+		{AlertUncoveredMargin, s.UncoveredMargin > 0, s.UncoveredMargin, 0},
+		{AlertAvailableToOperate, th.MinAvailableToOperate > 0 && s.AvailableToOperate < th.MinAvailableToOperate, s.AvailableToOperate, th.MinAvailableToOperate},
+		{AlertMarginUsage, th.MaxMarginUsage > 0 && s.MarginUsage > th.MaxMarginUsage, s.MarginUsage, th.MaxMarginUsage},
+	}
+	var events []MarginEvent
+	for _, ch := range checks {
+		if ch.on {
+			s.Alerts = append(s.Alerts, ch.alert)
+		}
+		if ch.on != hasAlert(prev.Alerts, ch.alert) {
+			events = append(events, MarginEvent{Account: account, Alert: ch.alert, Triggered: ch.on, Value: ch.value, Threshold: ch.threshold})
+		}
+	}
+	m.snapshots[account] = s
+	for _, ev := range events {
+		ev.Snapshot = s
+		select {
+		case m.events <- ev:
+		default:
+			if m.client.logger != nil {
+				m.client.logger.Warn("margin monitor: event dropped - channel full", slog.String("account", account), slog.String("alert", string(ev.Alert)))
+			}
+		}
+	}
+	return s, nil
+}
+
+// newMarginSnapshot resume el reporte de la cuenta.
+func newMarginSnapshot(account string, d model.AccountData) MarginSnapshot {
+	s := MarginSnapshot{
+		Account:               account,
+		Collateral:            d.Collateral,
+		Margin:                d.Margin,
+		UncoveredMargin:       d.UncoveredMargin,
+		AvailableToCollateral: d.AvailableToCollateral,
+		Report:                d,
+		UpdatedAt:             time.Now(),
+	}
+	if total := d.Margin + d.AvailableToCollateral; total > 0 {
+		s.MarginUsage = d.Margin / total
+	}
+	// Disponible de la liquidación más próxima
+	first := int64(-1)
+	for _, r := range d.DetailedAccountReports {
+		if first < 0 || r.SettlementDate < first {
+			first = r.SettlementDate
+			s.AvailableToOperate = r.AvailableToOperate.Total
+		}
+	}
+	return s
+}
+
+func hasAlert(alerts []MarginAlert, a MarginAlert) bool {
+	for _, x := range alerts {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarginMonitor_Poll(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	report := func(margin, availColl, uncovered float64, avail ...float64) map[string]any {
+		reports := map[string]any{}
+		for i, total := range avail {
+			reports[string(rune('0'+i))] = map[string]any{
+				"availableToOperate": map[string]any{"total": total},
+				"settlementDate":     1669950000000 + int64(i)*86400000,
+			}
+		}
+		return map[string]any{"status": "OK", "accountData": map[string]any{
+			"accountName": account, "margin": margin, "availableToCollateral": availColl,
+			"uncoveredMargin": uncovered, "detailedAccountReports": reports,
+		}}
+	}
+	responses := []map[string]any{
+		report(80, 20, 0, 500_000, 3_000_000),
+		report(50, 50, 10, 2_000_000),
+		{"status": "ERROR", "description": "account report unavailable"},
+		nil,
+	}
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/risk/accountReport/"+account {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		res := responses[calls]
+		calls++
+		if res == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	mon := NewMarginMonitor(c, MarginOptions{
+		Accounts:   []string{account},
+		Thresholds: MarginThresholds{MinAvailableToOperate: 1_000_000, MaxMarginUsage: 0.75},
+	})
+	ctx := context.Background()
+	drain := func() map[MarginAlert]bool {
+		got := map[MarginAlert]bool{}
+		for len(mon.Events()) > 0 {
+			ev := <-mon.Events()
+			got[ev.Alert] = ev.Triggered
+		}
+		return got
+	}
+
+	s, err := mon.Poll(ctx, account)
+	if err != nil || s.AvailableToOperate != 500_000 || s.MarginUsage != 0.8 || len(s.Alerts) != 2 {
+		t.Fatalf("first poll: %+v (%v)", s, err)
+	}
+	if ev := drain(); len(ev) != 2 || !ev[AlertAvailableToOperate] || !ev[AlertMarginUsage] {
+		t.Fatalf("first events: %v", ev)
+	}
+
+	if _, err := mon.Poll(ctx, account); err != nil {
+		t.Fatalf("second poll: %v", err)
+	}
+	ev := drain()
+	if len(ev) != 3 || !ev[AlertUncoveredMargin] || ev[AlertAvailableToOperate] || ev[AlertMarginUsage] {
+		t.Fatalf("second events: %v", ev)
+	}
+
+	// Un error, o una respuesta con status ERROR, conserva el último estado y no emite eventos
+	for i := 0; i < 2; i++ {
+		if _, err := mon.Poll(ctx, account); err == nil {
+			t.Fatalf("poll %d: want error", i)
+		}
+		s, ok := mon.Snapshot(account)
+		if !ok || s.Err == nil || !s.Breached() || s.UncoveredMargin != 10 || len(mon.Events()) != 0 {
+			t.Fatalf("snapshot after error %d: %+v", i, s)
+		}
+	}
+}
@@ -1,22 +1,145 @@
 package model
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
 
 // Account representa una cuenta de usuario como la devuelta por /rest/accounts.
-// El esquema puede incluir más campos; conservadoramente mapeamos los conocidos.
-// Al menos 'name' está presente según el uso de la colección Postman.
+//
+// Según el broker la respuesta trae más o menos campos: los conocidos se mapean y el
+// resto queda en Extra como un objeto JSON. id y brokerId pueden venir como número o
+// texto; status, como booleano o texto.
 type Account struct {
-	Name string `json:"name"`
-	// Captura campos adicionales sin fallar en decodificación estricta
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	BrokerID    string `json:"brokerId,omitempty"`
+	Status      string `json:"status,omitempty"` // "true"/"false" o el estado informado por el broker
+	Type        string `json:"type,omitempty"`
+	Currency    string `json:"currency,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Campos no mapeados, como objeto JSON (nil si no hay)
 	Extra json.RawMessage `json:"-"`
 }
 
+// IsActive indica si la cuenta está habilitada. Sin status se asume activa.
+func (a Account) IsActive() bool {
+	switch strings.ToUpper(a.Status) {
+	case "", "TRUE", "ACTIVE", "ENABLED":
+		return true
+	}
+	return false
+}
+
+// UnmarshalJSON decodifica los campos conocidos y guarda el resto en Extra.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*a = Account{}
+	known := map[string]*string{
+		"id":          &a.ID,
+		"name":        &a.Name,
+		"brokerId":    &a.BrokerID,
+		"status":      &a.Status,
+		"type":        &a.Type,
+		"currency":    &a.Currency,
+		"description": &a.Description,
+	}
+	for key, dst := range known {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		delete(fields, key)
+		*dst = scalarString(raw)
+	}
+	if len(fields) > 0 {
+		extra, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		a.Extra = extra
+	}
+	return nil
+}
+
+// MarshalJSON codifica los campos conocidos junto con los de Extra en un único objeto,
+// de modo que una cuenta decodificada se vuelve a codificar sin perder campos.
+func (a Account) MarshalJSON() ([]byte, error) {
+	type plain Account
+	base, err := json.Marshal(plain(a))
+	if err != nil || len(a.Extra) == 0 {
+		return base, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(a.Extra, &fields); err != nil {
+		return nil, err
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(base, &known); err != nil {
+		return nil, err
+	}
+	for key, raw := range known {
+		fields[key] = raw
+	}
+	return json.Marshal(fields)
+}
+
+// scalarString convierte un string, número o booleano JSON a texto ("" para null).
+func scalarString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return strconv.FormatBool(b)
+	}
+	return ""
+}
+
 // AccountsResponse es el contenedor para el listado de cuentas.
 //
 //	{
-//	  "accounts": [ { "name": "..." }, ... ]
+//	  "accounts": [ { "id": 6771, "name": "REM6771", "brokerId": 284, "status": true }, ... ]
 //	}
 type AccountsResponse struct {
 	Status   string    `json:"status,omitempty"`
 	Accounts []Account `json:"accounts"`
 }
+
+// ByName busca una cuenta por nombre.
+func (r AccountsResponse) ByName(name string) (Account, bool) {
+	for _, a := range r.Accounts {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Account{}, false
+}
+
+// ByID busca una cuenta por id.
+func (r AccountsResponse) ByID(id string) (Account, bool) {
+	for _, a := range r.Accounts {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return Account{}, false
+}
+
+// Names devuelve los nombres de las cuentas en el orden de la respuesta.
+func (r AccountsResponse) Names() []string {
+	names := make([]string, 0, len(r.Accounts))
+	for _, a := range r.Accounts {
+		names = append(names, a.Name)
+	}
+	return names
+}
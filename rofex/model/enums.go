@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // Environment representa el entorno objetivo.
 type Environment string
 
@@ -44,6 +46,33 @@ const (
 	CFIOn         CFICode = "DBXXFR"
 )
 
+// ContractType identifica el tipo de contrato de un ítem de DetailedPosition.
+//
+// El campo contractType de cada ítem usa guiones bajos ("FUTURE_OPTION_CALL") y las
+// claves del reporte que los agrupan, espacios ("FUTURE OPTION CALL"); ParseContractType
+// acepta ambas formas.
+type ContractType string
+
+const (
+	ContractFuture           ContractType = "FUTURE"
+	ContractFutureOptionCall ContractType = "FUTURE_OPTION_CALL"
+	ContractFutureOptionPut  ContractType = "FUTURE_OPTION_PUT"
+	ContractOptionCall       ContractType = "OPTION_CALL"
+	ContractOptionPut        ContractType = "OPTION_PUT"
+	ContractStock            ContractType = "STOCK"
+	ContractBond             ContractType = "BOND"
+)
+
+// ParseContractType normaliza un tipo de contrato: mayúsculas y espacios como guiones bajos.
+func ParseContractType(s string) ContractType {
+	return ContractType(strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), " ", "_")))
+}
+
+// IsOption indica si el tipo de contrato es una opción (sobre futuro o contado).
+func (t ContractType) IsOption() bool {
+	return strings.HasSuffix(string(t), "_CALL") || strings.HasSuffix(string(t), "_PUT")
+}
+
 // TimeInForce identifies the active time of an order.
 //
 // Primary API (docs/primary-api.md) definitions:
@@ -84,6 +113,11 @@ const (
 // - LIMIT: Orden con precio límite
 // - MARKET: Orden a mercado
 // - MARKET_TO_LIMIT: Orden que intenta a mercado y, si hay remanente, queda como LIMIT al mejor precio
+// - STOP_LIMIT: Orden limitada que se activa al alcanzarse el precio stop (stopPx)
+// - STOP_LIMIT_MERVAL: Variante STOP_LIMIT para instrumentos de mercados externos (MERV)
+//
+// STOP no es un ordType de Primary: es un stop a mercado que el SDK emula del lado
+// del cliente (ver StopManager) y dispara como MARKET.
 type OrderType string
 
 const (
@@ -93,8 +127,38 @@ const (
 	OrderTypeMarket OrderType = "MARKET"
 	// OrderTypeMarketToLimit: Intenta a mercado y, si hay remanente, queda LIMIT al mejor precio.
 	OrderTypeMarketToLimit OrderType = "MARKET_TO_LIMIT"
+	// OrderTypeStopLimit: Orden limitada que se activa al alcanzarse stopPx.
+	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+	// OrderTypeStopLimitMerval: STOP_LIMIT para instrumentos MERV.
+	OrderTypeStopLimitMerval OrderType = "STOP_LIMIT_MERVAL"
+	// OrderTypeStop: Stop a mercado, solo emulado del lado del cliente.
+	OrderTypeStop OrderType = "STOP"
 )
 
+// IsStop indica si el tipo de orden requiere precio stop.
+func (t OrderType) IsStop() bool {
+	switch t {
+	case OrderTypeStopLimit, OrderTypeStopLimitMerval, OrderTypeStop:
+		return true
+	}
+	return false
+}
+
+// IsClientOnly indica si el tipo de orden no existe en el mercado y solo puede
+// emularse del lado del cliente (StopManager).
+func (t OrderType) IsClientOnly() bool {
+	return t == OrderTypeStop
+}
+
+// HasLimitPrice indica si el tipo de orden lleva precio límite.
+func (t OrderType) HasLimitPrice() bool {
+	switch t {
+	case OrderTypeLimit, OrderTypeStopLimit, OrderTypeStopLimitMerval:
+		return true
+	}
+	return false
+}
+
 // MDEntry identifies the market data entries for an instrument.
 //
 // Primary API (docs/primary-api.md - "Descripción de MarketData Entries"):
@@ -189,3 +253,61 @@ const (
 	// (confirmaciones, ejecuciones, cancelaciones, rechazos).
 	WSMessageOrderReport WSMessageType = "or"
 )
+
+// OrderStatus identifies the state of an order as reported by Primary API.
+//
+// Primary API (docs/primary-api.md - "Anexo - Diccionario de Campos"):
+// - PENDING_NEW: Orden enviada, pendiente de confirmación
+// - NEW: Orden aceptada por el mercado
+// - PARTIALLY_FILLED: Orden parcialmente operada
+// - FILLED: Orden totalmente operada
+// - PENDING_REPLACE / PENDING_CANCEL: Modificación o cancelación en curso
+// - PENDING_APPROVAL: Orden pendiente de aprobación
+// - CANCELLED: Orden cancelada
+// - REJECTED: Orden rechazada (el campo text indica el motivo)
+// - REPLACED: Orden reemplazada
+type OrderStatus string
+
+const (
+	// StatusPendingNew: Orden enviada, pendiente de confirmación.
+	StatusPendingNew OrderStatus = "PENDING_NEW"
+	// StatusNew: Orden aceptada por el mercado.
+	StatusNew OrderStatus = "NEW"
+	// StatusPartiallyFilled: Orden parcialmente operada.
+	StatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	// StatusFilled: Orden totalmente operada.
+	StatusFilled OrderStatus = "FILLED"
+	// StatusPendingReplace: Reemplazo en curso.
+	StatusPendingReplace OrderStatus = "PENDING_REPLACE"
+	// StatusPendingCancel: Cancelación en curso.
+	StatusPendingCancel OrderStatus = "PENDING_CANCEL"
+	// StatusPendingApproval: Orden pendiente de aprobación.
+	StatusPendingApproval OrderStatus = "PENDING_APPROVAL"
+	// StatusCancelled: Orden cancelada.
+	StatusCancelled OrderStatus = "CANCELLED"
+	// StatusRejected: Orden rechazada.
+	StatusRejected OrderStatus = "REJECTED"
+	// StatusReplaced: Orden reemplazada.
+	StatusReplaced OrderStatus = "REPLACED"
+	// StatusTriggered: Stop emulado disparado (solo del lado del cliente, ver StopManager).
+	StatusTriggered OrderStatus = "TRIGGERED"
+)
+
+// IsActive indica si la orden puede todavía operar en el mercado.
+func (s OrderStatus) IsActive() bool {
+	switch s {
+	case StatusPendingNew, StatusNew, StatusPartiallyFilled,
+		StatusPendingReplace, StatusPendingCancel, StatusPendingApproval:
+		return true
+	}
+	return false
+}
+
+// IsTerminal indica si la orden alcanzó un estado final.
+func (s OrderStatus) IsTerminal() bool {
+	switch s {
+	case StatusFilled, StatusCancelled, StatusRejected, StatusReplaced, StatusTriggered:
+		return true
+	}
+	return false
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"encoding/json"
+	"math"
+	"strings"
 )
 
 // Segment describe el segmento del instrumento (marketSegmentId, marketId).
@@ -77,3 +79,80 @@ func (i *Instrument) UnmarshalJSON(b []byte) error {
 	*i = Instrument(aux)
 	return nil
 }
+
+// SupportsOrderType indica si el instrumento acepta el tipo de orden según orderTypes.
+// La API informa los tipos con espacios ("STOP LIMIT", "MARKET TO LIMIT"); la
+// comparación los normaliza a la forma de ordType ("STOP_LIMIT").
+func (i Instrument) SupportsOrderType(t OrderType) bool {
+	for _, ot := range i.OrderTypes {
+		if strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(ot)), " ", "_") == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// TickAt devuelve el incremento mínimo de precio vigente para price.
+//
+// Si el instrumento informa tickPriceRanges (ticks dinámicos) se usa el rango que
+// contiene al precio; si no, minPriceIncrement. Devuelve 0 si no hay información.
+func (i Instrument) TickAt(price float64) float64 {
+	for _, r := range i.TickPriceRanges {
+		if r.LowerLimit != nil && price < *r.LowerLimit {
+			continue
+		}
+		if r.UpperLimit != nil && price > *r.UpperLimit {
+			continue
+		}
+		if r.Tick > 0 {
+			return r.Tick
+		}
+	}
+	if i.MinPriceIncrement != nil {
+		return *i.MinPriceIncrement
+	}
+	return 0
+}
+
+// RoundPrice ajusta price a la grilla de ticks del lado conservador: hacia abajo
+// para compras y hacia arriba para ventas, de modo de no superar un precio límite.
+func (i Instrument) RoundPrice(price float64, side Side) float64 {
+	tick := i.TickAt(price)
+	if tick <= 0 {
+		return price
+	}
+	// Tolerancia para errores de punto flotante (ej. 179.85/0.05)
+	steps := price / tick
+	var n float64
+	if side == Sell {
+		n = math.Ceil(steps - 1e-9)
+	} else {
+		n = math.Floor(steps + 1e-9)
+	}
+	return roundDecimals(n*tick, i.InstrumentPricePrecision)
+}
+
+// LotSize devuelve el lote de negociación: roundLot, o minTradeVol si roundLot no está (mínimo 1).
+func (i Instrument) LotSize() int64 {
+	switch {
+	case i.RoundLot != nil && *i.RoundLot >= 1:
+		return int64(*i.RoundLot)
+	case i.MinTradeVol != nil && *i.MinTradeVol >= 1:
+		return int64(*i.MinTradeVol)
+	}
+	return 1
+}
+
+// RoundQty ajusta qty hacia abajo al múltiplo del lote de negociación (ver LotSize).
+func (i Instrument) RoundQty(qty int64) int64 {
+	return qty - qty%i.LotSize()
+}
+
+func roundDecimals(v float64, precision *int) float64 {
+	p := 8
+	if precision != nil {
+		p = *precision
+	}
+	f := math.Pow(10, float64(p))
+	return math.Round(v*f) / f
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 // SendOrderResponse coincide con APIDoc para /rest/order/newSingleOrder
 // { "status":"OK", "order": { "clientId": "...", "proprietary": "api" } }
 type SendOrderResponse struct {
@@ -53,3 +55,60 @@ type Order struct {
 	LastQty      *int64      `json:"lastQty,omitempty"`
 	TransactTime string      `json:"transactTime,omitempty"`
 }
+
+// IsReplaced indica si el reporte es el cierre de una orden reemplazada: status REPLACED,
+// o CANCELLED con text "Reemplazada" como informa Primary luego de ReplaceOrder.
+func (d OrderDetails) IsReplaced() bool {
+	if OrderStatus(d.Status) == StatusReplaced {
+		return true
+	}
+	return OrderStatus(d.Status) == StatusCancelled && d.Text != nil && strings.EqualFold(strings.TrimSpace(*d.Text), "Reemplazada")
+}
+
+// AsReport convierte el estado de una orden consultado por REST al formato de un
+// Execution Report recibido por WebSocket, para procesar ambos con el mismo código.
+func (o Order) AsReport() OrderDetails {
+	d := OrderDetails{
+		ClOrdID:      o.ClOrdID,
+		Proprietary:  o.Proprietary,
+		InstrumentID: o.InstrumentID,
+		Price:        o.Price,
+		OrdType:      string(o.OrdType),
+		Side:         string(o.Side),
+		TimeInForce:  string(o.TimeInForce),
+		TransactTime: o.TransactTime,
+		AvgPx:        o.AvgPx,
+		LastPx:       o.LastPx,
+		Status:       o.Status,
+	}
+	if o.OrderID != "" {
+		id := o.OrderID
+		d.OrderID = &id
+	}
+	if o.ExecID != "" {
+		id := o.ExecID
+		d.ExecID = &id
+	}
+	if o.AccountID != nil {
+		d.AccountID = &AccountReference{ID: o.AccountID.ID}
+	}
+	if o.Text != "" {
+		text := o.Text
+		d.Text = &text
+	}
+	if o.OrderQty != nil {
+		d.OrderQty = int(*o.OrderQty)
+	}
+	d.LastQty = intPtr(o.LastQty)
+	d.CumQty = intPtr(o.CumQty)
+	d.LeavesQty = intPtr(o.LeavesQty)
+	return d
+}
+
+func intPtr(v *int64) *int {
+	if v == nil {
+		return nil
+	}
+	n := int(*v)
+	return &n
+}
@@ -43,14 +43,14 @@ type DetailedDailyDiff struct {
 
 // DetailedPositionItem representa el detalle por símbolo (size, precios, etc.).
 type DetailedPositionItem struct {
-	SymbolReference       string  `json:"symbolReference"`
-	ContractType          string  `json:"contractType"`
-	PriceConversionFactor float64 `json:"priceConversionFactor"`
-	ContractSize          float64 `json:"contractSize"`
-	MarketPrice           float64 `json:"marketPrice"`
-	Currency              string  `json:"currency"`
-	ExchangeRate          float64 `json:"exchangeRate"`
-	ContractMultiplier    float64 `json:"contractMultiplier"`
+	SymbolReference       string       `json:"symbolReference"`
+	ContractType          ContractType `json:"contractType"`
+	PriceConversionFactor float64      `json:"priceConversionFactor"`
+	ContractSize          float64      `json:"contractSize"`
+	MarketPrice           float64      `json:"marketPrice"`
+	Currency              string       `json:"currency"`
+	ExchangeRate          float64      `json:"exchangeRate"`
+	ContractMultiplier    float64      `json:"contractMultiplier"`
 
 	TotalInitialSize float64 `json:"totalInitialSize"`
 	BuyInitialSize   float64 `json:"buyInitialSize"`
@@ -80,6 +80,10 @@ type DetailedInstrument struct {
 }
 
 // DetailedPosition representa el payload de detailedPosition.
+//
+// Report agrupa por tipo de contrato ("FUTURE OPTION CALL") y luego por símbolo; Items,
+// Instrument, ByMarketValue, ByContractType, ByCurrency, TotalsByCurrency y Rows lo
+// consultan sin recorrer los mapas.
 type DetailedPosition struct {
 	Account             string                                   `json:"account"`
 	TotalDailyDiffPlain float64                                  `json:"totalDailyDiffPlain"`
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"math"
+	"sort"
+)
+
+// Consultas sobre DetailedPosition.Report sin recorrer los mapas anidados.
+
+// Type devuelve el tipo de contrato del ítem normalizado.
+func (i DetailedPositionItem) Type() ContractType { return ParseContractType(string(i.ContractType)) }
+
+// Multiplier devuelve ContractMultiplier * PriceConversionFactor; los valores en cero
+// cuentan como 1.
+func (i DetailedPositionItem) Multiplier() float64 {
+	mult, pcf := i.ContractMultiplier, i.PriceConversionFactor
+	if mult == 0 {
+		mult = 1
+	}
+	if pcf == 0 {
+		pcf = 1
+	}
+	return mult * pcf
+}
+
+// MarketValue devuelve el valor de mercado de la posición actual en la moneda del ítem:
+// TotalCurrentSize * MarketPrice * Multiplier.
+func (i DetailedPositionItem) MarketValue() float64 {
+	return i.TotalCurrentSize * i.MarketPrice * i.Multiplier()
+}
+
+// Rate devuelve ExchangeRate o 1 si no viene informado (ítems en pesos).
+func (i DetailedPositionItem) Rate() float64 {
+	if i.ExchangeRate == 0 {
+		return 1
+	}
+	return i.ExchangeRate
+}
+
+// Items devuelve todos los ítems del reporte ordenados por símbolo. Los ítems sin
+// contractType toman el del grupo que los contiene.
+func (d DetailedPosition) Items() []DetailedPositionItem {
+	var out []DetailedPositionItem
+	for group, byInst := range d.Report {
+		for _, inst := range byInst {
+			for _, item := range inst.DetailedPositions {
+				if item.ContractType == "" {
+					item.ContractType = ParseContractType(group)
+				}
+				out = append(out, item)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].SymbolReference < out[j].SymbolReference })
+	return out
+}
+
+// Instrument busca un instrumento por símbolo y devuelve su tipo de contrato.
+func (d DetailedPosition) Instrument(symbol string) (DetailedInstrument, ContractType, bool) {
+	for group, byInst := range d.Report {
+		if inst, ok := byInst[symbol]; ok {
+			return inst, ParseContractType(group), true
+		}
+	}
+	return DetailedInstrument{}, "", false
+}
+
+// ByMarketValue devuelve los ítems ordenados por valor de mercado absoluto en pesos
+// (MarketValue * Rate), de mayor a menor.
+func (d DetailedPosition) ByMarketValue() []DetailedPositionItem {
+	items := d.Items()
+	sort.SliceStable(items, func(i, j int) bool {
+		return math.Abs(items[i].MarketValue()*items[i].Rate()) > math.Abs(items[j].MarketValue()*items[j].Rate())
+	})
+	return items
+}
+
+// ByContractType devuelve los ítems de los tipos de contrato indicados.
+func (d DetailedPosition) ByContractType(types ...ContractType) []DetailedPositionItem {
+	return d.filter(func(i DetailedPositionItem) bool {
+		for _, t := range types {
+			if i.Type() == ParseContractType(string(t)) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ByCurrency devuelve los ítems nominados en la moneda indicada (ej. "USD G").
+func (d DetailedPosition) ByCurrency(currency string) []DetailedPositionItem {
+	return d.filter(func(i DetailedPositionItem) bool { return i.Currency == currency })
+}
+
+func (d DetailedPosition) filter(keep func(DetailedPositionItem) bool) []DetailedPositionItem {
+	var out []DetailedPositionItem
+	for _, item := range d.Items() {
+		if keep(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// CurrencyTotal suma los ítems de una moneda, en esa moneda y convertidos a pesos con
+// ExchangeRate.
+type CurrencyTotal struct {
+	Currency         string
+	MarketValue      float64
+	MarketValuePlain float64
+	DailyDiff        float64
+	DailyDiffPlain   float64
+}
+
+// TotalsByCurrency devuelve los totales por moneda, ordenados por moneda.
+func (d DetailedPosition) TotalsByCurrency() []CurrencyTotal {
+	byCurrency := make(map[string]*CurrencyTotal)
+	for _, item := range d.Items() {
+		t, ok := byCurrency[item.Currency]
+		if !ok {
+			t = &CurrencyTotal{Currency: item.Currency}
+			byCurrency[item.Currency] = t
+		}
+		t.MarketValue += item.MarketValue()
+		t.MarketValuePlain += item.MarketValue() * item.Rate()
+		t.DailyDiff += item.DetailedDailyDiff.TotalDailyDiff
+		t.DailyDiffPlain += item.DetailedDailyDiff.TotalDailyDiffPlain
+	}
+	out := make([]CurrencyTotal, 0, len(byCurrency))
+	for _, t := range byCurrency {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Currency < out[j].Currency })
+	return out
+}
+
+// DetailedPositionRow es un ítem del reporte aplanado para exportar.
+type DetailedPositionRow struct {
+	Account          string       `json:"account"`
+	ContractType     ContractType `json:"contractType"`
+	Symbol           string       `json:"symbol"`
+	Currency         string       `json:"currency"`
+	ExchangeRate     float64      `json:"exchangeRate"`
+	InitialSize      float64      `json:"initialSize"`
+	FilledSize       float64      `json:"filledSize"`
+	CurrentSize      float64      `json:"currentSize"`
+	MarketPrice      float64      `json:"marketPrice"`
+	Multiplier       float64      `json:"multiplier"`
+	MarketValue      float64      `json:"marketValue"`
+	MarketValuePlain float64      `json:"marketValuePlain"`
+	DailyDiff        float64      `json:"dailyDiff"`
+	DailyDiffPlain   float64      `json:"dailyDiffPlain"`
+}
+
+// Rows aplana el reporte en una fila por ítem, ordenadas por símbolo.
+func (d DetailedPosition) Rows() []DetailedPositionRow {
+	items := d.Items()
+	rows := make([]DetailedPositionRow, 0, len(items))
+	for _, item := range items {
+		rows = append(rows, DetailedPositionRow{
+			Account:          d.Account,
+			ContractType:     item.Type(),
+			Symbol:           item.SymbolReference,
+			Currency:         item.Currency,
+			ExchangeRate:     item.Rate(),
+			InitialSize:      item.TotalInitialSize,
+			FilledSize:       item.TotalFilledSize,
+			CurrentSize:      item.TotalCurrentSize,
+			MarketPrice:      item.MarketPrice,
+			Multiplier:       item.Multiplier(),
+			MarketValue:      item.MarketValue(),
+			MarketValuePlain: item.MarketValue() * item.Rate(),
+			DailyDiff:        item.DetailedDailyDiff.TotalDailyDiff,
+			DailyDiffPlain:   item.DetailedDailyDiff.TotalDailyDiffPlain,
+		})
+	}
+	return rows
+}
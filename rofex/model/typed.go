@@ -8,8 +8,9 @@ type InstrumentsResponse struct {
 }
 
 type InstrumentDetailResponse struct {
-	Status     string     `json:"status,omitempty"`
-	Instrument Instrument `json:"instrument"`
+	Status      string     `json:"status,omitempty"`
+	Description string     `json:"description,omitempty"` // Motivo cuando status es ERROR
+	Instrument  Instrument `json:"instrument"`
 }
 
 type MarketDataSnapshotResponse struct {
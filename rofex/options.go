@@ -41,6 +41,31 @@ func WithTimeout(d time.Duration) Option {
 func WithUserAgent(ua string) Option  { return func(c *Client) { c.userAgent = ua } }
 func WithProprietary(p string) Option { return func(c *Client) { c.proprietary = p } }
 
+// WithLineageRedirect hace que OrderStatus, CancelOrder, CancelOrderWS y ReplaceOrder
+// llamados con un clOrdID ya reemplazado operen sobre el clOrdID vigente de la cadena
+// (ver Client.Lineage). Por defecto false: se usa el id indicado.
+func WithLineageRedirect(enabled bool) Option { return func(c *Client) { c.redirectStale = enabled } }
+
+// WithAccountsTTL establece la vigencia del listado de cuentas que Accounts deja en
+// cache para la validación local (por defecto 1h).
+func WithAccountsTTL(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.accounts.ttl = d
+		}
+	}
+}
+
+// WithWSOrderTimeout establece cuánto espera SendOrderWS el primer Execution Report
+// de la orden (por defecto 10s).
+func WithWSOrderTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		if d > 0 {
+			c.wsOrderTimeout = d
+		}
+	}
+}
+
 // WithWSBuffer sets the buffered channel size for streaming event channels (default 128).
 func WithWSBuffer(n int) Option {
 	return func(c *Client) {
@@ -65,3 +90,28 @@ func WithSandbox() Option { return func(c *Client) { c.applyEnvironment(model.En
 
 // WithLive is a convenience option to explicitly select LIVE (production).
 func WithLive() Option { return func(c *Client) { c.applyEnvironment(model.EnvironmentLive) } }
+
+// WithRiskManager activa los controles pre-trade sobre todos los métodos de ingreso de órdenes.
+func WithRiskManager(r *RiskManager) Option { return func(c *Client) { c.risk = r } }
+
+// WithHolidays registra feriados del mercado: las órdenes GTD no pueden vencer en esos
+// días (ver IsTradingDay). Se toma la fecha de cada día en MarketLocation.
+func WithHolidays(days ...time.Time) Option {
+	return func(c *Client) {
+		if c.holidays == nil {
+			c.holidays = make(map[string]struct{}, len(days))
+		}
+		for _, d := range days {
+			c.holidays[formatDate(d)] = struct{}{}
+		}
+	}
+}
+
+// WithPaperTrading activa el modo de simulación: SendOrder, ReplaceOrder, CancelOrder,
+// sus variantes WebSocket, las consultas de estado de órdenes, SubscribeOrderReport y
+// AccountPosition son atendidos por un simulador local (ver PaperBroker) que calza las
+// órdenes contra el Market Data en vivo. Market Data y datos de referencia siguen
+// consultándose a la API.
+func WithPaperTrading() Option {
+	return func(c *Client) { c.paper = newPaperBroker(c) }
+}
@@ -0,0 +1,251 @@
+// Package orderbook mantiene libros de órdenes L2 locales a partir de la Market Data
+// de rofex.Client.
+//
+// Cada mensaje de Market Data trae hasta 5 niveles por lado (depth) y puede traer solo
+// BI u OF: el lado ausente conserva los niveles anteriores y un lado presente pero
+// vacío deja el libro sin niveles de ese lado.
+//
+//	books := orderbook.NewBooks()
+//	md, _ := client.SubscribeMarketData(ctx, []string{"DLR/MAR26"},
+//		[]model.MDEntry{model.MDBids, model.MDOffers}, 5, model.MarketROFEX)
+//	go books.Run(ctx, md.Events)
+//
+//	updates, cancel := books.Subscribe(16)
+//	defer cancel()
+//	for u := range updates {
+//		b, _ := books.Get(u.InstrumentID)
+//		mid, _ := b.Mid()
+//		px, ok := b.VWAP(model.Buy, 50)
+//		fmt.Println(u.InstrumentID.Symbol, mid, px, ok, b.Imbalance(3))
+//	}
+package orderbook
+
+import (
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Snapshot es una copia del estado de un libro.
+type Snapshot struct {
+	InstrumentID model.InstrumentID
+	Bids         []model.BookLevel // De mejor a peor precio (descendente)
+	Offers       []model.BookLevel // De mejor a peor precio (ascendente)
+	Seq          uint64            // Cantidad de actualizaciones aplicadas que cambiaron el libro
+	UpdatedAt    time.Time
+}
+
+// DepthLevel es un nivel con la cantidad acumulada desde el mejor precio.
+type DepthLevel struct {
+	Price      float64
+	Size       float64
+	Cumulative float64
+}
+
+// Book es el libro L2 de un instrumento. Es seguro para uso concurrente.
+type Book struct {
+	id model.InstrumentID
+
+	mu      sync.RWMutex
+	bids    []model.BookLevel
+	offers  []model.BookLevel
+	seq     uint64
+	updated time.Time
+}
+
+// NewBook crea un libro vacío.
+func NewBook(id model.InstrumentID) *Book {
+	return &Book{id: id}
+}
+
+// InstrumentID devuelve el instrumento del libro.
+func (b *Book) InstrumentID() model.InstrumentID { return b.id }
+
+// Apply aplica una actualización. Los lados nil no se modifican y las actualizaciones
+// anteriores a la última aplicada (llegadas fuera de orden) se ignoran. Devuelve qué
+// lados cambiaron.
+func (b *Book) Apply(md model.MarketData, at time.Time) (bidsChanged, offersChanged bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !at.IsZero() && at.Before(b.updated) {
+		return false, false
+	}
+	if md.Bids != nil && !sameLevels(b.bids, md.Bids) {
+		b.bids = append([]model.BookLevel(nil), md.Bids...)
+		bidsChanged = true
+	}
+	if md.Offers != nil && !sameLevels(b.offers, md.Offers) {
+		b.offers = append([]model.BookLevel(nil), md.Offers...)
+		offersChanged = true
+	}
+	if bidsChanged || offersChanged {
+		b.seq++
+		b.updated = at
+	}
+	return bidsChanged, offersChanged
+}
+
+// Snapshot devuelve una copia del libro.
+func (b *Book) Snapshot() Snapshot {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return Snapshot{
+		InstrumentID: b.id,
+		Bids:         append([]model.BookLevel(nil), b.bids...),
+		Offers:       append([]model.BookLevel(nil), b.offers...),
+		Seq:          b.seq,
+		UpdatedAt:    b.updated,
+	}
+}
+
+// BestBid devuelve el mejor nivel de compra.
+func (b *Book) BestBid() (model.BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.bids)
+}
+
+// BestAsk devuelve el mejor nivel de venta.
+func (b *Book) BestAsk() (model.BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return top(b.offers)
+}
+
+// Spread devuelve mejor venta - mejor compra. ok es false si falta algún lado.
+func (b *Book) Spread() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// Mid devuelve el punto medio entre la mejor compra y la mejor venta.
+func (b *Book) Mid() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok {
+		return 0, false
+	}
+	return (bid.Price + ask.Price) / 2, true
+}
+
+// Microprice devuelve el precio medio ponderado por la cantidad del lado contrario:
+// (bid * askSize + ask * bidSize) / (bidSize + askSize).
+func (b *Book) Microprice() (float64, bool) {
+	bid, ask, ok := b.touch()
+	if !ok || bid.Size+ask.Size <= 0 {
+		return 0, false
+	}
+	return (bid.Price*ask.Size + ask.Price*bid.Size) / (bid.Size + ask.Size), true
+}
+
+// Imbalance devuelve (compras - ventas) / (compras + ventas) sumando las cantidades de
+// los primeros levels niveles de cada lado (todos si levels <= 0). Va de -1 (solo
+// ventas) a 1 (solo compras); 0 con el libro vacío.
+func (b *Book) Imbalance(levels int) float64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	bid, ask := sumSize(b.bids, levels), sumSize(b.offers, levels)
+	if bid+ask == 0 {
+		return 0
+	}
+	return (bid - ask) / (bid + ask)
+}
+
+// Depth devuelve los niveles de un lado con la cantidad acumulada. side es el lado del
+// libro: model.Buy para las compras (BI), model.Sell para las ventas (OF).
+func (b *Book) Depth(side model.Side) []DepthLevel {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	levels := b.levels(side)
+	out := make([]DepthLevel, 0, len(levels))
+	var cum float64
+	for _, l := range levels {
+		cum += l.Size
+		out = append(out, DepthLevel{Price: l.Price, Size: l.Size, Cumulative: cum})
+	}
+	return out
+}
+
+// DepthTo devuelve el peor precio que hay que recorrer en un lado del libro para
+// acumular size. ok es false si la profundidad visible no alcanza.
+func (b *Book) DepthTo(side model.Side, size float64) (float64, bool) {
+	for _, l := range b.Depth(side) {
+		if l.Cumulative >= size {
+			return l.Price, true
+		}
+	}
+	return 0, false
+}
+
+// VWAP devuelve el precio promedio de ejecutar qty de inmediato contra el libro: una
+// compra (model.Buy) recorre las ventas y una venta recorre las compras. ok es false si
+// la profundidad visible no alcanza.
+func (b *Book) VWAP(side model.Side, qty float64) (float64, bool) {
+	if qty <= 0 {
+		return 0, false
+	}
+	book := model.Sell
+	if side == model.Sell {
+		book = model.Buy
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var filled, notional float64
+	for _, l := range b.levels(book) {
+		take := min(l.Size, qty-filled)
+		filled += take
+		notional += take * l.Price
+		if filled >= qty {
+			return notional / filled, true
+		}
+	}
+	return 0, false
+}
+
+func (b *Book) touch() (bid, ask model.BookLevel, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	bid, okBid := top(b.bids)
+	ask, okAsk := top(b.offers)
+	return bid, ask, okBid && okAsk
+}
+
+func (b *Book) levels(side model.Side) []model.BookLevel {
+	if side == model.Sell {
+		return b.offers
+	}
+	return b.bids
+}
+
+func top(levels []model.BookLevel) (model.BookLevel, bool) {
+	if len(levels) == 0 {
+		return model.BookLevel{}, false
+	}
+	return levels[0], true
+}
+
+func sumSize(levels []model.BookLevel, n int) float64 {
+	if n > 0 && n < len(levels) {
+		levels = levels[:n]
+	}
+	var total float64
+	for _, l := range levels {
+		total += l.Size
+	}
+	return total
+}
+
+func sameLevels(a, b []model.BookLevel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
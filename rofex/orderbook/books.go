@@ -0,0 +1,168 @@
+package orderbook
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Update notifica que el libro de un instrumento cambió.
+type Update struct {
+	InstrumentID  model.InstrumentID
+	BidsChanged   bool
+	OffersChanged bool
+	Snapshot      Snapshot
+}
+
+// Books mantiene un Book por instrumento y notifica sus cambios. Es seguro para uso
+// concurrente.
+type Books struct {
+	mu    sync.RWMutex
+	books map[model.InstrumentID]*Book
+	subs  map[chan Update]struct{}
+}
+
+// NewBooks crea un conjunto de libros vacío.
+func NewBooks() *Books {
+	return &Books{books: make(map[model.InstrumentID]*Book), subs: make(map[chan Update]struct{})}
+}
+
+// Get devuelve el libro del instrumento, si ya recibió datos.
+func (bs *Books) Get(id model.InstrumentID) (*Book, bool) {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	b, ok := bs.books[normalize(id)]
+	return b, ok
+}
+
+// Book devuelve el libro del instrumento, creándolo vacío si no existe.
+func (bs *Books) Book(id model.InstrumentID) *Book {
+	id = normalize(id)
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.books[id]
+	if !ok {
+		b = NewBook(id)
+		bs.books[id] = b
+	}
+	return b
+}
+
+// Instruments devuelve los instrumentos con libro.
+func (bs *Books) Instruments() []model.InstrumentID {
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	out := make([]model.InstrumentID, 0, len(bs.books))
+	for id := range bs.books {
+		out = append(out, id)
+	}
+	return out
+}
+
+// OnMarketData aplica un evento de SubscribeMarketData.
+func (bs *Books) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	at := time.Now()
+	if t := ev.HumanTime(); t != nil {
+		at = *t
+	}
+	bs.apply(ev.InstrumentID, ev.MarketData, at)
+}
+
+// Run aplica los eventos hasta que ctx se cancele o el canal se cierre.
+func (bs *Books) Run(ctx context.Context, events <-chan *model.MarketDataEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			bs.OnMarketData(ev)
+		}
+	}
+}
+
+// Load inicializa el libro de un instrumento con un snapshot REST (MarketDataSnapshot)
+// de BI y OF con la profundidad indicada. El snapshot no trae marca de tiempo, por lo
+// que se aplica sin ella: la siguiente actualización del websocket se aplica siempre.
+// Una respuesta con status distinto de OK devuelve error y deja el libro intacto.
+func (bs *Books) Load(ctx context.Context, c *rofex.Client, symbol string, market model.Market, depth int) (*Book, error) {
+	if market == "" {
+		market = model.MarketROFEX
+	}
+	res, err := c.MarketDataSnapshot(ctx, rofex.MDRequest{
+		Symbol:  symbol,
+		Market:  market,
+		Entries: []model.MDEntry{model.MDBids, model.MDOffers},
+		Depth:   depth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.Status != "" && !strings.EqualFold(res.Status, "OK") {
+		return nil, fmt.Errorf("orderbook: snapshot %s: status %s", symbol, res.Status)
+	}
+	md := res.MarketData
+	// El snapshot describe el libro completo: un lado ausente es un lado vacío
+	if md.Bids == nil {
+		md.Bids = []model.BookLevel{}
+	}
+	if md.Offers == nil {
+		md.Offers = []model.BookLevel{}
+	}
+	id := model.InstrumentID{Symbol: symbol, MarketID: string(market)}
+	bs.apply(id, md, time.Time{})
+	return bs.Book(id), nil
+}
+
+// Subscribe devuelve un canal con los cambios de todos los libros y una función para
+// cancelar la suscripción. Si el canal está lleno la notificación se descarta: el
+// estado actual siempre está disponible en el Book.
+func (bs *Books) Subscribe(buffer int) (<-chan Update, func()) {
+	ch := make(chan Update, buffer)
+	bs.mu.Lock()
+	bs.subs[ch] = struct{}{}
+	bs.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			bs.mu.Lock()
+			delete(bs.subs, ch)
+			bs.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (bs *Books) apply(id model.InstrumentID, md model.MarketData, at time.Time) {
+	b := bs.Book(id)
+	bidsChanged, offersChanged := b.Apply(md, at)
+	if !bidsChanged && !offersChanged {
+		return
+	}
+	u := Update{InstrumentID: b.InstrumentID(), BidsChanged: bidsChanged, OffersChanged: offersChanged, Snapshot: b.Snapshot()}
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+	for ch := range bs.subs {
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+func normalize(id model.InstrumentID) model.InstrumentID {
+	if id.MarketID == "" {
+		id.MarketID = string(model.MarketROFEX)
+	}
+	return id
+}
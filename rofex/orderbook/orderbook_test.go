@@ -0,0 +1,143 @@
+package orderbook
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex"
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestBooks_PartialUpdatesAndAnalytics(t *testing.T) {
+	// Constantes
+	id := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	lv := func(px, size float64) model.BookLevel { return model.BookLevel{Price: px, Size: size} }
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	books := NewBooks()
+	updates, cancel := books.Subscribe(8)
+	defer cancel()
+
+	books.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+		Bids:   []model.BookLevel{lv(1000, 10), lv(999.5, 20), lv(999, 30)},
+		Offers: []model.BookLevel{lv(1001, 30), lv(1001.5, 10)},
+	}})
+	// Solo OF: las compras se conservan
+	books.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+		Offers: []model.BookLevel{lv(1001, 10), lv(1002, 20)},
+	}})
+	// Mismo libro: no notifica
+	books.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+		Offers: []model.BookLevel{lv(1001, 10), lv(1002, 20)},
+	}})
+
+	if len(updates) != 2 {
+		t.Fatalf("updates: %d", len(updates))
+	}
+	<-updates
+	if u := <-updates; u.BidsChanged || !u.OffersChanged || u.Snapshot.Seq != 2 || len(u.Snapshot.Bids) != 3 {
+		t.Fatalf("partial update: %+v", u)
+	}
+
+	b, ok := books.Get(model.InstrumentID{Symbol: "DLR/MAR26"})
+	if !ok {
+		t.Fatalf("book not found")
+	}
+	if spread, _ := b.Spread(); spread != 1 {
+		t.Fatalf("spread: %v", spread)
+	}
+	if mid, _ := b.Mid(); mid != 1000.5 {
+		t.Fatalf("mid: %v", mid)
+	}
+	if micro, _ := b.Microprice(); micro != 1000.5 {
+		t.Fatalf("microprice: %v", micro)
+	}
+	if imb := b.Imbalance(2); !near(imb, 0) {
+		t.Fatalf("imbalance(2): %v", imb)
+	}
+	if imb := b.Imbalance(0); !near(imb, (60.0-30)/90) {
+		t.Fatalf("imbalance: %v", imb)
+	}
+	if px, ok := b.DepthTo(model.Buy, 25); !ok || px != 999.5 {
+		t.Fatalf("depth to 25: %v %v", px, ok)
+	}
+	if d := b.Depth(model.Sell); len(d) != 2 || d[1].Cumulative != 30 {
+		t.Fatalf("depth: %+v", d)
+	}
+	if px, ok := b.VWAP(model.Buy, 20); !ok || px != 1001.5 {
+		t.Fatalf("vwap buy: %v %v", px, ok)
+	}
+	if px, ok := b.VWAP(model.Sell, 30); !ok || !near(px, (1000*10+999.5*20)/30.0) {
+		t.Fatalf("vwap sell: %v %v", px, ok)
+	}
+	if _, ok := b.VWAP(model.Buy, 31); ok {
+		t.Fatalf("vwap beyond visible depth")
+	}
+
+	// Actualización anterior a la última aplicada: se ignora
+	at := b.Snapshot().UpdatedAt
+	if bc, oc := b.Apply(model.MarketData{Bids: []model.BookLevel{lv(1, 1)}}, at.Add(-time.Second)); bc || oc {
+		t.Fatalf("stale update applied")
+	}
+	if bid, _ := b.BestBid(); bid.Price != 1000 {
+		t.Fatalf("best bid after stale update: %+v", bid)
+	}
+
+	// Lado vacío explícito
+	books.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{Bids: []model.BookLevel{}}})
+	if _, ok := b.Mid(); ok {
+		t.Fatalf("mid with empty bids")
+	}
+}
+
+func TestBooks_Load(t *testing.T) {
+	status := "OK"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/rest/marketdata/get" || q.Get("entries") != "BI,OF" || q.Get("depth") != "5" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": status, "marketData": map[string]any{
+			"BI": []any{map[string]any{"price": 99, "size": 3}},
+		}})
+	}))
+	defer ts.Close()
+	c, _ := rofex.NewClient(rofex.WithBaseURL(ts.URL + "/"))
+
+	books := NewBooks()
+	// Un ask previo se borra: el snapshot REST describe el libro completo
+	books.Book(model.InstrumentID{Symbol: "GGAL"}).Apply(model.MarketData{Offers: []model.BookLevel{{Price: 101, Size: 1}}}, time.Now())
+	b, err := books.Load(context.Background(), c, "GGAL", "", 5)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if bid, ok := b.BestBid(); !ok || bid.Price != 99 || bid.Size != 3 {
+		t.Fatalf("best bid: %+v", bid)
+	}
+	if _, ok := b.BestAsk(); ok {
+		t.Fatalf("stale offers kept after snapshot")
+	}
+
+	// Una actualización del websocket con timestamp del exchange (anterior al reloj
+	// local) se aplica sobre el snapshot
+	ts0 := time.Now().Add(-time.Second).UnixMilli()
+	books.OnMarketData(&model.MarketDataEvent{InstrumentID: b.InstrumentID(), Timestamp: &ts0,
+		MarketData: model.MarketData{Bids: []model.BookLevel{{Price: 100, Size: 2}}}})
+	if bid, ok := b.BestBid(); !ok || bid.Price != 100 {
+		t.Fatalf("ws update after Load ignored: %+v", bid)
+	}
+
+	// Un snapshot con status ERROR no borra el libro
+	status = "ERROR"
+	if _, err := books.Load(context.Background(), c, "GGAL", "", 5); err == nil {
+		t.Fatalf("Load with ERROR status: expected error")
+	}
+	if bid, ok := b.BestBid(); !ok || bid.Price != 100 {
+		t.Fatalf("book changed by ERROR snapshot: %+v", bid)
+	}
+}
@@ -3,7 +3,9 @@ package rofex
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/carvalab/rofex-go/rofex/model"
 )
@@ -20,8 +22,9 @@ type NewOrder struct {
 	Account        string
 	CancelPrevious bool
 	Iceberg        bool
-	ExpireDate     *string // yyyy-MM-dd for GTD
+	ExpireDate     time.Time // Día de vencimiento para GTD (se toma la fecha en MarketLocation)
 	DisplayQty     *int64
+	StopPx         *float64 // Precio stop para STOP_LIMIT / STOP_LIMIT_MERVAL / STOP
 	// WS-only optional fields
 	AllOrNone bool
 	WSClOrdID *string
@@ -37,12 +40,21 @@ func (o NewOrder) validate() error {
 	if o.Side == "" {
 		return &ValidationError{Field: "side", Msg: "required"}
 	}
-	if o.Type == model.OrderTypeLimit && o.Price == nil {
+	if o.Type.IsClientOnly() {
+		return &ValidationError{Field: "type", Msg: fmt.Sprintf("%s is emulated client-side, use StopManager", o.Type)}
+	}
+	if o.Type.HasLimitPrice() && o.Price == nil {
 		return &ValidationError{Field: "price", Msg: "required for limit"}
 	}
-	if o.TIF == model.GoodTillDate && (o.ExpireDate == nil || *o.ExpireDate == "") {
+	if o.Type.IsStop() && o.StopPx == nil {
+		return &ValidationError{Field: "stopPx", Msg: "required for stop"}
+	}
+	if o.TIF == model.GoodTillDate && o.ExpireDate.IsZero() {
 		return &ValidationError{Field: "expireDate", Msg: "required for GTD"}
 	}
+	if o.TIF != model.GoodTillDate && !o.ExpireDate.IsZero() {
+		return &ValidationError{Field: "expireDate", Msg: "only for GTD"}
+	}
 	return nil
 }
 
@@ -68,13 +80,20 @@ func (o NewOrder) validate() error {
 //
 // Parámetros condicionales:
 //   - Price: Requerido para órdenes LIMIT
+//   - StopPx: Requerido para órdenes STOP_LIMIT / STOP_LIMIT_MERVAL
 //   - DisplayQty: Requerido para órdenes Iceberg
-//   - ExpireDate: Requerido para órdenes GTD (formato: "20230720")
+//   - ExpireDate: Requerido para órdenes GTD. Debe ser un día hábil futuro (ver
+//     IsTradingDay y WithHolidays) y no posterior al maturityDate del instrumento;
+//     se envía como "20230720"
 //
 // Tipos de orden soportados:
 //   - LIMIT: Orden limitada con precio específico
 //   - MARKET: Orden a mercado
 //   - MARKET_TO_LIMIT: Orden a mercado convertida a limitada
+//   - STOP_LIMIT / STOP_LIMIT_MERVAL: Orden limitada activada por stopPx (si el
+//     instrumento no la soporta se rechaza localmente: usar StopManager para emularla)
+//
+// STOP no existe en el mercado y solo se acepta en StopManager.
 //
 // Modificadores de tiempo de vida:
 //   - DAY: Válida solo por el día, se expira al cierre
@@ -106,25 +125,54 @@ func (o NewOrder) validate() error {
 //
 // Referencia: docs/primary-api.md - "Ingresar una orden"
 func (c *Client) SendOrder(ctx context.Context, o NewOrder) (model.SendOrderResponse, error) {
-	if err := o.validate(); err != nil {
+	if err := c.validateOrder(ctx, o); err != nil {
 		return model.SendOrderResponse{}, err
 	}
 	if o.Market == "" {
 		o.Market = model.MarketROFEX
 	}
+	reserved, err := c.beforeNewOrder(ctx, o)
+	if err != nil {
+		return model.SendOrderResponse{}, err
+	}
+	if c.paper != nil {
+		rep := c.paper.submit(o, nil)
+		if model.OrderStatus(rep.Status) == model.StatusRejected {
+			reserved.release()
+		} else {
+			reserved.commit(rep.ClOrdID)
+		}
+		res := model.SendOrderResponse{Status: "OK"}
+		res.Order.ClientID, res.Order.Proprietary = rep.ClOrdID, rep.Proprietary
+		return res, nil
+	}
+	// URL-encode symbol: los símbolos MERV incluyen espacios ("MERV - XMEV - GGAL - 48hs")
 	path := fmt.Sprintf(pathNewOrder,
-		string(o.Market), o.Symbol, o.Qty, string(o.Type), string(o.Side), string(o.TIF), o.Account, o.CancelPrevious,
+		string(o.Market), url.QueryEscape(o.Symbol), o.Qty, string(o.Type), string(o.Side), string(o.TIF), o.Account, o.CancelPrevious,
 	)
-	if o.Type == model.OrderTypeLimit && o.Price != nil {
+	if o.Type.HasLimitPrice() && o.Price != nil {
 		path += fmt.Sprintf("&price=%v", *o.Price)
 	}
-	if o.TIF == model.GoodTillDate && o.ExpireDate != nil {
-		path += fmt.Sprintf("&expireDate=%s", *o.ExpireDate)
+	if o.Type.IsStop() && o.StopPx != nil {
+		path += fmt.Sprintf("&stopPx=%v", *o.StopPx)
+	}
+	if o.TIF == model.GoodTillDate {
+		path += "&expireDate=" + formatDate(o.ExpireDate)
 	}
 	if o.Iceberg && o.DisplayQty != nil {
 		path += fmt.Sprintf("&iceberg=true&displayQty=%d", *o.DisplayQty)
 	}
-	return getTyped[model.SendOrderResponse](ctx, c, path)
+	res, err := getTyped[model.SendOrderResponse](ctx, c, path)
+	if err != nil {
+		reserved.release()
+		return res, err
+	}
+	if strings.EqualFold(res.Status, "ERROR") {
+		reserved.release()
+		return res, nil
+	}
+	reserved.commit(res.Order.ClientID)
+	return res, nil
 }
 
 // CancelOrder cancela una orden vía REST según la documentación Primary API.
@@ -146,6 +194,15 @@ func (c *Client) CancelOrder(ctx context.Context, clientOrderID, proprietary str
 	if strings.TrimSpace(proprietary) == "" {
 		proprietary = c.proprietary
 	}
+	clientOrderID = c.liveID(clientOrderID)
+	if c.paper != nil {
+		if err := c.paper.cancelOrder(clientOrderID); err != nil {
+			return model.CancelOrderResponse{}, err
+		}
+		res := model.CancelOrderResponse{Status: "OK"}
+		res.Order.ClientID, res.Order.Proprietary = clientOrderID, proprietary
+		return res, nil
+	}
 	path := fmt.Sprintf(pathCancelOrder, clientOrderID, proprietary)
 	return getTyped[model.CancelOrderResponse](ctx, c, path)
 }
@@ -161,6 +218,9 @@ func (c *Client) CancelOrder(ctx context.Context, clientOrderID, proprietary str
 //   - newQty: Nueva cantidad (opcional)
 //   - newPrice: Nuevo precio (opcional)
 //
+// La respuesta trae el clientId de la orden nueva; la anterior termina CANCELLED con
+// text "Reemplazada". Cada reemplazo exitoso queda registrado en Client.Lineage.
+//
 // Referencia: docs/primary-api.md - "Reemplazar una orden"
 func (c *Client) ReplaceOrder(ctx context.Context, clOrdID, proprietary string, newQty *int64, newPrice *float64) (model.ReplaceOrderResponse, error) {
 	if clOrdID == "" {
@@ -169,6 +229,23 @@ func (c *Client) ReplaceOrder(ctx context.Context, clOrdID, proprietary string,
 	if strings.TrimSpace(proprietary) == "" {
 		proprietary = c.proprietary
 	}
+	clOrdID = c.liveID(clOrdID)
+	reserved, err := c.beforeReplaceOrder(ctx, clOrdID, proprietary, newQty, newPrice)
+	if err != nil {
+		return model.ReplaceOrderResponse{}, err
+	}
+	if c.paper != nil {
+		id, err := c.paper.replace(clOrdID, newQty, newPrice)
+		if err != nil {
+			reserved.release()
+			return model.ReplaceOrderResponse{}, err
+		}
+		reserved.commit(id)
+		c.lineage.Record(clOrdID, id)
+		res := model.ReplaceOrderResponse{Status: "OK"}
+		res.Order.ClientID, res.Order.Proprietary = id, proprietary
+		return res, nil
+	}
 	path := fmt.Sprintf(pathOrderReplace, clOrdID, proprietary)
 	if newQty != nil {
 		path += fmt.Sprintf("&orderQty=%d", *newQty)
@@ -176,7 +253,18 @@ func (c *Client) ReplaceOrder(ctx context.Context, clOrdID, proprietary string,
 	if newPrice != nil {
 		path += fmt.Sprintf("&price=%v", *newPrice)
 	}
-	return getTyped[model.ReplaceOrderResponse](ctx, c, path)
+	res, err := getTyped[model.ReplaceOrderResponse](ctx, c, path)
+	if err != nil {
+		reserved.release()
+		return res, err
+	}
+	if res.Status == "" || strings.EqualFold(res.Status, "OK") {
+		reserved.commit(res.Order.ClientID)
+		c.lineage.Record(clOrdID, res.Order.ClientID)
+	} else {
+		reserved.release()
+	}
+	return res, nil
 }
 
 // OrderStatus consulta el estado de una orden según la documentación Primary API.
@@ -204,6 +292,10 @@ func (c *Client) OrderStatus(ctx context.Context, clientOrderID, proprietary str
 	if strings.TrimSpace(proprietary) == "" {
 		proprietary = c.proprietary
 	}
+	clientOrderID = c.liveID(clientOrderID)
+	if c.paper != nil {
+		return c.paper.orderStatus(clientOrderID)
+	}
 	path := fmt.Sprintf(pathOrderStatus, clientOrderID, proprietary)
 	return getTyped[model.OrderStatusResponse](ctx, c, path)
 }
@@ -221,6 +313,9 @@ func (c *Client) OrderHistoryByClOrdID(ctx context.Context, clOrdID, proprietary
 	if strings.TrimSpace(proprietary) == "" {
 		proprietary = c.proprietary
 	}
+	if c.paper != nil {
+		return c.paper.orderHistory(clOrdID)
+	}
 	path := fmt.Sprintf(pathOrderAllByID, clOrdID, proprietary)
 	return getTyped[model.AllOrdersStatusResponse](ctx, c, path)
 }
@@ -234,6 +329,9 @@ func (c *Client) OrderByOrderID(ctx context.Context, orderID string) (model.Orde
 	if strings.TrimSpace(orderID) == "" {
 		return model.OrderStatusResponse{}, &ValidationError{Field: "orderID", Msg: "required"}
 	}
+	if c.paper != nil {
+		return c.paper.lookup(c.paper.byOrderID, orderID)
+	}
 	path := fmt.Sprintf(pathOrderByOrder, orderID)
 	return getTyped[model.OrderStatusResponse](ctx, c, path)
 }
@@ -248,6 +346,9 @@ func (c *Client) OrderByExecID(ctx context.Context, execID string) (model.OrderS
 	if strings.TrimSpace(execID) == "" {
 		return model.OrderStatusResponse{}, &ValidationError{Field: "execID", Msg: "required"}
 	}
+	if c.paper != nil {
+		return c.paper.lookup(c.paper.byExecID, execID)
+	}
 	path := fmt.Sprintf(pathOrderByExecID, execID)
 	return getTyped[model.OrderStatusResponse](ctx, c, path)
 }
@@ -259,8 +360,11 @@ func (c *Client) OrderByExecID(ctx context.Context, execID string) (model.OrderS
 //
 // Referencia: docs/primary-api.md - "Consultar Ordenes Operadas"
 func (c *Client) FilledOrders(ctx context.Context, account string) (model.AllOrdersStatusResponse, error) {
-	if account == "" {
-		return model.AllOrdersStatusResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.AllOrdersStatusResponse{}, err
+	}
+	if c.paper != nil {
+		return c.paper.accountOrders(account, func(po *paperOrder) bool { return po.cum > 0 }), nil
 	}
 	path := fmt.Sprintf(pathOrderFilleds, account)
 	return getTyped[model.AllOrdersStatusResponse](ctx, c, path)
@@ -272,8 +376,11 @@ func (c *Client) FilledOrders(ctx context.Context, account string) (model.AllOrd
 //
 // Referencia: docs/primary-api.md - "Consultar órdenes activas"
 func (c *Client) ActiveOrders(ctx context.Context, account string) (model.AllOrdersStatusResponse, error) {
-	if account == "" {
-		return model.AllOrdersStatusResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.AllOrdersStatusResponse{}, err
+	}
+	if c.paper != nil {
+		return c.paper.accountOrders(account, (*paperOrder).active), nil
 	}
 	path := fmt.Sprintf(pathOrderActives, account)
 	return getTyped[model.AllOrdersStatusResponse](ctx, c, path)
@@ -286,8 +393,11 @@ func (c *Client) ActiveOrders(ctx context.Context, account string) (model.AllOrd
 //
 // Referencia: docs/primary-api.md - "Estado de orden por ID Cuenta"
 func (c *Client) AllOrdersStatus(ctx context.Context, account string) (model.AllOrdersStatusResponse, error) {
-	if account == "" {
-		return model.AllOrdersStatusResponse{}, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return model.AllOrdersStatusResponse{}, err
+	}
+	if c.paper != nil {
+		return c.paper.accountOrders(account, func(*paperOrder) bool { return true }), nil
 	}
 	path := fmt.Sprintf(pathAllOrders, account)
 	return getTyped[model.AllOrdersStatusResponse](ctx, c, path)
@@ -0,0 +1,689 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// PaperBroker es el simulador local que atiende las órdenes de un cliente creado con
+// WithPaperTrading.
+//
+// Las órdenes se calzan contra el libro en vivo (BI/OF/LA) que llega por
+// SubscribeMarketData:
+//   - Al ingresar, una orden opera contra las puntas contrarias que cruza, nivel por
+//     nivel y al precio de cada nivel. La liquidez tomada se descuenta del libro local
+//     hasta la siguiente actualización.
+//   - Una orden que queda en el libro opera a su precio cuando la punta contraria la
+//     cruza o cuando un nuevo último operado (LA) la atraviesa (precio estrictamente
+//     mejor). Operar al mismo precio no la ejecuta: no se conoce su lugar en la cola.
+//   - IOC, FOK y MARKET cancelan el remanente; MARKET_TO_LIMIT deja el remanente como
+//     LIMIT al último precio operado. Las órdenes stop no se simulan (se rechazan; usar
+//     StopManager, que las emula con órdenes LIMIT).
+//   - El tamaño de un último operado (LA) se reparte entre las órdenes que atraviesa, en
+//     prioridad precio-tiempo.
+//
+// Cada cambio de estado se publica como Execution Report en las suscripciones de
+// SubscribeOrderReport y actualiza las posiciones que devuelve AccountPosition.
+//
+// Si no hay una suscripción de Market Data para el instrumento de una orden, el
+// simulador abre una (BI, OF, LA, profundidad 5) que se cierra con Close. También se
+// puede alimentar el libro directamente con OnMarketData (backtests, tests).
+type PaperBroker struct {
+	client *Client
+
+	mu        sync.Mutex
+	seq       int64                  // Órdenes
+	execSeq   int64                  // Execution Reports
+	orders    map[string]*paperOrder // Por clOrdId
+	byOrderID map[string]*paperOrder
+	byExecID  map[string]*paperOrder
+	byAccount map[string][]*paperOrder // En orden de ingreso
+	books     map[model.InstrumentID]*paperBook
+	positions map[string]map[model.InstrumentID]*model.Position
+	subs      map[*paperSub]struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// paperOrder es una orden simulada con todos sus estados.
+type paperOrder struct {
+	seq      int64
+	order    NewOrder
+	price    *float64
+	qty      int64
+	cum      int64
+	notional float64
+	last     model.OrderDetails
+	history  []model.OrderDetails
+}
+
+func (po *paperOrder) leaves() int64 { return po.qty - po.cum }
+
+func (po *paperOrder) active() bool {
+	return model.OrderStatus(po.last.Status).IsActive()
+}
+
+// paperBook es el libro local de un instrumento.
+type paperBook struct {
+	bids, offers []model.BookLevel
+	lastTrade    model.Entry
+	fed          bool // El libro recibe Market Data (suscripción propia, del usuario u OnMarketData)
+}
+
+// paperSub es una suscripción a Execution Reports simulados.
+type paperSub struct {
+	account string
+	ch      chan *model.OrderReportEvent
+	once    sync.Once
+}
+
+func newPaperBroker(c *Client) *PaperBroker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &PaperBroker{
+		client:    c,
+		orders:    make(map[string]*paperOrder),
+		byOrderID: make(map[string]*paperOrder),
+		byExecID:  make(map[string]*paperOrder),
+		byAccount: make(map[string][]*paperOrder),
+		books:     make(map[model.InstrumentID]*paperBook),
+		positions: make(map[string]map[model.InstrumentID]*model.Position),
+		subs:      make(map[*paperSub]struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+// PaperBroker devuelve el simulador de órdenes, o nil si el cliente no fue creado con
+// WithPaperTrading.
+func (c *Client) PaperBroker() *PaperBroker { return c.paper }
+
+// Close cierra las suscripciones de Market Data abiertas por el simulador.
+func (b *PaperBroker) Close() { b.cancel() }
+
+// OnMarketData actualiza el libro local del instrumento y ejecuta las órdenes en el
+// libro que queden cruzadas.
+func (b *PaperBroker) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	book := b.bookLocked(ev.InstrumentID)
+	book.fed = true
+	md := ev.MarketData
+	if md.Bids != nil {
+		book.bids = append([]model.BookLevel(nil), md.Bids...)
+	}
+	if md.Offers != nil {
+		book.offers = append([]model.BookLevel(nil), md.Offers...)
+	}
+	var trade *model.Entry
+	if md.LA != nil && md.LA.Price != nil && !sameEntry(*md.LA, book.lastTrade) {
+		book.lastTrade = *md.LA
+		trade = md.LA
+	}
+
+	var resting []*paperOrder
+	for _, po := range b.orders {
+		if po.active() && po.order.Symbol == ev.InstrumentID.Symbol && string(po.order.Market) == ev.InstrumentID.MarketID {
+			resting = append(resting, po)
+		}
+	}
+	// Prioridad precio-tiempo
+	sort.Slice(resting, func(i, j int) bool {
+		pi, pj := *resting[i].price, *resting[j].price
+		if pi != pj {
+			if resting[i].order.Side == model.Buy {
+				return pi > pj
+			}
+			return pi < pj
+		}
+		return resting[i].seq < resting[j].seq
+	})
+	// El tamaño del operado se reparte entre las órdenes que atraviesa, en prioridad
+	// precio-tiempo (sin tamaño informado no se limita)
+	tradeLeft := int64(-1)
+	if trade != nil && trade.Size != nil {
+		tradeLeft = int64(*trade.Size)
+	}
+	for _, po := range resting {
+		b.matchLocked(po, book, true)
+		if trade != nil && tradeLeft != 0 && po.leaves() > 0 && tradesThrough(po, *trade.Price) {
+			qty := po.leaves()
+			if tradeLeft > 0 && tradeLeft < qty {
+				qty = tradeLeft
+			}
+			if qty > 0 {
+				b.fillLocked(po, qty, *po.price)
+				if tradeLeft > 0 {
+					tradeLeft -= qty
+				}
+			}
+		}
+	}
+}
+
+func (b *PaperBroker) bookLocked(id model.InstrumentID) *paperBook {
+	book, ok := b.books[id]
+	if !ok {
+		book = &paperBook{}
+		b.books[id] = book
+	}
+	return book
+}
+
+// watch marca un instrumento como alimentado por una suscripción del usuario.
+func (b *PaperBroker) watch(instruments []model.InstrumentID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range instruments {
+		b.bookLocked(id).fed = true
+	}
+}
+
+// unwatch indica que una suscripción del usuario dejó de alimentar los instrumentos.
+// Si quedan órdenes activas en alguno, el simulador abre su propia suscripción; si no,
+// la abrirá la próxima orden.
+func (b *PaperBroker) unwatch(instruments []model.InstrumentID) {
+	b.mu.Lock()
+	var dial []model.InstrumentID
+	for _, id := range instruments {
+		book := b.bookLocked(id)
+		book.fed = false
+		for _, po := range b.orders {
+			if po.active() && po.order.Symbol == id.Symbol && string(po.order.Market) == id.MarketID {
+				book.fed = true
+				dial = append(dial, id)
+				break
+			}
+		}
+	}
+	b.mu.Unlock()
+	for _, id := range dial {
+		b.openFeed(id)
+	}
+}
+
+// ensureFeedLocked marca el instrumento como alimentado e indica si nadie tenía una
+// suscripción de Market Data para él; en ese caso el llamador debe abrirla con openFeed
+// después de liberar b.mu (la conexión no se abre bajo el lock).
+func (b *PaperBroker) ensureFeedLocked(id model.InstrumentID) bool {
+	book := b.bookLocked(id)
+	if book.fed {
+		return false
+	}
+	book.fed = true
+	return true
+}
+
+// openFeed abre la suscripción de Market Data del simulador para el instrumento.
+func (b *PaperBroker) openFeed(id model.InstrumentID) {
+	entries := []model.MDEntry{model.MDBids, model.MDOffers, model.MDLast}
+	sub, err := b.client.subscribeMarketData(b.ctx, []model.InstrumentID{id}, entries, 5)
+	if err != nil {
+		b.mu.Lock()
+		b.bookLocked(id).fed = false
+		b.mu.Unlock()
+		b.logWarn("paper trading: market data subscription failed", slog.String("symbol", id.Symbol), slog.Any("err", err))
+		return
+	}
+	go func() {
+		for ev := range sub.Events {
+			b.OnMarketData(ev)
+		}
+	}()
+	go func() {
+		for err := range sub.Errs {
+			b.logWarn("paper trading: market data error", slog.String("symbol", id.Symbol), slog.Any("err", err))
+		}
+	}()
+}
+
+// submit ingresa una orden validada y devuelve su primer reporte: PENDING_NEW, o
+// REJECTED si el simulador no la admite.
+func (b *PaperBroker) submit(o NewOrder, wsClOrdID *string) model.OrderDetails {
+	b.mu.Lock()
+	po := b.newOrderLocked(o, o.Price, o.Qty)
+	var first model.OrderDetails
+	dial, inst := false, po.last.InstrumentID
+	if o.Type.IsStop() {
+		first = b.reportLocked(po, model.StatusRejected, "paper trading: stop orders are not simulated")
+	} else {
+		first = b.reportLocked(po, model.StatusPendingNew, "")
+	}
+	first.WSClOrdID = wsClOrdID
+	po.last.WSClOrdID, po.history[0].WSClOrdID = wsClOrdID, wsClOrdID
+	b.publishLocked(po.order.Account, first)
+	if po.active() {
+		dial = b.acceptLocked(po)
+	}
+	b.mu.Unlock()
+	if dial {
+		b.openFeed(inst)
+	}
+	return first
+}
+
+func (b *PaperBroker) newOrderLocked(o NewOrder, price *float64, qty int64) *paperOrder {
+	b.seq++
+	po := &paperOrder{seq: b.seq, order: o, price: price, qty: qty}
+	clOrdID := fmt.Sprintf("paper%d", b.seq)
+	orderID := fmt.Sprintf("P%d", b.seq)
+	po.last = model.OrderDetails{
+		OrderID:      &orderID,
+		ClOrdID:      clOrdID,
+		Proprietary:  b.client.proprietary,
+		AccountID:    &model.AccountReference{ID: o.Account},
+		InstrumentID: model.InstrumentID{Symbol: o.Symbol, MarketID: string(o.Market)},
+		OrderQty:     int(qty),
+		OrdType:      string(o.Type),
+		Side:         string(o.Side),
+		TimeInForce:  string(o.TIF),
+	}
+	b.orders[clOrdID] = po
+	b.byOrderID[orderID] = po
+	b.byAccount[o.Account] = append(b.byAccount[o.Account], po)
+	return po
+}
+
+// acceptLocked confirma la orden y la calza contra el libro. Devuelve si hay que abrir
+// la suscripción de Market Data del instrumento (ver ensureFeedLocked).
+func (b *PaperBroker) acceptLocked(po *paperOrder) (dial bool) {
+	o := po.order
+	id := model.InstrumentID{Symbol: o.Symbol, MarketID: string(o.Market)}
+	dial = b.ensureFeedLocked(id)
+	book := b.bookLocked(id)
+	if o.TIF == model.FillOrKill && available(book, po) < po.qty {
+		b.publishLocked(o.Account, b.reportLocked(po, model.StatusNew, ""))
+		b.publishLocked(o.Account, b.reportLocked(po, model.StatusCancelled, "FOK not fully executable"))
+		return dial
+	}
+	b.publishLocked(o.Account, b.reportLocked(po, model.StatusNew, ""))
+	b.matchLocked(po, book, false)
+	if po.leaves() == 0 {
+		return dial
+	}
+	switch {
+	case o.Type == model.OrderTypeMarketToLimit && po.cum > 0:
+		px := *po.last.LastPx
+		po.price = &px
+	case o.Type == model.OrderTypeMarket || o.Type == model.OrderTypeMarketToLimit ||
+		o.TIF == model.ImmediateOrCancel || o.TIF == model.FillOrKill:
+		b.publishLocked(o.Account, b.reportLocked(po, model.StatusCancelled, "Not executed remainder cancelled"))
+	}
+	return dial
+}
+
+// matchLocked ejecuta la orden contra las puntas contrarias que cruza. Una orden en el
+// libro (resting) opera a su propio precio; una orden entrante, al de cada nivel.
+func (b *PaperBroker) matchLocked(po *paperOrder, book *paperBook, resting bool) {
+	levels := &book.offers
+	if po.order.Side == model.Sell {
+		levels = &book.bids
+	}
+	for po.leaves() > 0 && len(*levels) > 0 {
+		lvl := &(*levels)[0]
+		if !crosses(po, lvl.Price) {
+			return
+		}
+		qty := po.leaves()
+		if int64(lvl.Size) < qty {
+			qty = int64(lvl.Size)
+		}
+		if qty <= 0 {
+			*levels = (*levels)[1:]
+			continue
+		}
+		px := lvl.Price
+		if resting {
+			px = *po.price
+		}
+		b.fillLocked(po, qty, px)
+		lvl.Size -= float64(qty)
+		if lvl.Size <= 0 {
+			*levels = (*levels)[1:]
+		}
+	}
+}
+
+// fillLocked registra una ejecución, publica su reporte y actualiza la posición.
+func (b *PaperBroker) fillLocked(po *paperOrder, qty int64, px float64) {
+	po.cum += qty
+	po.notional += float64(qty) * px
+	status := model.StatusPartiallyFilled
+	if po.leaves() == 0 {
+		status = model.StatusFilled
+	}
+	lastQty := int(qty)
+	po.last.LastQty, po.last.LastPx = &lastQty, &px
+	rep := b.reportLocked(po, status, "")
+	b.byExecID[*rep.ExecID] = po
+	b.publishLocked(po.order.Account, rep)
+
+	pos := b.positionLocked(po.order.Account, po.last.InstrumentID)
+	if po.order.Side == model.Buy {
+		pos.BuyPrice = (pos.BuyPrice*pos.BuySize + px*float64(qty)) / (pos.BuySize + float64(qty))
+		pos.BuySize += float64(qty)
+	} else {
+		pos.SellPrice = (pos.SellPrice*pos.SellSize + px*float64(qty)) / (pos.SellSize + float64(qty))
+		pos.SellSize += float64(qty)
+	}
+}
+
+func (b *PaperBroker) positionLocked(account string, id model.InstrumentID) *model.Position {
+	byInst, ok := b.positions[account]
+	if !ok {
+		byInst = make(map[model.InstrumentID]*model.Position)
+		b.positions[account] = byInst
+	}
+	pos, ok := byInst[id]
+	if !ok {
+		pos = &model.Position{Symbol: id.Symbol, TradingSymbol: id.Symbol, Instrument: model.PositionInstrument{SymbolReference: id.Symbol}}
+		byInst[id] = pos
+	}
+	return pos
+}
+
+// reportLocked pasa la orden a status y devuelve el Execution Report correspondiente.
+func (b *PaperBroker) reportLocked(po *paperOrder, status model.OrderStatus, text string) model.OrderDetails {
+	b.execSeq++
+	execID := fmt.Sprintf("E%d", b.execSeq)
+	d := po.last
+	d.Status = string(status)
+	d.ExecID = &execID
+	d.Price = po.price
+	d.OrderQty = int(po.qty)
+	d.TransactTime = time.Now().In(MarketLocation).Format(transactTimeLayout)
+	cum, leaves := int(po.cum), int(po.leaves())
+	if status.IsTerminal() {
+		leaves = 0
+	}
+	d.CumQty, d.LeavesQty = &cum, &leaves
+	if po.cum > 0 {
+		avg := po.notional / float64(po.cum)
+		d.AvgPx = &avg
+	}
+	if status != model.StatusPartiallyFilled && status != model.StatusFilled {
+		d.LastQty, d.LastPx = nil, nil
+	}
+	d.Text = nil
+	if text != "" {
+		d.Text = &text
+	}
+	d.WSClOrdID = nil
+	po.last = d
+	po.history = append(po.history, d)
+	return d
+}
+
+// publishLocked envía el reporte a las suscripciones de la cuenta.
+func (b *PaperBroker) publishLocked(account string, d model.OrderDetails) {
+	ts := time.Now().UnixMilli()
+	for s := range b.subs {
+		if s.account != account {
+			continue
+		}
+		select {
+		case s.ch <- &model.OrderReportEvent{Type: model.WSMessageOrderReport, Timestamp: &ts, OrderReport: d}:
+		default:
+			b.logWarn("paper trading: order report dropped - channel full", slog.String("clOrdId", d.ClOrdID))
+		}
+	}
+}
+
+// cancel cancela una orden activa.
+func (b *PaperBroker) cancelOrder(clOrdID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	po, err := b.activeLocked(clOrdID)
+	if err != nil {
+		return err
+	}
+	b.publishLocked(po.order.Account, b.reportLocked(po, model.StatusCancelled, ""))
+	return nil
+}
+
+// replace reemplaza una orden activa por una nueva con la cantidad total y el precio
+// indicados (nil conserva los de la orden original). La orden nueva conserva lo operado
+// (cumQty) de la reemplazada. Devuelve el nuevo clOrdId.
+func (b *PaperBroker) replace(clOrdID string, newQty *int64, newPrice *float64) (string, error) {
+	b.mu.Lock()
+	id, dial, err := b.replaceLocked(clOrdID, newQty, newPrice)
+	var inst model.InstrumentID
+	if dial {
+		inst = b.orders[id].last.InstrumentID
+	}
+	b.mu.Unlock()
+	if dial {
+		b.openFeed(inst)
+	}
+	return id, err
+}
+
+func (b *PaperBroker) replaceLocked(clOrdID string, newQty *int64, newPrice *float64) (string, bool, error) {
+	old, err := b.activeLocked(clOrdID)
+	if err != nil {
+		return "", false, err
+	}
+	qty, price := old.qty, old.price
+	if newQty != nil {
+		qty = *newQty
+	}
+	if newPrice != nil {
+		px := *newPrice
+		price = &px
+	}
+	if qty <= old.cum {
+		return "", false, &ValidationError{Field: "newQty", Msg: "must exceed the filled quantity"}
+	}
+	b.publishLocked(old.order.Account, b.reportLocked(old, model.StatusCancelled, "Reemplazada"))
+	o := old.order
+	o.Qty, o.Price = qty, price
+	po := b.newOrderLocked(o, price, qty)
+	po.cum, po.notional = old.cum, old.notional
+	b.publishLocked(o.Account, b.reportLocked(po, model.StatusPendingNew, ""))
+	dial := b.acceptLocked(po)
+	return po.last.ClOrdID, dial, nil
+}
+
+func (b *PaperBroker) activeLocked(clOrdID string) (*paperOrder, error) {
+	po, ok := b.orders[clOrdID]
+	if !ok {
+		return nil, fmt.Errorf("paper trading: unknown order %s", clOrdID)
+	}
+	if !po.active() {
+		return nil, fmt.Errorf("paper trading: order %s is %s", clOrdID, po.last.Status)
+	}
+	return po, nil
+}
+
+// subscribe registra una suscripción a los reportes de la cuenta y envía el snapshot
+// de sus órdenes (solo las activas si snapshotOnlyActive).
+func (b *PaperBroker) subscribe(ctx context.Context, account string, snapshotOnlyActive bool) *OrderReportSubscription {
+	b.mu.Lock()
+	s := &paperSub{account: account, ch: make(chan *model.OrderReportEvent, b.client.wsBuf)}
+	for _, po := range b.byAccount[account] {
+		if snapshotOnlyActive && !po.active() {
+			continue
+		}
+		select {
+		case s.ch <- &model.OrderReportEvent{Type: model.WSMessageOrderReport, OrderReport: po.last}:
+		default:
+		}
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	closeSub := func() error {
+		s.once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, s)
+			close(s.ch)
+			b.mu.Unlock()
+		})
+		return nil
+	}
+	go func() {
+		<-ctx.Done()
+		_ = closeSub()
+	}()
+	errs := make(chan error)
+	return &OrderReportSubscription{Events: s.ch, Errs: errs, Close: closeSub, account: account, snapshotOnlyActive: snapshotOnlyActive}
+}
+
+func (b *PaperBroker) orderStatus(clOrdID string) (model.OrderStatusResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	po, ok := b.orders[clOrdID]
+	if !ok {
+		return model.OrderStatusResponse{}, fmt.Errorf("paper trading: unknown order %s", clOrdID)
+	}
+	return model.OrderStatusResponse{Status: "OK", Order: paperOrderStatus(po.last)}, nil
+}
+
+func (b *PaperBroker) orderHistory(clOrdID string) (model.AllOrdersStatusResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	po, ok := b.orders[clOrdID]
+	if !ok {
+		return model.AllOrdersStatusResponse{}, fmt.Errorf("paper trading: unknown order %s", clOrdID)
+	}
+	res := model.AllOrdersStatusResponse{Status: "OK"}
+	for _, d := range po.history {
+		res.Orders = append(res.Orders, paperOrderStatus(d))
+	}
+	return res, nil
+}
+
+func (b *PaperBroker) lookup(index map[string]*paperOrder, id string) (model.OrderStatusResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	po, ok := index[id]
+	if !ok {
+		return model.OrderStatusResponse{}, fmt.Errorf("paper trading: unknown order %s", id)
+	}
+	return model.OrderStatusResponse{Status: "OK", Order: paperOrderStatus(po.last)}, nil
+}
+
+// accountOrders devuelve el último estado de las órdenes de la cuenta que cumplan keep.
+func (b *PaperBroker) accountOrders(account string, keep func(*paperOrder) bool) model.AllOrdersStatusResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := model.AllOrdersStatusResponse{Status: "OK", Orders: []model.Order{}}
+	for _, po := range b.byAccount[account] {
+		if keep(po) {
+			res.Orders = append(res.Orders, paperOrderStatus(po.last))
+		}
+	}
+	return res
+}
+
+func (b *PaperBroker) accountPosition(account string) model.AccountPositionResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := model.AccountPositionResponse{Status: "OK", Positions: []model.Position{}}
+	for _, pos := range b.positions[account] {
+		res.Positions = append(res.Positions, *pos)
+	}
+	sort.Slice(res.Positions, func(i, j int) bool { return res.Positions[i].Symbol < res.Positions[j].Symbol })
+	return res
+}
+
+func (b *PaperBroker) logWarn(msg string, args ...any) {
+	if b.client.logger != nil {
+		b.client.logger.Warn(msg, args...)
+	}
+}
+
+// crosses indica si la orden opera contra un nivel contrario de precio px.
+func crosses(po *paperOrder, px float64) bool {
+	if po.price == nil {
+		return true
+	}
+	if po.order.Side == model.Buy {
+		return px <= *po.price
+	}
+	return px >= *po.price
+}
+
+// tradesThrough indica si un operado a px atraviesa el precio de la orden.
+func tradesThrough(po *paperOrder, px float64) bool {
+	if po.order.Side == model.Buy {
+		return px < *po.price
+	}
+	return px > *po.price
+}
+
+// available devuelve la cantidad contraria que la orden podría tomar del libro.
+func available(book *paperBook, po *paperOrder) int64 {
+	levels := book.offers
+	if po.order.Side == model.Sell {
+		levels = book.bids
+	}
+	var n int64
+	for _, l := range levels {
+		if !crosses(po, l.Price) {
+			break
+		}
+		n += int64(l.Size)
+	}
+	return n
+}
+
+func sameEntry(a, b model.Entry) bool {
+	eq := func(x, y *float64) bool { return (x == nil && y == nil) || (x != nil && y != nil && *x == *y) }
+	eqi := func(x, y *int64) bool { return (x == nil && y == nil) || (x != nil && y != nil && *x == *y) }
+	return eq(a.Price, b.Price) && eq(a.Size, b.Size) && eqi(a.Date, b.Date)
+}
+
+// paperOrderStatus convierte un Execution Report al formato de las consultas REST.
+func paperOrderStatus(d model.OrderDetails) model.Order {
+	o := model.Order{
+		InstrumentID: d.InstrumentID,
+		ClOrdID:      d.ClOrdID,
+		Proprietary:  d.Proprietary,
+		Status:       d.Status,
+		Side:         model.Side(d.Side),
+		OrdType:      model.OrderType(d.OrdType),
+		TimeInForce:  model.TimeInForce(d.TimeInForce),
+		Price:        d.Price,
+		AvgPx:        d.AvgPx,
+		LastPx:       d.LastPx,
+		TransactTime: d.TransactTime,
+	}
+	if d.OrderID != nil {
+		o.OrderID = *d.OrderID
+	}
+	if d.ExecID != nil {
+		o.ExecID = *d.ExecID
+	}
+	if d.AccountID != nil {
+		o.AccountID = &struct {
+			ID string `json:"id"`
+		}{ID: d.AccountID.ID}
+	}
+	if d.Text != nil {
+		o.Text = *d.Text
+	}
+	qty := int64(d.OrderQty)
+	o.OrderQty = &qty
+	o.LeavesQty = int64Ptr(d.LeavesQty)
+	o.CumQty = int64Ptr(d.CumQty)
+	o.LastQty = int64Ptr(d.LastQty)
+	return o
+}
+
+func int64Ptr(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
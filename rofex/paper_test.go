@@ -0,0 +1,172 @@
+package rofex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestPaperTrading(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	symbol := "DLR/MAR26"
+	id := model.InstrumentID{Symbol: symbol, MarketID: string(model.MarketROFEX)}
+
+	// Sin BaseURL válida: cualquier request real haría fallar el test
+	c, _ := NewClient(WithBaseURL("http://127.0.0.1:1/"), WithPaperTrading())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	paper := c.PaperBroker()
+	defer paper.Close()
+
+	paper.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+		Bids:   []model.BookLevel{{Price: 99, Size: 10}},
+		Offers: []model.BookLevel{{Price: 101, Size: 5}, {Price: 102, Size: 10}},
+	}})
+	sub, err := c.SubscribeOrderReport(ctx, account, false)
+	if err != nil {
+		t.Fatalf("SubscribeOrderReport: %v", err)
+	}
+	defer sub.Close()
+	var got []string
+	drain := func() {
+		for {
+			select {
+			case ev := <-sub.Events:
+				r := ev.OrderReport
+				s := r.ClOrdID + " " + r.Status
+				if r.LastQty != nil {
+					s += fmt.Sprintf(" %d@%v", *r.LastQty, *r.LastPx)
+				}
+				if r.Text != nil {
+					s += " " + *r.Text
+				}
+				got = append(got, s)
+			default:
+				return
+			}
+		}
+	}
+
+	// Compra que toma la mejor oferta y deja el remanente en el libro
+	price := 101.5
+	buy := NewOrder{Symbol: symbol, Side: model.Buy, Type: model.OrderTypeLimit, Qty: 8, Price: &price, TIF: model.Day, Account: account}
+	res, err := c.SendOrder(ctx, buy)
+	if err != nil || res.Order.ClientID != "paper1" {
+		t.Fatalf("SendOrder: %+v %v", res, err)
+	}
+	// Un operado que atraviesa el precio ejecuta la orden en el libro
+	la, size := 101.0, 2.0
+	paper.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{LA: &model.Entry{Price: &la, Size: &size}}})
+	paper.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{LA: &model.Entry{Price: &la, Size: &size}}}) // repetido
+
+	newPrice := 100.0
+	rep, err := c.ReplaceOrder(ctx, "paper1", "", nil, &newPrice)
+	if err != nil || rep.Order.ClientID != "paper2" {
+		t.Fatalf("ReplaceOrder: %+v %v", rep, err)
+	}
+	paper.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{Offers: []model.BookLevel{{Price: 100, Size: 5}}}})
+
+	// Venta a mercado por más de lo disponible: se cancela el remanente
+	if _, err := c.SendOrder(ctx, NewOrder{Symbol: symbol, Side: model.Sell, Type: model.OrderTypeMarket, Qty: 20, TIF: model.Day, Account: account}); err != nil {
+		t.Fatalf("SendOrder market: %v", err)
+	}
+	drain()
+
+	want := []string{
+		"paper1 PENDING_NEW", "paper1 NEW", "paper1 PARTIALLY_FILLED 5@101",
+		"paper1 PARTIALLY_FILLED 2@101.5",
+		"paper1 CANCELLED Reemplazada", "paper2 PENDING_NEW", "paper2 NEW",
+		"paper2 FILLED 1@100",
+		"paper3 PENDING_NEW", "paper3 NEW", "paper3 PARTIALLY_FILLED 10@99", "paper3 CANCELLED Not executed remainder cancelled",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("reports:\nwant %v\ngot  %v", want, got)
+	}
+
+	pos, err := c.AccountPosition(ctx, account)
+	if err != nil || len(pos.Positions) != 1 {
+		t.Fatalf("AccountPosition: %+v %v", pos, err)
+	}
+	if p := pos.Positions[0]; p.BuySize != 8 || p.BuyPrice != 101 || p.SellSize != 10 || p.SellPrice != 99 {
+		t.Fatalf("position: %+v", p)
+	}
+	st, err := c.OrderStatus(ctx, "paper1", "")
+	if err != nil || st.Order.Status != "CANCELLED" || st.Order.Text != "Reemplazada" || *st.Order.CumQty != 7 {
+		t.Fatalf("OrderStatus: %+v %v", st, err)
+	}
+	if filled, _ := c.FilledOrders(ctx, account); len(filled.Orders) != 3 {
+		t.Fatalf("FilledOrders: %+v", filled)
+	}
+
+	// Orden por WebSocket que queda en el libro y se cancela
+	low := 90.0
+	buy.Price = &low
+	h, err := c.SendOrderWS(ctx, buy)
+	if err != nil {
+		t.Fatalf("SendOrderWS: %v", err)
+	}
+	ack, err := h.Wait(ctx)
+	if err != nil || ack.ClOrdID != "paper4" || ack.Status != model.StatusPendingNew {
+		t.Fatalf("ack: %+v %v", ack, err)
+	}
+	if active, _ := c.ActiveOrders(ctx, account); len(active.Orders) != 1 {
+		t.Fatalf("ActiveOrders: %+v", active)
+	}
+	if err := c.CancelOrderWS(ctx, "paper4", ""); err != nil {
+		t.Fatalf("CancelOrderWS: %v", err)
+	}
+	if res, err := c.CancelOrder(ctx, "paper4", ""); err == nil || res.Status == "OK" {
+		t.Fatalf("cancel of a cancelled order must fail: %+v %v", res, err)
+	}
+	if active, _ := c.ActiveOrders(ctx, account); len(active.Orders) != 0 {
+		t.Fatalf("ActiveOrders after cancel: %+v", active)
+	}
+
+	// Un operado se reparte entre las órdenes que atraviesa
+	drain()
+	got = nil
+	for _, px := range []float64{95, 94} {
+		px := px
+		buy.Qty, buy.Price = 3, &px
+		if _, err := c.SendOrder(ctx, buy); err != nil {
+			t.Fatalf("SendOrder: %v", err)
+		}
+	}
+	tradePx, tradeSize := 93.0, 4.0
+	paper.OnMarketData(&model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{LA: &model.Entry{Price: &tradePx, Size: &tradeSize}}})
+	drain()
+	want = []string{
+		"paper5 PENDING_NEW", "paper5 NEW", "paper6 PENDING_NEW", "paper6 NEW",
+		"paper5 FILLED 3@95", "paper6 PARTIALLY_FILLED 1@94",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("trade-through reports:\nwant %v\ngot  %v", want, got)
+	}
+
+	// Un rechazo del simulador se informa como OrderRejectedError
+	stopPx := 97.0
+	stop := buy
+	stop.Type, stop.StopPx, stop.WSClOrdID = model.OrderTypeStopLimit, &stopPx, &symbol
+	if _, err := c.paperOrderWS(stop, nil).Wait(ctx); !errors.As(err, new(*OrderRejectedError)) {
+		t.Fatalf("want OrderRejectedError, got %v", err)
+	}
+
+	// Quitar un instrumento de una suscripción del usuario deja de considerarlo alimentado
+	other := model.InstrumentID{Symbol: "DLR/JUN26", MarketID: string(model.MarketROFEX)}
+	md := &MarketDataSubscription{client: c, instruments: []model.InstrumentID{other}}
+	paper.watch(md.Instruments())
+	if err := md.Remove(ctx, model.InstrumentID{Symbol: other.Symbol}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	paper.mu.Lock()
+	fed := paper.books[other].fed
+	paper.mu.Unlock()
+	if fed {
+		t.Fatalf("removed instrument still fed")
+	}
+}
@@ -0,0 +1,393 @@
+package rofex
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// CostMethod es el método de costeo con que se calcula el PnL realizado.
+type CostMethod string
+
+const (
+	CostFIFO    CostMethod = "FIFO"    // Cierra primero los lotes más antiguos
+	CostLIFO    CostMethod = "LIFO"    // Cierra primero los lotes más recientes
+	CostAverage CostMethod = "AVERAGE" // Precio promedio ponderado de la posición abierta
+)
+
+// MarkSource es el precio de mercado con que se valúa la posición abierta.
+type MarkSource string
+
+const (
+	MarkLast       MarkSource = "LA"  // Último operado
+	MarkMid        MarkSource = "MID" // Punto medio entre mejor BI y mejor OF
+	MarkSettlement MarkSource = "SE"  // Precio de ajuste (futuros)
+)
+
+// PnLOptions configura un PnLEngine.
+type PnLOptions struct {
+	Method CostMethod // Por defecto CostFIFO
+	Mark   MarkSource // Por defecto MarkLast
+	Buffer int        // Capacidad del canal de Updates (por defecto 256)
+}
+
+// PnL es el resultado de una cuenta en un instrumento.
+type PnL struct {
+	Account    string
+	Symbol     string
+	Market     model.Market
+	Position   int64   // Cantidad neta abierta (negativa si es vendida)
+	AvgPrice   float64 // Costo promedio de la posición abierta según el método
+	MarkPrice  float64 // 0 si todavía no hay precio de mercado
+	Multiplier float64 // ContractMultiplier * PriceConvertionFactor
+	Realized   float64
+	Unrealized float64
+	UpdatedAt  time.Time
+}
+
+// Total devuelve Realized + Unrealized.
+func (p PnL) Total() float64 { return p.Realized + p.Unrealized }
+
+// PnLEngine calcula el PnL realizado y no realizado en tiempo real a partir de los
+// fills de los Execution Reports y los precios de SubscribeMarketData.
+//
+// Cada fill y cada precio que cambia la valuación publica un PnL en Updates; si el
+// canal está lleno la actualización se descarta (Positions siempre tiene el estado).
+//
+// Ejemplo:
+//
+//	engine := rofex.NewPnLEngine(client, rofex.PnLOptions{Method: rofex.CostAverage, Mark: rofex.MarkMid})
+//	reports, _ := client.SubscribeOrderReport(ctx, account, false)
+//	md, _ := client.SubscribeMarketData(ctx, symbols, []model.MDEntry{model.MDBids, model.MDOffers}, 1, model.MarketROFEX)
+//	go engine.Run(ctx, reports.Events, md.Events)
+//	for p := range engine.Updates() {
+//		fmt.Println(p.Account, p.Symbol, p.Realized, p.Unrealized)
+//	}
+type PnLEngine struct {
+	client *Client
+	opts   PnLOptions
+
+	mu        sync.Mutex
+	positions map[pnlKey]*pnlPosition
+	marks     map[model.InstrumentID]float64
+	mults     map[model.InstrumentID]float64 // Último multiplicador obtenido de Instrument
+	execs     map[string]time.Time           // execId -> día de mercado del fill
+	day       time.Time                      // Día de mercado más reciente con fills
+	updates   chan PnL
+}
+
+type pnlKey struct {
+	account string
+	id      model.InstrumentID
+}
+
+// pnlPosition son los lotes abiertos de una cuenta en un instrumento, todos del mismo lado.
+type pnlPosition struct {
+	lots       []pnlLot
+	realized   float64
+	multiplier float64
+	updatedAt  time.Time
+}
+
+type pnlLot struct {
+	qty   int64 // Con signo: positiva comprada, negativa vendida
+	price float64
+}
+
+// NewPnLEngine crea un PnLEngine. El cliente se usa para obtener el multiplicador de
+// cada instrumento (Instrument); con c nil el multiplicador es 1.
+func NewPnLEngine(c *Client, opts PnLOptions) *PnLEngine {
+	if opts.Method == "" {
+		opts.Method = CostFIFO
+	}
+	if opts.Mark == "" {
+		opts.Mark = MarkLast
+	}
+	if opts.Buffer <= 0 {
+		opts.Buffer = 256
+	}
+	return &PnLEngine{
+		client:    c,
+		opts:      opts,
+		positions: make(map[pnlKey]*pnlPosition),
+		marks:     make(map[model.InstrumentID]float64),
+		mults:     make(map[model.InstrumentID]float64),
+		execs:     make(map[string]time.Time),
+		updates:   make(chan PnL, opts.Buffer),
+	}
+}
+
+// Updates devuelve el canal de actualizaciones de PnL.
+func (e *PnLEngine) Updates() <-chan PnL { return e.updates }
+
+// Run consume reports y md hasta que ctx se cancele o ambos canales se cierren.
+// Cualquiera de los dos puede ser nil.
+func (e *PnLEngine) Run(ctx context.Context, reports <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) {
+	for reports != nil || md != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-reports:
+			if !ok {
+				reports = nil
+				continue
+			}
+			e.OnOrderReport(ctx, ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			e.OnMarketData(ev)
+		}
+	}
+}
+
+// OnOrderReport aplica el fill de un Execution Report, si lo tiene.
+func (e *PnLEngine) OnOrderReport(ctx context.Context, ev *model.OrderReportEvent) {
+	if f, ok := fillFromReport(ev); ok {
+		e.OnFill(ctx, f)
+	}
+}
+
+// OnFill aplica un fill a la posición de su cuenta e instrumento. Los fills con un
+// execId ya aplicado se ignoran; los execId se recuerdan hasta que llega un fill de
+// un día de mercado posterior.
+func (e *PnLEngine) OnFill(ctx context.Context, f Fill) {
+	if f.ExecID == "" || f.Qty <= 0 {
+		return
+	}
+	if f.Market == "" {
+		f.Market = model.MarketROFEX
+	}
+	id := model.InstrumentID{Symbol: f.Symbol, MarketID: string(f.Market)}
+	mult, known := e.multiplier(ctx, id)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, dup := e.execs[f.ExecID]; dup {
+		return
+	}
+	at := f.Time
+	if at.IsZero() {
+		at = time.Now()
+	}
+	day := marketDay(at)
+	if day.After(e.day) {
+		e.day = day
+		for execID, d := range e.execs {
+			if d.Before(day) {
+				delete(e.execs, execID)
+			}
+		}
+	}
+	e.execs[f.ExecID] = day
+	if known {
+		e.mults[id] = mult
+	} else if last, ok := e.mults[id]; ok {
+		mult = last
+	}
+	k := pnlKey{account: f.Account, id: id}
+	pos, ok := e.positions[k]
+	if !ok {
+		pos = &pnlPosition{}
+		e.positions[k] = pos
+	}
+	pos.multiplier = mult
+	qty := f.Qty
+	if f.Side == model.Sell {
+		qty = -qty
+	}
+	pos.apply(qty, f.Price, e.opts.Method)
+	pos.updatedAt = f.Time
+	e.publishLocked(k, pos)
+}
+
+// OnMarketData actualiza el precio de valuación del instrumento y publica el PnL de
+// las posiciones afectadas. Los eventos sin la entry de MarkSource se ignoran.
+func (e *PnLEngine) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	px, ok := markPrice(ev.MarketData, e.opts.Mark)
+	if !ok {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.marks[ev.InstrumentID] == px {
+		return
+	}
+	e.marks[ev.InstrumentID] = px
+	now := time.Now()
+	for k, pos := range e.positions {
+		if k.id == ev.InstrumentID && len(pos.lots) > 0 {
+			pos.updatedAt = now
+			e.publishLocked(k, pos)
+		}
+	}
+}
+
+// Positions devuelve el PnL de todas las posiciones, ordenado por cuenta y símbolo.
+func (e *PnLEngine) Positions() []PnL {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]PnL, 0, len(e.positions))
+	for k, pos := range e.positions {
+		out = append(out, e.pnlLocked(k, pos))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Account != out[j].Account {
+			return out[i].Account < out[j].Account
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out
+}
+
+// PnL devuelve el PnL de una cuenta en un instrumento.
+func (e *PnLEngine) PnL(account, symbol string, market model.Market) (PnL, bool) {
+	if market == "" {
+		market = model.MarketROFEX
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	k := pnlKey{account: account, id: model.InstrumentID{Symbol: symbol, MarketID: string(market)}}
+	pos, ok := e.positions[k]
+	if !ok {
+		return PnL{}, false
+	}
+	return e.pnlLocked(k, pos), true
+}
+
+func (e *PnLEngine) pnlLocked(k pnlKey, pos *pnlPosition) PnL {
+	p := PnL{
+		Account:    k.account,
+		Symbol:     k.id.Symbol,
+		Market:     model.Market(k.id.MarketID),
+		Multiplier: pos.multiplier,
+		Realized:   pos.realized,
+		MarkPrice:  e.marks[k.id],
+		UpdatedAt:  pos.updatedAt,
+	}
+	var cost float64
+	for _, l := range pos.lots {
+		p.Position += l.qty
+		cost += float64(l.qty) * l.price
+	}
+	if p.Position != 0 {
+		p.AvgPrice = cost / float64(p.Position)
+		if p.MarkPrice != 0 {
+			p.Unrealized = (p.MarkPrice*float64(p.Position) - cost) * pos.multiplier
+		}
+	}
+	return p
+}
+
+func (e *PnLEngine) publishLocked(k pnlKey, pos *pnlPosition) {
+	select {
+	case e.updates <- e.pnlLocked(k, pos):
+	default:
+		if e.client != nil && e.client.logger != nil {
+			e.client.logger.Warn("pnl update dropped - channel full", slog.String("account", k.account), slog.String("symbol", k.id.Symbol))
+		}
+	}
+}
+
+// multiplier devuelve ContractMultiplier * PriceConvertionFactor del instrumento y si
+// se obtuvo de Instrument. Si la consulta falla devuelve 1 y false: OnFill conserva el
+// último multiplicador conocido del instrumento y lo vuelve a intentar en el próximo fill.
+func (e *PnLEngine) multiplier(ctx context.Context, id model.InstrumentID) (float64, bool) {
+	if e.client == nil {
+		return 1, false
+	}
+	inst, err := e.client.Instrument(ctx, id.Symbol, model.Market(id.MarketID))
+	if err != nil {
+		if e.client.logger != nil {
+			e.client.logger.Warn("pnl: instrument lookup failed", slog.String("symbol", id.Symbol), slog.Any("err", err))
+		}
+		return 1, false
+	}
+	return contractFactor(&inst), true
+}
+
+// apply suma un lote con signo: primero cierra lotes del lado contrario según el
+// método y, si sobra cantidad, abre un lote nuevo.
+func (p *pnlPosition) apply(qty int64, price float64, method CostMethod) {
+	for qty != 0 && len(p.lots) > 0 && (p.lots[0].qty > 0) != (qty > 0) {
+		i := 0
+		if method == CostLIFO {
+			i = len(p.lots) - 1
+		}
+		l := &p.lots[i]
+		closed := min(abs64(qty), abs64(l.qty))
+		if l.qty < 0 {
+			closed = -closed
+		}
+		// closed tiene el signo del lote: long gana si price > costo, short si price < costo
+		p.realized += float64(closed) * (price - l.price) * p.multiplier
+		l.qty -= closed
+		qty += closed
+		if l.qty == 0 {
+			p.lots = append(p.lots[:i], p.lots[i+1:]...)
+		}
+	}
+	if qty == 0 {
+		return
+	}
+	if method == CostAverage && len(p.lots) > 0 {
+		l := &p.lots[0]
+		total := l.qty + qty
+		l.price = (float64(l.qty)*l.price + float64(qty)*price) / float64(total)
+		l.qty = total
+		return
+	}
+	p.lots = append(p.lots, pnlLot{qty: qty, price: price})
+}
+
+// markPrice obtiene el precio de valuación de md según source.
+func markPrice(md model.MarketData, source MarkSource) (float64, bool) {
+	switch source {
+	case MarkMid:
+		if len(md.Bids) == 0 || len(md.Offers) == 0 {
+			return 0, false
+		}
+		return (md.Bids[0].Price + md.Offers[0].Price) / 2, true
+	case MarkSettlement:
+		if md.SE == nil || md.SE.Price == nil {
+			return 0, false
+		}
+		return *md.SE.Price, true
+	default:
+		if md.LA == nil || md.LA.Price == nil {
+			return 0, false
+		}
+		return *md.LA.Price, true
+	}
+}
+
+// contractFactor devuelve ContractMultiplier * PriceConvertionFactor del instrumento;
+// los valores ausentes o en cero cuentan como 1.
+func contractFactor(inst *model.Instrument) float64 {
+	mult, pcf := 1.0, 1.0
+	if inst != nil {
+		if inst.ContractMultiplier != nil && *inst.ContractMultiplier != 0 {
+			mult = *inst.ContractMultiplier
+		}
+		if inst.PriceConvertionFactor != nil && *inst.PriceConvertionFactor != 0 {
+			pcf = *inst.PriceConvertionFactor
+		}
+	}
+	return mult * pcf
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,106 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestPnLEngine_CostMethods(t *testing.T) {
+	// Constantes
+	ctx := context.Background()
+	id := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	mult, pcf := 1000.0, 1.0
+	c, _ := NewClient(WithBaseURL("http://127.0.0.1:0/"))
+	c.CacheInstrument(model.Instrument{InstrumentID: id, ContractMultiplier: &mult, PriceConvertionFactor: &pcf})
+
+	n := 0
+	report := func(side string, qty int, px float64) *model.OrderReportEvent {
+		n++
+		execID := fmt.Sprintf("e%d", n)
+		return &model.OrderReportEvent{Type: model.WSMessageOrderReport, OrderReport: model.OrderDetails{
+			ClOrdID: fmt.Sprintf("o%d", n), Status: "FILLED", Side: side, InstrumentID: id,
+			AccountID: &model.AccountReference{ID: "REM6771"}, ExecID: &execID, LastQty: &qty, LastPx: &px,
+		}}
+	}
+	mid := &model.MarketDataEvent{InstrumentID: id, MarketData: model.MarketData{
+		Bids: []model.BookLevel{{Price: 114, Size: 1}}, Offers: []model.BookLevel{{Price: 116, Size: 1}},
+	}}
+
+	cases := []struct {
+		method               CostMethod
+		realized, unrealized float64
+		avg                  float64
+	}{
+		{CostFIFO, 50000, 5000, 110},
+		{CostLIFO, 40000, 15000, 100},
+		{CostAverage, 45000, 10000, 105},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.method), func(t *testing.T) {
+			e := NewPnLEngine(c, PnLOptions{Method: tc.method, Mark: MarkMid})
+			e.OnOrderReport(ctx, report("BUY", 2, 100))
+			e.OnOrderReport(ctx, report("BUY", 2, 110))
+			sell := report("SELL", 3, 120)
+			e.OnOrderReport(ctx, sell)
+			e.OnOrderReport(ctx, sell) // execId repetido
+			e.OnMarketData(mid)
+
+			p, ok := e.PnL("REM6771", id.Symbol, "")
+			if !ok || p.Position != 1 || p.Realized != tc.realized || p.Unrealized != tc.unrealized || p.AvgPrice != tc.avg || p.MarkPrice != 115 || p.Multiplier != 1000 {
+				t.Fatalf("pnl: %+v", p)
+			}
+			if got := len(e.Updates()); got != 4 {
+				t.Fatalf("updates: %d", got)
+			}
+		})
+	}
+
+	// Cambio de lado: vender 2 cierra el lote restante y abre una posición vendida
+	e := NewPnLEngine(c, PnLOptions{Mark: MarkMid})
+	e.OnOrderReport(ctx, report("BUY", 1, 110))
+	e.OnMarketData(mid)
+	e.OnOrderReport(ctx, report("SELL", 2, 100))
+	p, _ := e.PnL("REM6771", id.Symbol, model.MarketROFEX)
+	if p.Position != -1 || p.Realized != -10000 || p.AvgPrice != 100 || p.Unrealized != -15000 || p.Total() != -25000 {
+		t.Fatalf("flip: %+v", p)
+	}
+}
+
+func TestPnLEngine_MultiplierAndExecPruning(t *testing.T) {
+	// Constantes
+	ctx := context.Background()
+	id := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	mult := 1000.0
+	c, _ := NewClient(WithBaseURL("http://127.0.0.1:0/"))
+	c.CacheInstrument(model.Instrument{InstrumentID: id, ContractMultiplier: &mult})
+	day1 := time.Date(2026, 3, 2, 11, 0, 0, 0, MarketLocation)
+	fill := func(execID, account string, at time.Time) Fill {
+		return Fill{ExecID: execID, Account: account, Symbol: id.Symbol, Side: model.Buy, Qty: 1, Price: 100, Time: at}
+	}
+
+	e := NewPnLEngine(c, PnLOptions{})
+	e.OnFill(ctx, fill("e1", "A", day1))
+	// Sin cache ni API la consulta falla: se usa el último multiplicador conocido
+	c.instruments = newInstrumentCache()
+	e.OnFill(ctx, fill("e2", "B", day1))
+	if p, _ := e.PnL("B", id.Symbol, ""); p.Multiplier != 1000 {
+		t.Fatalf("multiplier: %+v", p)
+	}
+
+	e.OnFill(ctx, fill("e1", "A", day1))
+	e.OnFill(ctx, fill("e3", "A", day1.AddDate(0, 0, 1)))
+	e.mu.Lock()
+	_, kept := e.execs["e1"]
+	n := len(e.execs)
+	e.mu.Unlock()
+	if kept || n != 1 {
+		t.Fatalf("execs not pruned on day change: %d", n)
+	}
+	if p, _ := e.PnL("A", id.Symbol, ""); p.Position != 2 {
+		t.Fatalf("position: %+v", p)
+	}
+}
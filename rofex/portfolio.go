@@ -0,0 +1,305 @@
+package rofex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// PortfolioOptions configura un PortfolioAggregator.
+type PortfolioOptions struct {
+	Accounts []string      // Cuentas a consolidar; vacío = todas las de Accounts
+	Interval time.Duration // Período de actualización de Run (por defecto 30s)
+}
+
+// PortfolioPosition es la posición consolidada de un instrumento en todas las cuentas.
+type PortfolioPosition struct {
+	Symbol         string
+	Underlying     string // Ver Underlying
+	ContractType   model.ContractType
+	Currency       string
+	BuySize        float64
+	SellSize       float64
+	NetSize        float64
+	MarketPrice    float64
+	Multiplier     float64            // ContractMultiplier * PriceConversionFactor (0 sin DetailedPosition)
+	Notional       float64            // NetSize * MarketPrice * Multiplier
+	DailyDiff      float64            // Diferencia diaria en la moneda del instrumento
+	DailyDiffPlain float64            // Diferencia diaria convertida a pesos
+	ByAccount      map[string]float64 // Tamaño neto por cuenta
+}
+
+// UnderlyingExposure consolida las posiciones de un subyacente en una moneda.
+type UnderlyingExposure struct {
+	Underlying string
+	Currency   string
+	Symbols    []string
+	Notional   float64
+	DailyDiff  float64
+}
+
+// Portfolio es la vista consolidada de las posiciones de varias cuentas.
+type Portfolio struct {
+	Accounts       []string
+	Positions      []PortfolioPosition  // Por instrumento, ordenadas por símbolo
+	Underlyings    []UnderlyingExposure // Por subyacente y moneda
+	Notional       map[string]float64   // Por moneda
+	DailyDiff      map[string]float64   // Por moneda
+	DailyDiffPlain float64              // Total convertido a pesos
+	Errors         map[string]error     // Cuentas que no se pudieron consultar
+	UpdatedAt      time.Time
+}
+
+// PortfolioAggregator consolida AccountPosition y DetailedPosition de varias cuentas
+// por instrumento y por subyacente.
+//
+// AccountPosition aporta los tamaños comprados y vendidos; DetailedPosition, el precio
+// de mercado, multiplicador, factor de conversión, moneda y diferencias diarias.
+//
+// La API admite una consulta de reportes cada 5 segundos por endpoint: Refresh consulta
+// las cuentas de a una y espacia las llamadas a cada endpoint, por lo que tarda unos
+// 5s * (len(Accounts) - 1). Interval debería superar ese tiempo.
+//
+// Ejemplo:
+//
+//	agg := rofex.NewPortfolioAggregator(client, rofex.PortfolioOptions{Interval: time.Minute})
+//	go agg.Run(ctx, func(p rofex.Portfolio) {
+//		for _, pos := range p.Positions {
+//			fmt.Println(pos.Symbol, pos.NetSize, pos.Notional, pos.Currency)
+//		}
+//	})
+type PortfolioAggregator struct {
+	client *Client
+	opts   PortfolioOptions
+
+	positions *riskPacer // AccountPosition
+	detailed  *riskPacer // DetailedPosition
+
+	mu   sync.RWMutex
+	last Portfolio
+}
+
+// NewPortfolioAggregator crea un PortfolioAggregator.
+func NewPortfolioAggregator(c *Client, opts PortfolioOptions) *PortfolioAggregator {
+	if opts.Interval <= 0 {
+		opts.Interval = 30 * time.Second
+	}
+	return &PortfolioAggregator{client: c, opts: opts, positions: newRiskPacer(), detailed: newRiskPacer()}
+}
+
+// Snapshot devuelve el último Portfolio calculado por Refresh o Run.
+func (a *PortfolioAggregator) Snapshot() Portfolio {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.last
+}
+
+// Run actualiza el Portfolio cada Interval hasta que ctx se cancele y llama a onUpdate
+// (si no es nil) con cada resultado. Los errores por cuenta quedan en Portfolio.Errors.
+func (a *PortfolioAggregator) Run(ctx context.Context, onUpdate func(Portfolio)) error {
+	ticker := time.NewTicker(a.opts.Interval)
+	defer ticker.Stop()
+	for {
+		p, err := a.Refresh(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && a.client.logger != nil {
+			a.client.logger.Warn("portfolio refresh failed", slog.Any("err", err))
+		}
+		if onUpdate != nil {
+			onUpdate(p)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// accountPositions son las respuestas de una cuenta.
+type accountPositions struct {
+	positions []model.Position
+	detailed  model.DetailedPosition
+	err       error
+}
+
+// Refresh consulta las cuentas y recalcula el Portfolio. Si alguna cuenta falla, el
+// Portfolio consolida las demás y el error las enumera.
+func (a *PortfolioAggregator) Refresh(ctx context.Context) (Portfolio, error) {
+	accounts := a.opts.Accounts
+	if len(accounts) == 0 {
+		res, err := a.client.Accounts(ctx)
+		if err != nil {
+			return Portfolio{}, fmt.Errorf("portfolio: accounts: %w", err)
+		}
+		accounts = res.Names()
+	}
+
+	results := make([]accountPositions, len(accounts))
+	for i, account := range accounts {
+		results[i] = a.fetch(ctx, account)
+	}
+
+	p := consolidate(accounts, results)
+	a.mu.Lock()
+	a.last = p
+	a.mu.Unlock()
+
+	var errs []error
+	for _, account := range p.Accounts {
+		if err := p.Errors[account]; err != nil {
+			errs = append(errs, fmt.Errorf("portfolio: account %s: %w", account, err))
+		}
+	}
+	return p, errors.Join(errs...)
+}
+
+func (a *PortfolioAggregator) fetch(ctx context.Context, account string) accountPositions {
+	if err := a.positions.wait(ctx); err != nil {
+		return accountPositions{err: err}
+	}
+	pos, err := a.client.AccountPosition(ctx, account)
+	if err == nil {
+		err = responseStatusError(pos.Status)
+	}
+	if err != nil {
+		return accountPositions{err: fmt.Errorf("positions: %w", err)}
+	}
+	if err := a.detailed.wait(ctx); err != nil {
+		return accountPositions{err: err}
+	}
+	det, err := a.client.DetailedPosition(ctx, account)
+	if err == nil {
+		err = responseStatusError(det.Status)
+	}
+	if err != nil {
+		return accountPositions{err: fmt.Errorf("detailed position: %w", err)}
+	}
+	return accountPositions{positions: pos.Positions, detailed: det.DetailedPosition}
+}
+
+// consolidate combina las posiciones de todas las cuentas por instrumento y subyacente.
+func consolidate(accounts []string, results []accountPositions) Portfolio {
+	p := Portfolio{
+		Accounts:  accounts,
+		Notional:  make(map[string]float64),
+		DailyDiff: make(map[string]float64),
+		Errors:    make(map[string]error),
+		UpdatedAt: time.Now(),
+	}
+	bySymbol := make(map[string]*PortfolioPosition)
+	get := func(symbol string) *PortfolioPosition {
+		pp, ok := bySymbol[symbol]
+		if !ok {
+			pp = &PortfolioPosition{Symbol: symbol, Underlying: Underlying(symbol), ByAccount: make(map[string]float64)}
+			bySymbol[symbol] = pp
+		}
+		return pp
+	}
+
+	for i, account := range accounts {
+		r := results[i]
+		if r.err != nil {
+			p.Errors[account] = r.err
+			continue
+		}
+		sized := make(map[string]bool)
+		for _, pos := range r.positions {
+			symbol := positionSymbol(pos)
+			pp := get(symbol)
+			pp.BuySize += pos.BuySize
+			pp.SellSize += pos.SellSize
+			pp.ByAccount[account] += pos.BuySize - pos.SellSize
+			sized[symbol] = true
+		}
+		for _, byInst := range r.detailed.Report {
+			for symbol, inst := range byInst {
+				for _, item := range inst.DetailedPositions {
+					sym := symbol
+					if item.SymbolReference != "" {
+						sym = item.SymbolReference
+					}
+					pp := get(sym)
+					if !sized[sym] {
+						// Sin AccountPosition para el instrumento: se usa el tamaño actual del detalle
+						pp.BuySize += item.BuyCurrentSize
+						pp.SellSize += item.SellCurrentSize
+						pp.ByAccount[account] += item.TotalCurrentSize
+					}
+					pp.ContractType, pp.Currency, pp.MarketPrice = item.Type(), item.Currency, item.MarketPrice
+					pp.DailyDiff += item.DetailedDailyDiff.TotalDailyDiff
+					pp.DailyDiffPlain += item.DetailedDailyDiff.TotalDailyDiffPlain
+					pp.Multiplier = item.Multiplier()
+				}
+			}
+		}
+	}
+
+	type ukey struct{ underlying, currency string }
+	byUnderlying := make(map[ukey]*UnderlyingExposure)
+	for _, pp := range bySymbol {
+		pp.NetSize = pp.BuySize - pp.SellSize
+		pp.Notional = pp.NetSize * pp.MarketPrice * pp.Multiplier
+		p.Positions = append(p.Positions, *pp)
+		p.Notional[pp.Currency] += pp.Notional
+		p.DailyDiff[pp.Currency] += pp.DailyDiff
+		p.DailyDiffPlain += pp.DailyDiffPlain
+
+		k := ukey{pp.Underlying, pp.Currency}
+		u, ok := byUnderlying[k]
+		if !ok {
+			u = &UnderlyingExposure{Underlying: k.underlying, Currency: k.currency}
+			byUnderlying[k] = u
+		}
+		u.Symbols = append(u.Symbols, pp.Symbol)
+		u.Notional += pp.Notional
+		u.DailyDiff += pp.DailyDiff
+	}
+	sort.Slice(p.Positions, func(i, j int) bool { return p.Positions[i].Symbol < p.Positions[j].Symbol })
+	for _, u := range byUnderlying {
+		sort.Strings(u.Symbols)
+		p.Underlyings = append(p.Underlyings, *u)
+	}
+	sort.Slice(p.Underlyings, func(i, j int) bool {
+		if p.Underlyings[i].Underlying != p.Underlyings[j].Underlying {
+			return p.Underlyings[i].Underlying < p.Underlyings[j].Underlying
+		}
+		return p.Underlyings[i].Currency < p.Underlyings[j].Currency
+	})
+	return p
+}
+
+func positionSymbol(pos model.Position) string {
+	if pos.Symbol != "" {
+		return pos.Symbol
+	}
+	if pos.TradingSymbol != "" {
+		return pos.TradingSymbol
+	}
+	return pos.Instrument.SymbolReference
+}
+
+// Underlying devuelve el subyacente de un símbolo: el prefijo hasta "/" para futuros y
+// opciones ("DLR/DIC23" -> "DLR", "SOJ.ROS/MAY23 380 C" -> "SOJ.ROS") y la especie para
+// símbolos de BYMA ("MERV - XMEV - GGAL - 48hs" -> "GGAL").
+func Underlying(symbol string) string {
+	if parts := strings.Split(symbol, " - "); len(parts) >= 3 {
+		return strings.TrimSpace(parts[2])
+	}
+	if i := strings.Index(symbol, "/"); i > 0 {
+		return symbol[:i]
+	}
+	if i := strings.Index(symbol, " "); i > 0 {
+		return symbol[:i]
+	}
+	return symbol
+}
@@ -0,0 +1,104 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPortfolioAggregator_Refresh(t *testing.T) {
+	// Constantes
+	item := func(symbol, currency string, price, multiplier, buy, sell, diff, plain float64) map[string]any {
+		return map[string]any{
+			"symbolReference": symbol, "contractType": "FUTURE", "currency": currency, "marketPrice": price,
+			"contractMultiplier": multiplier, "priceConversionFactor": 1,
+			"buyCurrentSize": buy, "sellCurrentSize": sell, "totalCurrentSize": buy - sell,
+			"detailedDailyDiff": map[string]any{"totalDailyDiff": diff, "totalDailyDiffPlain": plain},
+		}
+	}
+	report := func(items ...map[string]any) map[string]any {
+		byInst := map[string]any{}
+		for _, it := range items {
+			byInst[it["symbolReference"].(string)] = map[string]any{"detailedPositions": []any{it}}
+		}
+		return map[string]any{"status": "OK", "detailedPosition": map[string]any{"report": map[string]any{"FUTURE": byInst}}}
+	}
+	position := func(symbol string, buy, sell float64) map[string]any {
+		return map[string]any{"symbol": symbol, "buySize": buy, "sellSize": sell}
+	}
+	responses := map[string]any{
+		"/rest/accounts":                     map[string]any{"accounts": []any{map[string]any{"name": "A"}, map[string]any{"name": "B"}, map[string]any{"name": "C"}, map[string]any{"name": "D"}}},
+		"/rest/risk/position/getPositions/A": map[string]any{"status": "OK", "positions": []any{position("DLR/MAR26", 10, 2)}},
+		"/rest/risk/position/getPositions/B": map[string]any{"status": "OK", "positions": []any{position("DLR/MAR26", 0, 3), position("DLR/ABR26", 5, 0)}},
+		"/rest/risk/position/getPositions/D": map[string]any{"status": "OK", "positions": []any{position("DLR/MAR26", 100, 0)}},
+		"/rest/risk/detailedPosition/D":      map[string]any{"status": "ERROR", "description": "unavailable"},
+		"/rest/risk/detailedPosition/A":      report(item("DLR/MAR26", "ARS", 1000, 1000, 10, 2, 500, 500)),
+		"/rest/risk/detailedPosition/B": report(
+			item("DLR/MAR26", "ARS", 1000, 1000, 0, 3, -100, -100),
+			item("DLR/ABR26", "ARS", 1050, 1000, 5, 0, 200, 200),
+			item("SOJ.ROS/MAY23 380 C", "USD G", 380.5, 1, 0, 2, -100, -16777),
+		),
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, ok := responses[r.URL.Path]
+		if !ok {
+			if !strings.HasSuffix(r.URL.Path, "/C") {
+				t.Errorf("unexpected path: %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	agg := NewPortfolioAggregator(c, PortfolioOptions{})
+	agg.positions.interval, agg.detailed.interval = 20*time.Millisecond, 20*time.Millisecond
+	start := time.Now()
+	p, err := agg.Refresh(context.Background())
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Fatalf("risk requests not paced: 4 accounts in %v", elapsed)
+	}
+	// C responde HTTP 400 y D status ERROR: ninguna aporta posiciones
+	if err == nil || p.Errors["C"] == nil || p.Errors["D"] == nil || len(p.Errors) != 2 {
+		t.Fatalf("want errors for accounts C and D, got %v (%v)", err, p.Errors)
+	}
+
+	if len(p.Positions) != 3 {
+		t.Fatalf("positions: %+v", p.Positions)
+	}
+	mar := p.Positions[1]
+	if mar.Symbol != "DLR/MAR26" || mar.NetSize != 5 || mar.Notional != 5e6 || mar.ByAccount["A"] != 8 || mar.ByAccount["B"] != -3 || mar.DailyDiff != 400 {
+		t.Fatalf("DLR/MAR26: %+v", mar)
+	}
+	if soj := p.Positions[2]; soj.NetSize != -2 || soj.Notional != -761 || soj.Underlying != "SOJ.ROS" {
+		t.Fatalf("detail-only position: %+v", soj)
+	}
+	if len(p.Underlyings) != 2 || p.Underlyings[0].Underlying != "DLR" || p.Underlyings[0].Notional != 10.25e6 || len(p.Underlyings[0].Symbols) != 2 {
+		t.Fatalf("underlyings: %+v", p.Underlyings)
+	}
+	if p.DailyDiff["ARS"] != 600 || p.DailyDiff["USD G"] != -100 || p.DailyDiffPlain != -16177 || p.Notional["USD G"] != -761 {
+		t.Fatalf("totals: notional=%v diff=%v plain=%v", p.Notional, p.DailyDiff, p.DailyDiffPlain)
+	}
+	if agg.Snapshot().UpdatedAt != p.UpdatedAt {
+		t.Fatalf("snapshot not stored")
+	}
+}
+
+func TestUnderlying(t *testing.T) {
+	for symbol, want := range map[string]string{
+		"DLR/DIC23":                 "DLR",
+		"SOJ.ROS/MAY23 380 C":       "SOJ.ROS",
+		"MERV - XMEV - GGAL - 48hs": "GGAL",
+		"GGAL":                      "GGAL",
+	} {
+		if got := Underlying(symbol); got != want {
+			t.Errorf("Underlying(%q) = %q, want %q", symbol, got, want)
+		}
+	}
+}
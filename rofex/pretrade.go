@@ -0,0 +1,60 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// Controles comunes a todos los métodos de ingreso de órdenes (REST y WebSocket).
+
+// beforeNewOrder se ejecuta antes de enviar una orden nueva ya validada. La reserva
+// devuelta (nil sin RiskManager) debe confirmarse con commit si la API acepta la orden
+// o devolverse con release si el envío falla.
+func (c *Client) beforeNewOrder(ctx context.Context, o NewOrder) (*riskReservation, error) {
+	if c.halted.Load() {
+		return nil, ErrTradingHalted
+	}
+	return c.preTradeRisk(ctx, o, "")
+}
+
+// beforeReplaceOrder se ejecuta antes de reemplazar una orden. Si la orden no es
+// conocida por el RiskManager (aún no llegó su Execution Report) se consulta con
+// OrderStatus para aplicar igualmente los límites.
+func (c *Client) beforeReplaceOrder(ctx context.Context, clOrdID, proprietary string, newQty *int64, newPrice *float64) (*riskReservation, error) {
+	if c.halted.Load() {
+		return nil, ErrTradingHalted
+	}
+	if c.risk == nil {
+		return nil, nil
+	}
+	ro, ok := c.risk.lookupOrder(clOrdID)
+	if !ok {
+		res, err := c.OrderStatus(ctx, clOrdID, proprietary)
+		if err != nil {
+			return nil, fmt.Errorf("risk check: order status: %w", err)
+		}
+		if strings.EqualFold(res.Status, "ERROR") || res.Order.InstrumentID.Symbol == "" {
+			return nil, fmt.Errorf("risk check: order %s not found", clOrdID)
+		}
+		ro = riskOrderFromReport(res.Order.AsReport())
+	}
+	o := NewOrder{
+		Symbol:  ro.symbol,
+		Market:  ro.market,
+		Side:    ro.side,
+		Type:    model.OrderTypeLimit,
+		Qty:     ro.leaves,
+		Price:   ro.price,
+		Account: ro.account,
+	}
+	if newQty != nil {
+		o.Qty = *newQty
+	}
+	if newPrice != nil {
+		o.Price = newPrice
+	}
+	return c.preTradeRisk(ctx, o, clOrdID)
+}
@@ -3,6 +3,8 @@ package rofex
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -89,16 +91,20 @@ func TestSendOrder_GTD_WithExpireDate(t *testing.T) {
 	okStatus := "OK"
 	symbol := "DLR/DIC23"
 	account := "REM2747"
-	expire := "20230505"
+	expire := nextTradingDay(time.Now())
 	price := 182.5
 
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/instruments/detail" {
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": okStatus, "instrument": map[string]any{"maturityDate": "29991231"}})
+			return
+		}
 		if !strings.HasPrefix(r.URL.Path, "/rest/order/newSingleOrder") {
 			t.Fatalf("unexpected path: %s", r.URL.Path)
 		}
 		q := r.URL.Query()
 		mustEq(t, q, "timeInForce", string(model.GoodTillDate))
-		mustEq(t, q, "expireDate", expire)
+		mustEq(t, q, "expireDate", expire.In(MarketLocation).Format("20060102"))
 		_ = json.NewEncoder(w).Encode(map[string]any{
 			"status": okStatus,
 			"order":  map[string]any{"clientId": "utfa3256548752365489", "proprietary": "api"},
@@ -118,7 +124,7 @@ func TestSendOrder_GTD_WithExpireDate(t *testing.T) {
 		Qty:        100,
 		Price:      &price,
 		TIF:        model.GoodTillDate,
-		ExpireDate: &expire,
+		ExpireDate: expire,
 		Account:    account,
 	})
 	if err != nil {
@@ -275,6 +281,94 @@ func TestMarketDataSnapshot_Decode_LA_CL_SE_Object(t *testing.T) {
 	}
 }
 
+func TestAccounts_ExtraFieldsAndCache(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/accounts":
+			_, _ = w.Write([]byte(`{"status":"OK","accounts":[
+				{"id":6771,"name":"REM6771","brokerId":284,"status":true,"description":"Cuenta demo","marketMember":"PMY","flags":{"dma":true}},
+				{"id":"A2","name":"REM6772","status":"INACTIVE","type":"CLIENT","currency":"ARS"}]}`))
+		case "/rest/risk/position/getPositions/" + account:
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "positions": []any{}})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx := context.Background()
+
+	// Sin listado en cache no se valida localmente
+	if err := c.validateAccount("OTRA"); err != nil {
+		t.Fatalf("validate before Accounts: %v", err)
+	}
+
+	res, err := c.Accounts(ctx)
+	if err != nil {
+		t.Fatalf("Accounts: %v", err)
+	}
+	acc, ok := res.ByName(account)
+	if !ok || acc.ID != "6771" || acc.BrokerID != "284" || acc.Status != "true" || !acc.IsActive() || acc.Description != "Cuenta demo" {
+		t.Fatalf("account: %+v", acc)
+	}
+	var extra map[string]any
+	if err := json.Unmarshal(acc.Extra, &extra); err != nil || len(extra) != 2 || extra["marketMember"] != "PMY" {
+		t.Fatalf("extra: %s (%v)", acc.Extra, err)
+	}
+	// Marshal vuelve a incluir los campos de Extra en el mismo objeto
+	b, err := json.Marshal(acc)
+	if err != nil {
+		t.Fatalf("marshal account: %v", err)
+	}
+	var back model.Account
+	if err := json.Unmarshal(b, &back); err != nil || back.ID != acc.ID || back.Name != acc.Name || back.Description != acc.Description || string(back.Extra) != string(acc.Extra) {
+		t.Fatalf("round trip: %s -> %+v (%v)", b, back, err)
+	}
+	if other, ok := res.ByID("A2"); !ok || other.IsActive() || other.Type != "CLIENT" || other.Extra != nil {
+		t.Fatalf("byID: %+v", other)
+	}
+	if names := res.Names(); len(names) != 2 || names[1] != "REM6772" {
+		t.Fatalf("names: %v", names)
+	}
+
+	// Con el listado en cache las cuentas desconocidas se rechazan sin llamar a la API
+	var ve *ValidationError
+	if _, err := c.AccountPosition(ctx, "OTRA"); !errors.As(err, &ve) || ve.Field != "account" {
+		t.Fatalf("AccountPosition unknown account: %v", err)
+	}
+	if _, err := c.SubscribeOrderReport(ctx, "OTRA", false); !errors.As(err, &ve) {
+		t.Fatalf("SubscribeOrderReport unknown account: %v", err)
+	}
+	price := 100.0
+	if _, err := c.SendOrder(ctx, NewOrder{Symbol: "DLR/MAR26", Side: model.Buy, Type: model.OrderTypeLimit, Qty: 1, Price: &price, Account: "OTRA"}); !errors.As(err, &ve) {
+		t.Fatalf("SendOrder unknown account: %v", err)
+	}
+	if _, err := c.AccountPosition(ctx, account); err != nil {
+		t.Fatalf("AccountPosition: %v", err)
+	}
+	if a, err := c.Account(ctx, "REM6772"); err != nil || a.Currency != "ARS" {
+		t.Fatalf("Account: %+v (%v)", a, err)
+	}
+
+	// Vencido el cache se deja de validar localmente y Account vuelve a consultar
+	c.accounts.mu.Lock()
+	c.accounts.loaded = time.Now().Add(-accountCacheTTL)
+	c.accounts.mu.Unlock()
+	if err := c.validateAccount("OTRA"); err != nil {
+		t.Fatalf("validate with expired cache: %v", err)
+	}
+	if _, err := c.Account(ctx, account); err != nil {
+		t.Fatalf("Account after expiry: %v", err)
+	}
+	if _, ok := c.accounts.get(); !ok {
+		t.Fatalf("cache not refreshed")
+	}
+}
+
 func TestRisk_GetPositions_Typed(t *testing.T) {
 	// Constantes
 	okStatus := "OK"
@@ -320,6 +414,82 @@ func TestRisk_GetPositions_Typed(t *testing.T) {
 	}
 }
 
+func TestRisk_DetailedPosition_Report(t *testing.T) {
+	// Constantes
+	account := "REM7374"
+	item := func(symbol, contractType, currency string, rate, mult, price, size, diff float64) map[string]any {
+		plain := diff
+		if rate != 0 {
+			plain = diff * rate
+		}
+		return map[string]any{
+			"symbolReference": symbol, "contractType": contractType, "currency": currency, "exchangeRate": rate,
+			"priceConversionFactor": 1, "contractMultiplier": mult, "marketPrice": price, "totalCurrentSize": size,
+			"detailedDailyDiff": map[string]any{"totalDailyDiff": diff, "totalDailyDiffPlain": plain},
+		}
+	}
+	group := func(items ...map[string]any) map[string]any {
+		out := map[string]any{}
+		for _, it := range items {
+			out[it["symbolReference"].(string)] = map[string]any{"detailedPositions": []any{it}}
+		}
+		return out
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "detailedPosition": map[string]any{
+			"account": account,
+			"report": map[string]any{
+				"FUTURE OPTION CALL": group(
+					item("SOJ.ROS/MAY23 380 C", "FUTURE_OPTION_CALL", "USD G", 167.77, 1, 380.5, -2, -100),
+					item("SOJ.ROS/ENE23 412 C", "FUTURE_OPTION_CALL", "USD G", 167.77, 1, 410, 3, 0),
+				),
+				"FUTURE": group(item("DLR/MAR26", "", "ARS", 0, 1000, 1000, 5, 2000)),
+			},
+		}})
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	res, err := c.DetailedPosition(context.Background(), account)
+	if err != nil {
+		t.Fatalf("DetailedPosition: %v", err)
+	}
+	d := res.DetailedPosition
+
+	inst, ct, ok := d.Instrument("SOJ.ROS/MAY23 380 C")
+	if !ok || ct != model.ContractFutureOptionCall || !ct.IsOption() || len(inst.DetailedPositions) != 1 {
+		t.Fatalf("Instrument: %+v %s %v", inst, ct, ok)
+	}
+	if _, _, ok := d.Instrument("GGAL"); ok {
+		t.Fatalf("unknown symbol found")
+	}
+
+	sorted := d.ByMarketValue()
+	if len(sorted) != 3 || sorted[0].SymbolReference != "DLR/MAR26" || sorted[1].SymbolReference != "SOJ.ROS/ENE23 412 C" {
+		t.Fatalf("ByMarketValue: %+v", sorted)
+	}
+	if fut := d.ByContractType(model.ContractFuture); len(fut) != 1 || fut[0].Type() != model.ContractFuture {
+		t.Fatalf("ByContractType: %+v", fut)
+	}
+	if usd := d.ByCurrency("USD G"); len(usd) != 2 {
+		t.Fatalf("ByCurrency: %+v", usd)
+	}
+
+	totals := d.TotalsByCurrency()
+	if len(totals) != 2 || totals[0].Currency != "ARS" || totals[0].MarketValue != 5e6 || totals[0].DailyDiffPlain != 2000 {
+		t.Fatalf("totals: %+v", totals)
+	}
+	if usd := totals[1]; usd.MarketValue != 469 || math.Abs(usd.MarketValuePlain-469*167.77) > 1e-6 || usd.DailyDiff != -100 {
+		t.Fatalf("USD G totals: %+v", usd)
+	}
+
+	rows := d.Rows()
+	if len(rows) != 3 || rows[0].Symbol != "DLR/MAR26" || rows[0].Account != account || rows[0].ContractType != model.ContractFuture || rows[0].Multiplier != 1000 || rows[0].ExchangeRate != 1 {
+		t.Fatalf("rows: %+v", rows)
+	}
+}
+
 func TestRisk_AccountReport_Typed(t *testing.T) {
 	// Constantes
 	okStatus := "OK"
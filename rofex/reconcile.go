@@ -0,0 +1,205 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// BreakKind clasifica una diferencia entre el Ledger y la Risk API.
+type BreakKind string
+
+const (
+	BreakMissingFill   BreakKind = "MISSING_FILL"   // La Risk API informa más cantidad que los fills locales
+	BreakQtyMismatch   BreakKind = "QTY_MISMATCH"   // Los fills locales suman más cantidad que la Risk API
+	BreakUnknownSymbol BreakKind = "UNKNOWN_SYMBOL" // El instrumento está solo de un lado
+)
+
+// PositionBreak es una diferencia de un instrumento entre los fills locales y AccountPosition.
+type PositionBreak struct {
+	Account    string
+	Symbol     string
+	Kind       BreakKind
+	LocalBuy   int64
+	LocalSell  int64
+	RemoteBuy  int64
+	RemoteSell int64
+}
+
+func (b PositionBreak) String() string {
+	return fmt.Sprintf("%s %s %s local=%d/%d remote=%d/%d", b.Kind, b.Account, b.Symbol, b.LocalBuy, b.LocalSell, b.RemoteBuy, b.RemoteSell)
+}
+
+// ReconcileReport es el resultado de conciliar una cuenta.
+type ReconcileReport struct {
+	Account    string
+	Time       time.Time
+	Matched    []string        // Instrumentos sin diferencias
+	Breaks     []PositionBreak // Ordenadas por símbolo
+	Backfilled int             // Fills agregados desde FilledOrders
+}
+
+// OK indica si la cuenta concilia sin diferencias.
+func (r ReconcileReport) OK() bool { return len(r.Breaks) == 0 }
+
+// String devuelve el reporte en texto, una diferencia por línea.
+func (r ReconcileReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "reconciliation %s at %s: %d matched, %d breaks, %d backfilled\n",
+		r.Account, r.Time.In(MarketLocation).Format(time.RFC3339), len(r.Matched), len(r.Breaks), r.Backfilled)
+	for _, br := range r.Breaks {
+		b.WriteString("  " + br.String() + "\n")
+	}
+	return b.String()
+}
+
+// ReconcileOptions configura un Reconciler.
+type ReconcileOptions struct {
+	Accounts []string      // Cuentas a conciliar en Run
+	Interval time.Duration // Período de Run (por defecto 1 minuto)
+	Backfill bool          // Ante diferencias, cargar en el Ledger los fills faltantes de FilledOrders
+	// From es el comienzo de los fills locales que se comparan; por defecto el comienzo
+	// del día de mercado. Debe coincidir con el período que cubre AccountPosition.
+	From time.Time
+}
+
+// Reconciler compara las cantidades compradas y vendidas por instrumento de un Ledger
+// con las de AccountPosition y clasifica las diferencias.
+//
+// Ejemplo:
+//
+//	rec := rofex.NewReconciler(client, ledger, rofex.ReconcileOptions{Backfill: true})
+//	report, err := rec.Reconcile(ctx, "REM6771")
+//	if err == nil && !report.OK() {
+//		fmt.Print(report)
+//	}
+type Reconciler struct {
+	client *Client
+	ledger *Ledger
+	opts   ReconcileOptions
+}
+
+// NewReconciler crea un Reconciler sobre ledger.
+func NewReconciler(c *Client, ledger *Ledger, opts ReconcileOptions) *Reconciler {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Minute
+	}
+	return &Reconciler{client: c, ledger: ledger, opts: opts}
+}
+
+// Run concilia Accounts cada Interval hasta que ctx se cancele y llama a onReport (si
+// no es nil) con cada reporte.
+func (r *Reconciler) Run(ctx context.Context, onReport func(ReconcileReport)) error {
+	if len(r.opts.Accounts) == 0 {
+		return &ValidationError{Field: "accounts", Msg: "required"}
+	}
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+	for {
+		for _, account := range r.opts.Accounts {
+			rep, err := r.Reconcile(ctx, account)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				if r.client.logger != nil {
+					r.client.logger.Warn("reconciliation failed", slog.String("account", account), slog.Any("err", err))
+				}
+				continue
+			}
+			if onReport != nil {
+				onReport(rep)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile concilia una cuenta. Con Backfill, si hay diferencias carga los fills de
+// FilledOrders en el Ledger y vuelve a comparar.
+func (r *Reconciler) Reconcile(ctx context.Context, account string) (ReconcileReport, error) {
+	res, err := r.client.AccountPosition(ctx, account)
+	if err == nil {
+		err = responseStatusError(res.Status)
+	}
+	if err != nil {
+		return ReconcileReport{}, fmt.Errorf("reconcile: positions: %w", err)
+	}
+	remote := make(map[string][2]int64)
+	for _, pos := range res.Positions {
+		sizes := remote[positionSymbol(pos)]
+		sizes[0] += int64(math.Round(pos.BuySize))
+		sizes[1] += int64(math.Round(pos.SellSize))
+		remote[positionSymbol(pos)] = sizes
+	}
+
+	rep := r.compare(account, remote)
+	if r.opts.Backfill && !rep.OK() {
+		added, err := r.ledger.Load(ctx, r.client, account)
+		if err != nil {
+			return rep, fmt.Errorf("reconcile: backfill: %w", err)
+		}
+		if added > 0 {
+			rep = r.compare(account, remote)
+			rep.Backfilled = added
+		}
+	}
+	return rep, nil
+}
+
+// compare arma el reporte con los fills locales actuales.
+func (r *Reconciler) compare(account string, remote map[string][2]int64) ReconcileReport {
+	from := r.opts.From
+	if from.IsZero() {
+		from = marketDay(time.Now())
+	}
+	local := make(map[string][2]int64)
+	for _, f := range r.ledger.Fills(FillFilter{Account: account, From: from}) {
+		sizes := local[f.Symbol]
+		if f.Side == model.Buy {
+			sizes[0] += f.Qty
+		} else {
+			sizes[1] += f.Qty
+		}
+		local[f.Symbol] = sizes
+	}
+
+	rep := ReconcileReport{Account: account, Time: time.Now()}
+	symbols := make(map[string]struct{}, len(local)+len(remote))
+	for s := range local {
+		symbols[s] = struct{}{}
+	}
+	for s := range remote {
+		symbols[s] = struct{}{}
+	}
+	for symbol := range symbols {
+		l, inLocal := local[symbol]
+		rm, inRemote := remote[symbol]
+		br := PositionBreak{Account: account, Symbol: symbol, LocalBuy: l[0], LocalSell: l[1], RemoteBuy: rm[0], RemoteSell: rm[1]}
+		switch {
+		case l == rm:
+			rep.Matched = append(rep.Matched, symbol)
+			continue
+		case !inLocal || !inRemote:
+			br.Kind = BreakUnknownSymbol
+		case l[0] <= rm[0] && l[1] <= rm[1]:
+			br.Kind = BreakMissingFill
+		default:
+			br.Kind = BreakQtyMismatch
+		}
+		rep.Breaks = append(rep.Breaks, br)
+	}
+	sort.Strings(rep.Matched)
+	sort.Slice(rep.Breaks, func(i, j int) bool { return rep.Breaks[i].Symbol < rep.Breaks[j].Symbol })
+	return rep
+}
@@ -0,0 +1,89 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestReconciler_BreaksAndBackfill(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	now := time.Now()
+	l := NewLedger()
+	add := func(execID, clOrdID, symbol string, side model.Side, qty int64) {
+		l.Add(Fill{ExecID: execID, ClOrdID: clOrdID, Account: account, Symbol: symbol, Market: model.MarketROFEX, Side: side, Qty: qty, Price: 100, Time: now})
+	}
+	add("e1", "o1", "DLR/MAR26", model.Buy, 4)
+	add("e2", "o2", "DLR/MAR26", model.Sell, 3)
+	add("e3", "o3", "GGAL/ABR26", model.Buy, 6)
+	add("e4", "o4", "RFX20/MAR26", model.Sell, 2)
+	add("e5", "o5", "RFX20/MAR26", model.Buy, 1)
+	// Fill de un día anterior: fuera del período conciliado
+	l.Add(Fill{ExecID: "e0", ClOrdID: "o0", Account: account, Symbol: "RFX20/MAR26", Side: model.Buy, Qty: 7, Time: now.AddDate(0, 0, -2)})
+
+	filleds := 0
+	positionsStatus := "OK"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/rest/risk/position/getPositions/"):
+			pos := func(symbol string, buy, sell float64) map[string]any {
+				return map[string]any{"symbol": symbol, "buySize": buy, "sellSize": sell}
+			}
+			if positionsStatus != "OK" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"status": positionsStatus, "description": "unavailable"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "positions": []any{
+				pos("DLR/MAR26", 10, 3), pos("GGAL/ABR26", 5, 0), pos("RFX20/MAR26", 1, 2), pos("SOJ.ROS/MAY26", 0, 4),
+			}})
+		case strings.HasSuffix(r.URL.Path, "/filleds"):
+			filleds++
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "orders": []map[string]any{{
+				"clOrdId": "o1", "execId": "e9", "side": "BUY", "instrumentId": map[string]any{"marketId": "ROFX", "symbol": "DLR/MAR26"},
+				"cumQty": 10, "avgPx": 100, "transactTime": now.In(MarketLocation).Format(transactTimeLayout),
+			}}})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx := context.Background()
+
+	rep, err := NewReconciler(c, l, ReconcileOptions{}).Reconcile(ctx, account)
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	kinds := map[string]BreakKind{}
+	for _, b := range rep.Breaks {
+		kinds[b.Symbol] = b.Kind
+	}
+	if len(rep.Breaks) != 3 || kinds["DLR/MAR26"] != BreakMissingFill || kinds["GGAL/ABR26"] != BreakQtyMismatch || kinds["SOJ.ROS/MAY26"] != BreakUnknownSymbol {
+		t.Fatalf("breaks: %v", rep.Breaks)
+	}
+	if len(rep.Matched) != 1 || rep.Matched[0] != "RFX20/MAR26" || filleds != 0 {
+		t.Fatalf("matched: %v filleds=%d", rep.Matched, filleds)
+	}
+
+	rep, err = NewReconciler(c, l, ReconcileOptions{Backfill: true}).Reconcile(ctx, account)
+	if err != nil || rep.Backfilled != 1 || len(rep.Breaks) != 2 || len(rep.Matched) != 2 {
+		t.Fatalf("backfill: %+v (%v)", rep, err)
+	}
+	if !strings.Contains(rep.String(), "QTY_MISMATCH REM6771 GGAL/ABR26 local=6/0 remote=5/0") {
+		t.Fatalf("report:\n%s", rep)
+	}
+
+	// Posiciones con status ERROR: la conciliación falla sin reportar quiebres ni hacer backfill
+	positionsStatus = "ERROR"
+	filleds = 0
+	if rep, err := NewReconciler(c, l, ReconcileOptions{Backfill: true}).Reconcile(ctx, account); err == nil || len(rep.Breaks) != 0 || filleds != 0 {
+		t.Fatalf("reconcile with status ERROR: %+v (%v) filleds=%d", rep, err, filleds)
+	}
+}
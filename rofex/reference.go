@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 
 	"github.com/carvalab/rofex-go/rofex/model"
 )
@@ -121,3 +122,61 @@ func (c *Client) InstrumentsBySegment(ctx context.Context, market model.Market,
 	}
 	return agg, nil
 }
+
+// instrumentCache guarda las descripciones de instrumentos ya consultadas para
+// que los controles del lado del cliente no repitan InstrumentDetail por cada orden.
+type instrumentCache struct {
+	mu sync.RWMutex
+	m  map[model.InstrumentID]model.Instrument
+}
+
+func newInstrumentCache() *instrumentCache {
+	return &instrumentCache{m: make(map[model.InstrumentID]model.Instrument)}
+}
+
+func (ic *instrumentCache) get(id model.InstrumentID) (model.Instrument, bool) {
+	ic.mu.RLock()
+	defer ic.mu.RUnlock()
+	inst, ok := ic.m[id]
+	return inst, ok
+}
+
+func (ic *instrumentCache) put(inst model.Instrument) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.m[inst.InstrumentID] = inst
+}
+
+// CacheInstrument registra la descripción de un instrumento en el cache local del cliente.
+//
+// Útil para precargar metadatos (multiplicador, ticks, vencimiento) obtenidos con
+// InstrumentsDetails y evitar consultas individuales desde los controles pre-trade.
+func (c *Client) CacheInstrument(inst model.Instrument) {
+	c.instruments.put(inst)
+}
+
+// Instrument devuelve la descripción del instrumento desde el cache local o, si no
+// está, la consulta con InstrumentDetail y la almacena.
+//
+// Es la fuente de metadatos (ticks, multiplicador, tipos de orden, vencimiento) que
+// usan los controles pre-trade y los algoritmos de ejecución.
+func (c *Client) Instrument(ctx context.Context, symbol string, market model.Market) (model.Instrument, error) {
+	if market == "" {
+		market = model.MarketROFEX
+	}
+	id := model.InstrumentID{Symbol: symbol, MarketID: string(market)}
+	if inst, ok := c.instruments.get(id); ok {
+		return inst, nil
+	}
+	res, err := c.InstrumentDetail(ctx, symbol, market)
+	if err != nil {
+		return model.Instrument{}, err
+	}
+	if res.Status == "ERROR" {
+		return model.Instrument{}, fmt.Errorf("%w: %s:%s: %s", ErrUnknownInstrument, symbol, market, res.Description)
+	}
+	inst := res.Instrument
+	inst.InstrumentID = id
+	c.instruments.put(inst)
+	return inst, nil
+}
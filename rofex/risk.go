@@ -0,0 +1,607 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// RiskLimit identifica cada uno de los controles pre-trade del RiskManager.
+type RiskLimit string
+
+const (
+	// LimitMaxOrderQty: cantidad máxima por orden.
+	LimitMaxOrderQty RiskLimit = "MAX_ORDER_QTY"
+	// LimitMaxOrderNotional: nocional máximo por orden (qty * precio * contractMultiplier * priceConvertionFactor).
+	LimitMaxOrderNotional RiskLimit = "MAX_ORDER_NOTIONAL"
+	// LimitMaxOpenOrders: cantidad máxima de órdenes activas.
+	LimitMaxOpenOrders RiskLimit = "MAX_OPEN_ORDERS"
+	// LimitMaxNetPosition: posición neta máxima (en contratos) contando órdenes activas.
+	LimitMaxNetPosition RiskLimit = "MAX_NET_POSITION"
+	// LimitPriceCollar: desvío máximo del precio respecto del último/medio de mercado.
+	LimitPriceCollar RiskLimit = "PRICE_COLLAR"
+	// LimitMaxOrdersPerSecond: cantidad máxima de órdenes enviadas por segundo.
+	LimitMaxOrdersPerSecond RiskLimit = "MAX_ORDERS_PER_SECOND"
+)
+
+// RiskLimits agrupa los límites configurables. Un valor cero deshabilita el control.
+type RiskLimits struct {
+	MaxOrderQty        int64   // Cantidad máxima por orden
+	MaxOrderNotional   float64 // Nocional máximo por orden
+	MaxOpenOrders      int     // Órdenes activas simultáneas
+	MaxNetPosition     int64   // Posición neta máxima en valor absoluto
+	PriceCollar        float64 // Desvío máximo como fracción (0.05 = ±5%)
+	MaxOrdersPerSecond int     // Órdenes por segundo
+}
+
+// merge devuelve l con los campos no nulos de o superpuestos.
+func (l RiskLimits) merge(o RiskLimits) RiskLimits {
+	if o.MaxOrderQty != 0 {
+		l.MaxOrderQty = o.MaxOrderQty
+	}
+	if o.MaxOrderNotional != 0 {
+		l.MaxOrderNotional = o.MaxOrderNotional
+	}
+	if o.MaxNetPosition != 0 {
+		l.MaxNetPosition = o.MaxNetPosition
+	}
+	if o.PriceCollar != 0 {
+		l.PriceCollar = o.PriceCollar
+	}
+	return l
+}
+
+// LimitUsage informa la utilización actual de un límite.
+type LimitUsage struct {
+	Limit   RiskLimit
+	Account string
+	Symbol  string // Vacío para límites a nivel cuenta
+	Used    float64
+	Max     float64
+}
+
+// Ratio devuelve Used/Max (0 si el límite no está configurado).
+func (u LimitUsage) Ratio() float64 {
+	if u.Max == 0 {
+		return 0
+	}
+	return u.Used / u.Max
+}
+
+// riskOrder es el estado mínimo de una orden activa que necesita el RiskManager.
+type riskOrder struct {
+	account string
+	symbol  string
+	market  model.Market
+	side    model.Side
+	leaves  int64
+	price   *float64
+}
+
+// lastOrderUsage guarda los valores por orden del último envío aceptado.
+type lastOrderUsage struct {
+	qty      float64
+	notional float64
+	collar   float64
+}
+
+// RiskManager aplica controles pre-trade a todas las órdenes que salen del cliente.
+//
+// Los límites se configuran por cuenta (SetAccountLimits) y por instrumento
+// (SetInstrumentLimits). Para cantidad, nocional, posición neta y collar de precio,
+// el límite del instrumento reemplaza al de la cuenta. Órdenes activas y órdenes por
+// segundo se controlan en ambos niveles: el de cuenta sobre todas las órdenes de la
+// cuenta y el de instrumento sobre las órdenes de ese símbolo en la cuenta.
+//
+// El estado de órdenes se alimenta con OnOrderReport (Execution Reports), las
+// posiciones con SetPositions o Client.SyncRiskPositions (AccountPosition) y los
+// precios de referencia con OnMarketData.
+//
+// Uso:
+//
+//	rm := rofex.NewRiskManager()
+//	rm.SetAccountLimits("REM6771", rofex.RiskLimits{MaxOrderQty: 100, MaxOpenOrders: 20})
+//	client, _ := rofex.NewClient(rofex.WithRiskManager(rm))
+//	_ = client.SyncRiskPositions(ctx, "REM6771")
+//	go rm.Watch(ctx, orderSub.Events, mdSub.Events)
+type RiskManager struct {
+	mu               sync.Mutex
+	accountLimits    map[string]RiskLimits
+	instrumentLimits map[string]RiskLimits
+	orders           map[string]*riskOrder
+	positions        map[string]map[string]int64
+	fills            map[string]map[string]int64
+	execIDs          map[string]struct{}
+	refPrices        map[string]float64
+	sent             map[string][]time.Time
+	last             map[string]lastOrderUsage
+	pending          uint64 // Secuencia de claves provisorias de órdenes en vuelo
+	now              func() time.Time
+}
+
+// NewRiskManager crea un RiskManager sin límites configurados.
+func NewRiskManager() *RiskManager {
+	return &RiskManager{
+		accountLimits:    make(map[string]RiskLimits),
+		instrumentLimits: make(map[string]RiskLimits),
+		orders:           make(map[string]*riskOrder),
+		positions:        make(map[string]map[string]int64),
+		fills:            make(map[string]map[string]int64),
+		execIDs:          make(map[string]struct{}),
+		refPrices:        make(map[string]float64),
+		sent:             make(map[string][]time.Time),
+		last:             make(map[string]lastOrderUsage),
+		now:              time.Now,
+	}
+}
+
+// SetAccountLimits configura los límites de una cuenta. La cuenta "" define los
+// límites por defecto para cuentas sin configuración propia.
+func (r *RiskManager) SetAccountLimits(account string, l RiskLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.accountLimits[account] = l
+}
+
+// SetInstrumentLimits configura los límites de un símbolo.
+func (r *RiskManager) SetInstrumentLimits(symbol string, l RiskLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instrumentLimits[symbol] = l
+}
+
+// SetPositions reemplaza la posición de una cuenta con un snapshot de AccountPosition.
+// Los fills acumulados desde el snapshot anterior se descartan porque ya están incluidos.
+func (r *RiskManager) SetPositions(account string, positions []model.Position) {
+	net := make(map[string]int64, len(positions))
+	for _, p := range positions {
+		sym := p.TradingSymbol
+		if sym == "" {
+			sym = p.Symbol
+		}
+		net[sym] += int64(math.Round(p.BuySize - p.SellSize))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.positions[account] = net
+	delete(r.fills, account)
+}
+
+// SyncRiskPositions consulta AccountPosition para cada cuenta y actualiza el RiskManager configurado.
+func (c *Client) SyncRiskPositions(ctx context.Context, accounts ...string) error {
+	if c.risk == nil {
+		return &ValidationError{Field: "risk", Msg: "risk manager not configured"}
+	}
+	for _, account := range accounts {
+		res, err := c.AccountPosition(ctx, account)
+		if err != nil {
+			return err
+		}
+		c.risk.SetPositions(account, res.Positions)
+	}
+	return nil
+}
+
+// OnOrderReport actualiza órdenes activas y posiciones a partir de un Execution Report.
+func (r *RiskManager) OnOrderReport(ev *model.OrderReportEvent) {
+	if ev == nil {
+		return
+	}
+	rep := ev.OrderReport
+	account := ""
+	if rep.AccountID != nil {
+		account = rep.AccountID.ID
+	}
+	symbol := rep.InstrumentID.Symbol
+	status := model.OrderStatus(rep.Status)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rep.WSClOrdID != nil && *rep.WSClOrdID != rep.ClOrdID {
+		delete(r.orders, *rep.WSClOrdID)
+	}
+	if (status == model.StatusPartiallyFilled || status == model.StatusFilled) &&
+		rep.LastQty != nil && *rep.LastQty > 0 && rep.ExecID != nil {
+		if _, seen := r.execIDs[*rep.ExecID]; !seen {
+			r.execIDs[*rep.ExecID] = struct{}{}
+			qty := int64(*rep.LastQty)
+			if model.Side(rep.Side) == model.Sell {
+				qty = -qty
+			}
+			if r.fills[account] == nil {
+				r.fills[account] = make(map[string]int64)
+			}
+			r.fills[account][symbol] += qty
+		}
+	}
+	if !status.IsActive() {
+		delete(r.orders, rep.ClOrdID)
+		return
+	}
+	ro := riskOrderFromReport(rep)
+	r.orders[rep.ClOrdID] = &ro
+}
+
+// riskOrderFromReport arma el estado de una orden activa a partir de su reporte.
+func riskOrderFromReport(rep model.OrderDetails) riskOrder {
+	ro := riskOrder{
+		symbol: rep.InstrumentID.Symbol,
+		market: model.Market(rep.InstrumentID.MarketID),
+		side:   model.Side(rep.Side),
+		leaves: int64(rep.OrderQty),
+		price:  rep.Price,
+	}
+	if rep.AccountID != nil {
+		ro.account = rep.AccountID.ID
+	}
+	if rep.LeavesQty != nil {
+		ro.leaves = int64(*rep.LeavesQty)
+	} else if rep.CumQty != nil {
+		ro.leaves -= int64(*rep.CumQty)
+	}
+	return ro
+}
+
+// OnMarketData actualiza el precio de referencia para el collar: precio medio si hay
+// BI y OF, o último operado (LA) en su defecto.
+func (r *RiskManager) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	md := ev.MarketData
+	ref := 0.0
+	switch {
+	case len(md.Bids) > 0 && len(md.Offers) > 0:
+		ref = (md.Bids[0].Price + md.Offers[0].Price) / 2
+	case md.LA != nil && md.LA.Price != nil:
+		ref = *md.LA.Price
+	}
+	if ref <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.refPrices[ev.InstrumentID.Symbol] = ref
+}
+
+// Watch consume Execution Reports y Market Data hasta que ctx se cancele o ambos canales se cierren.
+// Cualquiera de los canales puede ser nil.
+func (r *RiskManager) Watch(ctx context.Context, orders <-chan *model.OrderReportEvent, md <-chan *model.MarketDataEvent) {
+	for orders != nil || md != nil {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			r.OnOrderReport(ev)
+		case ev, ok := <-md:
+			if !ok {
+				md = nil
+				continue
+			}
+			r.OnMarketData(ev)
+		}
+	}
+}
+
+// Utilization devuelve la utilización de cada límite configurado para la cuenta.
+// Para los límites por orden (cantidad, nocional, collar) informa el valor de la última orden aceptada.
+func (r *RiskManager) Utilization(account string) []LimitUsage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	acct := r.accountLimitsLocked(account)
+	out := make([]LimitUsage, 0)
+	last := r.last[account]
+	if acct.MaxOrderQty > 0 {
+		out = append(out, LimitUsage{Limit: LimitMaxOrderQty, Account: account, Used: last.qty, Max: float64(acct.MaxOrderQty)})
+	}
+	if acct.MaxOrderNotional > 0 {
+		out = append(out, LimitUsage{Limit: LimitMaxOrderNotional, Account: account, Used: last.notional, Max: acct.MaxOrderNotional})
+	}
+	if acct.PriceCollar > 0 {
+		out = append(out, LimitUsage{Limit: LimitPriceCollar, Account: account, Used: last.collar, Max: acct.PriceCollar})
+	}
+	if acct.MaxOpenOrders > 0 {
+		out = append(out, LimitUsage{Limit: LimitMaxOpenOrders, Account: account, Used: float64(r.openOrdersLocked(account, "")), Max: float64(acct.MaxOpenOrders)})
+	}
+	if acct.MaxOrdersPerSecond > 0 {
+		out = append(out, LimitUsage{Limit: LimitMaxOrdersPerSecond, Account: account, Used: float64(len(r.recentLocked(account))), Max: float64(acct.MaxOrdersPerSecond)})
+	}
+
+	symbols := make(map[string]struct{})
+	for sym := range r.positions[account] {
+		symbols[sym] = struct{}{}
+	}
+	for sym := range r.fills[account] {
+		symbols[sym] = struct{}{}
+	}
+	for sym := range r.instrumentLimits {
+		symbols[sym] = struct{}{}
+	}
+	names := make([]string, 0, len(symbols))
+	for sym := range symbols {
+		names = append(names, sym)
+	}
+	sort.Strings(names)
+	for _, sym := range names {
+		eff := acct
+		il, hasInstr := r.instrumentLimits[sym]
+		if hasInstr {
+			eff = eff.merge(il)
+		}
+		if eff.MaxNetPosition > 0 {
+			net := r.netLocked(account, sym)
+			out = append(out, LimitUsage{Limit: LimitMaxNetPosition, Account: account, Symbol: sym, Used: math.Abs(float64(net)), Max: float64(eff.MaxNetPosition)})
+		}
+		if hasInstr && il.MaxOpenOrders > 0 {
+			out = append(out, LimitUsage{Limit: LimitMaxOpenOrders, Account: account, Symbol: sym, Used: float64(r.openOrdersLocked(account, sym)), Max: float64(il.MaxOpenOrders)})
+		}
+		if hasInstr && il.MaxOrdersPerSecond > 0 {
+			out = append(out, LimitUsage{Limit: LimitMaxOrdersPerSecond, Account: account, Symbol: sym, Used: float64(len(r.recentLocked(account + "|" + sym))), Max: float64(il.MaxOrdersPerSecond)})
+		}
+	}
+	return out
+}
+
+// needsInstrument indica si los límites aplicables requieren metadatos del instrumento.
+func (r *RiskManager) needsInstrument(account, symbol string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	eff := r.accountLimitsLocked(account)
+	if il, ok := r.instrumentLimits[symbol]; ok {
+		eff = eff.merge(il)
+	}
+	return eff.MaxOrderNotional > 0
+}
+
+// lookupOrder devuelve la orden activa conocida con ese clOrdID.
+func (r *RiskManager) lookupOrder(clOrdID string) (riskOrder, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orders[clOrdID]
+	if !ok {
+		return riskOrder{}, false
+	}
+	return *o, true
+}
+
+// checkOrder valida la orden contra todos los límites aplicables. replacing es el
+// clOrdID de la orden reemplazada (vacío para órdenes nuevas) y se excluye de los
+// cálculos de órdenes activas y posición.
+//
+// Control y reserva son atómicos: si la orden pasa, su capacidad queda reservada en la
+// misma sección crítica, de modo que envíos concurrentes no pueden superar los límites
+// entre sí. El llamador debe confirmar la reserva con commit o devolverla con release.
+func (r *RiskManager) checkOrder(o NewOrder, inst *model.Instrument, replacing string) (*riskReservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	acct := r.accountLimitsLocked(o.Account)
+	il, hasInstr := r.instrumentLimits[o.Symbol]
+	eff := acct
+	if hasInstr {
+		eff = eff.merge(il)
+	}
+	fail := func(limit RiskLimit, value, max float64) error {
+		return &RiskError{Limit: limit, Account: o.Account, Symbol: o.Symbol, Value: value, Max: max}
+	}
+
+	usage := lastOrderUsage{qty: float64(o.Qty)}
+	if eff.MaxOrderQty > 0 && o.Qty > eff.MaxOrderQty {
+		return nil, fail(LimitMaxOrderQty, float64(o.Qty), float64(eff.MaxOrderQty))
+	}
+
+	ref := r.refPrices[o.Symbol]
+	price := ref
+	if o.Price != nil {
+		price = *o.Price
+	}
+	if eff.MaxOrderNotional > 0 {
+		// Sin precio (orden a mercado sin referencia) el nocional no se puede acotar
+		if price <= 0 {
+			return nil, &RiskError{Limit: LimitMaxOrderNotional, Account: o.Account, Symbol: o.Symbol, Max: eff.MaxOrderNotional, Msg: "no reference price"}
+		}
+		usage.notional = math.Abs(float64(o.Qty) * price * contractFactor(inst))
+		if usage.notional > eff.MaxOrderNotional {
+			return nil, fail(LimitMaxOrderNotional, usage.notional, eff.MaxOrderNotional)
+		}
+	}
+	if eff.PriceCollar > 0 && o.Price != nil && ref > 0 {
+		usage.collar = math.Abs(*o.Price-ref) / ref
+		if usage.collar > eff.PriceCollar {
+			return nil, fail(LimitPriceCollar, usage.collar, eff.PriceCollar)
+		}
+	}
+
+	if replacing == "" {
+		if acct.MaxOpenOrders > 0 {
+			if n := r.openOrdersLocked(o.Account, "") + 1; n > acct.MaxOpenOrders {
+				return nil, fail(LimitMaxOpenOrders, float64(n), float64(acct.MaxOpenOrders))
+			}
+		}
+		if hasInstr && il.MaxOpenOrders > 0 {
+			if n := r.openOrdersLocked(o.Account, o.Symbol) + 1; n > il.MaxOpenOrders {
+				return nil, fail(LimitMaxOpenOrders, float64(n), float64(il.MaxOpenOrders))
+			}
+		}
+	}
+
+	if eff.MaxNetPosition > 0 {
+		net := r.netLocked(o.Account, o.Symbol)
+		var openBuy, openSell int64
+		for id, ro := range r.orders {
+			if id == replacing || ro.account != o.Account || ro.symbol != o.Symbol {
+				continue
+			}
+			if ro.side == model.Sell {
+				openSell += ro.leaves
+			} else {
+				openBuy += ro.leaves
+			}
+		}
+		projected := net + openBuy + o.Qty
+		if o.Side == model.Sell {
+			projected = net - openSell - o.Qty
+		}
+		if abs := math.Abs(float64(projected)); abs > float64(eff.MaxNetPosition) {
+			return nil, fail(LimitMaxNetPosition, abs, float64(eff.MaxNetPosition))
+		}
+	}
+
+	now := r.now()
+	acctKey := o.Account
+	symKey := o.Account + "|" + o.Symbol
+	if acct.MaxOrdersPerSecond > 0 {
+		if n := len(r.recentLocked(acctKey)) + 1; n > acct.MaxOrdersPerSecond {
+			return nil, fail(LimitMaxOrdersPerSecond, float64(n), float64(acct.MaxOrdersPerSecond))
+		}
+	}
+	if hasInstr && il.MaxOrdersPerSecond > 0 {
+		if n := len(r.recentLocked(symKey)) + 1; n > il.MaxOrdersPerSecond {
+			return nil, fail(LimitMaxOrdersPerSecond, float64(n), float64(il.MaxOrdersPerSecond))
+		}
+	}
+	r.sent[acctKey] = append(r.sent[acctKey], now)
+	r.sent[symKey] = append(r.sent[symKey], now)
+
+	res := &riskReservation{
+		r:       r,
+		order:   &riskOrder{account: o.Account, symbol: o.Symbol, market: o.Market, side: o.Side, leaves: o.Qty, price: o.Price},
+		account: o.Account,
+		sentKey: []string{acctKey, symKey},
+		at:      now,
+		usage:   usage,
+	}
+	if replacing == "" {
+		r.pending++
+		res.key = fmt.Sprintf("pending-%d", r.pending)
+	} else {
+		res.key, res.prev = replacing, r.orders[replacing]
+	}
+	r.orders[res.key] = res.order
+	return res, nil
+}
+
+// riskReservation es la capacidad que checkOrder reserva para una orden en vuelo: la
+// orden ya cuenta como activa (órdenes activas y posición neta) y como enviada (órdenes
+// por segundo) hasta que el envío se confirma (commit) o falla (release).
+type riskReservation struct {
+	r       *RiskManager
+	key     string     // Clave en orders: provisoria para órdenes nuevas, el clOrdID reemplazado para reemplazos
+	order   *riskOrder // Orden reservada
+	prev    *riskOrder // Orden reemplazada, para restaurarla si el envío falla
+	account string
+	sentKey []string
+	at      time.Time
+	usage   lastOrderUsage
+}
+
+// commit registra la orden aceptada por la API con su clOrdID, hasta que llegue su
+// Execution Report. Es un no-op sobre una reserva nil.
+func (res *riskReservation) commit(clOrdID string) {
+	if res == nil {
+		return
+	}
+	r := res.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last[res.account] = res.usage
+	if r.orders[res.key] == res.order {
+		delete(r.orders, res.key)
+	}
+	if clOrdID == "" {
+		return
+	}
+	if _, ok := r.orders[clOrdID]; !ok {
+		r.orders[clOrdID] = res.order
+	}
+}
+
+// release devuelve la capacidad reservada cuando el envío falla. Es un no-op sobre una
+// reserva nil.
+func (res *riskReservation) release() {
+	if res == nil {
+		return
+	}
+	r := res.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Un Execution Report posterior a la reserva reemplaza el puntero: no se pisa
+	if r.orders[res.key] == res.order {
+		if res.prev != nil {
+			r.orders[res.key] = res.prev
+		} else {
+			delete(r.orders, res.key)
+		}
+	}
+	for _, key := range res.sentKey {
+		ts := r.sent[key]
+		for i, t := range ts {
+			if t.Equal(res.at) {
+				r.sent[key] = append(ts[:i:i], ts[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (r *RiskManager) accountLimitsLocked(account string) RiskLimits {
+	if l, ok := r.accountLimits[account]; ok {
+		return l
+	}
+	return r.accountLimits[""]
+}
+
+func (r *RiskManager) netLocked(account, symbol string) int64 {
+	return r.positions[account][symbol] + r.fills[account][symbol]
+}
+
+func (r *RiskManager) openOrdersLocked(account, symbol string) int {
+	n := 0
+	for _, o := range r.orders {
+		if o.account == account && (symbol == "" || o.symbol == symbol) {
+			n++
+		}
+	}
+	return n
+}
+
+// recentLocked descarta los envíos de hace más de un segundo y devuelve los restantes.
+func (r *RiskManager) recentLocked(key string) []time.Time {
+	cutoff := r.now().Add(-time.Second)
+	ts := r.sent[key]
+	i := 0
+	for i < len(ts) && !ts[i].After(cutoff) {
+		i++
+	}
+	ts = ts[i:]
+	r.sent[key] = ts
+	return ts
+}
+
+// preTradeRisk aplica los controles del RiskManager a una orden y reserva su
+// capacidad. Sin RiskManager devuelve una reserva nil.
+func (c *Client) preTradeRisk(ctx context.Context, o NewOrder, replacing string) (*riskReservation, error) {
+	if c.risk == nil {
+		return nil, nil
+	}
+	var inst *model.Instrument
+	if c.risk.needsInstrument(o.Account, o.Symbol) {
+		i, err := c.Instrument(ctx, o.Symbol, o.Market)
+		if err != nil {
+			return nil, fmt.Errorf("risk check: instrument detail: %w", err)
+		}
+		inst = &i
+	}
+	return c.risk.checkOrder(o, inst, replacing)
+}
@@ -0,0 +1,244 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestRiskManager_RejectsBeforeSending(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	symbol := "DLR/DIC23"
+	price := 200.0
+	var sent int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/instruments/detail":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "OK",
+				"instrument": map[string]any{
+					"contractMultiplier":    1000,
+					"priceConvertionFactor": 1,
+					"instrumentId":          map[string]any{"marketId": "ROFX", "symbol": symbol},
+				},
+			})
+		case "/rest/order/newSingleOrder":
+			n := atomic.AddInt32(&sent, 1)
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "OK",
+				"order":  map[string]any{"clientId": "user" + string(rune('0'+n)), "proprietary": "PBCP"},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	rm := NewRiskManager()
+	rm.SetAccountLimits(account, RiskLimits{
+		MaxOrderQty:      50,
+		MaxOrderNotional: 5_000_000,
+		MaxOpenOrders:    2,
+		PriceCollar:      0.05,
+	})
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithRiskManager(rm))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	order := NewOrder{Symbol: symbol, Side: model.Buy, Type: model.OrderTypeLimit, Qty: 10, Price: &price, TIF: model.Day, Account: account}
+
+	cases := []struct {
+		name  string
+		mut   func(o *NewOrder)
+		limit RiskLimit
+	}{
+		{"qty", func(o *NewOrder) { o.Qty = 51 }, LimitMaxOrderQty},
+		{"notional", func(o *NewOrder) { o.Qty = 50 }, LimitMaxOrderNotional}, // 50*200*1000
+	}
+	for _, tc := range cases {
+		o := order
+		tc.mut(&o)
+		_, err := c.SendOrder(ctx, o)
+		var re *RiskError
+		if !errors.As(err, &re) || re.Limit != tc.limit {
+			t.Fatalf("%s: want RiskError %s, got %v", tc.name, tc.limit, err)
+		}
+	}
+
+	// Collar: referencia 180 por market data, precio 200 (+11%)
+	bid, off := 179.0, 181.0
+	rm.OnMarketData(&model.MarketDataEvent{
+		InstrumentID: model.InstrumentID{Symbol: symbol, MarketID: "ROFX"},
+		MarketData:   model.MarketData{Bids: []model.BookLevel{{Price: bid}}, Offers: []model.BookLevel{{Price: off}}},
+	})
+	var re *RiskError
+	if _, err := c.SendOrder(ctx, order); !errors.As(err, &re) || re.Limit != LimitPriceCollar {
+		t.Fatalf("collar: want RiskError, got %v", err)
+	}
+
+	// Órdenes activas: dos aceptadas, la tercera se rechaza
+	near := 182.0
+	order.Price = &near
+	for i := 0; i < 2; i++ {
+		if _, err := c.SendOrder(ctx, order); err != nil {
+			t.Fatalf("send %d: %v", i, err)
+		}
+	}
+	if _, err := c.SendOrder(ctx, order); !errors.As(err, &re) || re.Limit != LimitMaxOpenOrders {
+		t.Fatalf("open orders: want RiskError, got %v", err)
+	}
+	if got := atomic.LoadInt32(&sent); got != 2 {
+		t.Fatalf("orders sent: want 2 got %d", got)
+	}
+
+	// Un Execution Report FILLED libera la orden
+	filled := 10
+	execID := "exec-1"
+	rm.OnOrderReport(&model.OrderReportEvent{OrderReport: model.OrderDetails{
+		ClOrdID: "user1", AccountID: &model.AccountReference{ID: account},
+		InstrumentID: model.InstrumentID{Symbol: symbol, MarketID: "ROFX"},
+		Side:         "BUY", OrderQty: 10, LastQty: &filled, CumQty: &filled, ExecID: &execID,
+		Status: string(model.StatusFilled),
+	}})
+	for _, u := range rm.Utilization(account) {
+		if u.Limit == LimitMaxOpenOrders && u.Used != 1 {
+			t.Fatalf("open orders used: want 1 got %v", u.Used)
+		}
+	}
+}
+
+func TestRiskManager_NetPositionAndRate(t *testing.T) {
+	account := "REM7374"
+	symbol := "DLR/DIC23"
+	rm := NewRiskManager()
+	rm.SetInstrumentLimits(symbol, RiskLimits{MaxNetPosition: 10, MaxOrdersPerSecond: 2})
+	rm.SetPositions(account, []model.Position{{TradingSymbol: symbol, BuySize: 8}})
+
+	buy := NewOrder{Symbol: symbol, Side: model.Buy, Qty: 3, Account: account}
+	var re *RiskError
+	if _, err := rm.checkOrder(buy, nil, ""); !errors.As(err, &re) || re.Limit != LimitMaxNetPosition {
+		t.Fatalf("net position: want RiskError, got %v", err)
+	}
+
+	sell := NewOrder{Symbol: symbol, Side: model.Sell, Qty: 5, Account: account}
+	for i := 0; i < 2; i++ {
+		if _, err := rm.checkOrder(sell, nil, ""); err != nil {
+			t.Fatalf("sell %d: %v", i, err)
+		}
+	}
+	if _, err := rm.checkOrder(sell, nil, ""); !errors.As(err, &re) || re.Limit != LimitMaxOrdersPerSecond {
+		t.Fatalf("rate: want RiskError, got %v", err)
+	}
+	now := time.Now()
+	rm.now = func() time.Time { return now.Add(2 * time.Second) }
+	if _, err := rm.checkOrder(sell, nil, ""); err != nil {
+		t.Fatalf("rate window not reset: %v", err)
+	}
+}
+
+func TestRiskManager_ReservesAtomically(t *testing.T) {
+	account := "REM6771"
+	symbol := "DLR/DIC23"
+	price := 200.0
+	var sent int32
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/instruments/detail":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status":     "OK",
+				"instrument": map[string]any{"contractMultiplier": 1000, "instrumentId": map[string]any{"marketId": "ROFX", "symbol": symbol}},
+			})
+		case "/rest/order/newSingleOrder":
+			n := atomic.AddInt32(&sent, 1)
+			<-release
+			if r.URL.Query().Get("orderQty") == "2" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{"clientId": "user" + string(rune('0'+n))}})
+		case "/rest/order/id":
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": "OK", "order": map[string]any{
+				"clOrdId": "other1", "accountId": map[string]any{"id": account}, "side": "BUY", "orderQty": 5, "leavesQty": 5,
+				"price": price, "status": "NEW", "instrumentId": map[string]any{"marketId": "ROFX", "symbol": symbol},
+			}})
+		case "/rest/order/replaceById":
+			t.Errorf("replace sent despite risk limit")
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	rm := NewRiskManager()
+	rm.SetAccountLimits(account, RiskLimits{MaxOrderQty: 10, MaxOrderNotional: 5_000_000, MaxOpenOrders: 2})
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithRiskManager(rm))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	order := NewOrder{Symbol: symbol, Side: model.Buy, Type: model.OrderTypeLimit, Qty: 1, Price: &price, TIF: model.Day, Account: account}
+
+	// Cuatro envíos concurrentes: solo dos reservan lugar mientras la API no responde
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := c.SendOrder(ctx, order)
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		var re *RiskError
+		if err := <-errs; !errors.As(err, &re) || re.Limit != LimitMaxOpenOrders {
+			t.Fatalf("want MaxOpenOrders, got %v", err)
+		}
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&sent); got != 2 {
+		t.Fatalf("orders sent: want 2 got %d", got)
+	}
+
+	// Un envío fallido devuelve la capacidad reservada
+	rm.OnOrderReport(&model.OrderReportEvent{OrderReport: model.OrderDetails{
+		ClOrdID: "user1", AccountID: &model.AccountReference{ID: account},
+		InstrumentID: model.InstrumentID{Symbol: symbol, MarketID: "ROFX"}, Side: "BUY", OrderQty: 1,
+		Status: string(model.StatusCancelled),
+	}})
+	failing := order
+	failing.Qty = 2
+	if _, err := c.SendOrder(ctx, failing); err == nil {
+		t.Fatalf("want HTTP error")
+	}
+	for _, u := range rm.Utilization(account) {
+		if u.Limit == LimitMaxOpenOrders && u.Used != 1 {
+			t.Fatalf("open orders after failed send: want 1 got %v", u.Used)
+		}
+	}
+
+	// Orden a mercado sin precio de referencia: el nocional no se puede controlar
+	market := order
+	market.Type, market.Price = model.OrderTypeMarket, nil
+	var re *RiskError
+	if _, err := c.SendOrder(ctx, market); !errors.As(err, &re) || re.Limit != LimitMaxOrderNotional {
+		t.Fatalf("market order without reference: want RiskError, got %v", err)
+	}
+
+	// Reemplazo de una orden desconocida: se consulta y se aplican los límites por orden
+	qty := int64(11)
+	if _, err := c.ReplaceOrder(ctx, "other1", "", &qty, nil); !errors.As(err, &re) || re.Limit != LimitMaxOrderQty {
+		t.Fatalf("replace unknown order: want RiskError, got %v", err)
+	}
+}
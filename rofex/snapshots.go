@@ -0,0 +1,308 @@
+package rofex
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// AccountSnapshot es el estado de una cuenta en un momento: reporte de cuenta y posiciones.
+type AccountSnapshot struct {
+	Account         string            `json:"account"`
+	Time            time.Time         `json:"time"`            // LastCalculation del reporte o, si falta, la hora de captura
+	LastCalculation int64             `json:"lastCalculation"` // ms, del reporte de cuenta
+	Report          model.AccountData `json:"report"`
+	Positions       []model.Position  `json:"positions"`
+}
+
+// SnapshotStore guarda snapshots como serie temporal por cuenta.
+type SnapshotStore interface {
+	// Append agrega un snapshot.
+	Append(ctx context.Context, s AccountSnapshot) error
+	// Query devuelve los snapshots de la cuenta con Time en [from, to), ordenados por
+	// Time. from o to en cero no limitan.
+	Query(ctx context.Context, account string, from, to time.Time) ([]AccountSnapshot, error)
+}
+
+// SnapshotOptions configura un Snapshotter.
+type SnapshotOptions struct {
+	Accounts []string      // Cuentas a capturar (requerido para Run)
+	Interval time.Duration // Separación entre capturas (mínimo y por defecto 5s, límite de reportes)
+}
+
+// Snapshotter captura periódicamente AccountReport y AccountPosition de un conjunto de
+// cuentas en un SnapshotStore.
+//
+// Como MarginMonitor, captura una cuenta por Interval en forma rotativa. Cada captura
+// hace hasta dos consultas de reportes (AccountReport y AccountPosition) y el
+// Snapshotter deja 5 segundos entre cada consulta, por lo que una captura completa
+// tarda al menos 5s y las siguientes se demoran si Interval es menor a 10s. Los
+// reportes cuyo LastCalculation ya fue guardado no se vuelven a guardar.
+//
+// Ejemplo:
+//
+//	store, _ := rofex.NewFileSnapshotStore("snapshots")
+//	snap := rofex.NewSnapshotter(client, store, rofex.SnapshotOptions{Accounts: []string{"REM6771"}, Interval: time.Minute})
+//	go snap.Run(ctx)
+//	// ...
+//	margin, _ := rofex.QuerySeries(ctx, store, "REM6771", from, to, rofex.MetricMargin)
+type Snapshotter struct {
+	client *Client
+	store  SnapshotStore
+	opts   SnapshotOptions
+	pace   *riskPacer // Espacia AccountReport y AccountPosition
+
+	mu   sync.Mutex
+	last map[string]int64 // Último LastCalculation guardado por cuenta
+}
+
+// NewSnapshotter crea un Snapshotter sobre store.
+func NewSnapshotter(c *Client, store SnapshotStore, opts SnapshotOptions) *Snapshotter {
+	if opts.Interval < 5*time.Second {
+		opts.Interval = 5 * time.Second
+	}
+	return &Snapshotter{client: c, store: store, opts: opts, pace: newRiskPacer(), last: make(map[string]int64)}
+}
+
+// Run captura las cuentas en forma rotativa hasta que ctx se cancele.
+func (s *Snapshotter) Run(ctx context.Context) error {
+	if len(s.opts.Accounts) == 0 {
+		return &ValidationError{Field: "accounts", Msg: "required"}
+	}
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for i := 0; ; i = (i + 1) % len(s.opts.Accounts) {
+		if _, _, err := s.Capture(ctx, s.opts.Accounts[i]); err != nil && ctx.Err() == nil && s.client.logger != nil {
+			s.client.logger.Warn("snapshot capture failed", slog.String("account", s.opts.Accounts[i]), slog.Any("err", err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Capture consulta y guarda el estado de una cuenta. Devuelve false si el reporte
+// tiene el mismo LastCalculation que el último guardado. Espera lo necesario para
+// dejar 5 segundos respecto de la consulta anterior del Snapshotter.
+func (s *Snapshotter) Capture(ctx context.Context, account string) (AccountSnapshot, bool, error) {
+	if err := s.pace.wait(ctx); err != nil {
+		return AccountSnapshot{}, false, err
+	}
+	rep, err := s.client.AccountReport(ctx, account)
+	if err != nil {
+		return AccountSnapshot{}, false, fmt.Errorf("snapshot: account report: %w", err)
+	}
+	if err := responseStatusError(rep.Status); err != nil {
+		return AccountSnapshot{}, false, fmt.Errorf("snapshot: account report: %w", err)
+	}
+	last, err := s.lastCalculation(ctx, account)
+	if err != nil {
+		return AccountSnapshot{}, false, err
+	}
+	calc := rep.AccountData.LastCalculation
+	if calc != 0 && calc == last {
+		return AccountSnapshot{}, false, nil
+	}
+	if err := s.pace.wait(ctx); err != nil {
+		return AccountSnapshot{}, false, err
+	}
+	pos, err := s.client.AccountPosition(ctx, account)
+	if err != nil {
+		return AccountSnapshot{}, false, fmt.Errorf("snapshot: positions: %w", err)
+	}
+	if err := responseStatusError(pos.Status); err != nil {
+		return AccountSnapshot{}, false, fmt.Errorf("snapshot: positions: %w", err)
+	}
+	snap := AccountSnapshot{Account: account, Time: time.Now(), LastCalculation: calc, Report: rep.AccountData, Positions: pos.Positions}
+	if calc != 0 {
+		snap.Time = time.UnixMilli(calc)
+	}
+	if err := s.store.Append(ctx, snap); err != nil {
+		return AccountSnapshot{}, false, fmt.Errorf("snapshot: store: %w", err)
+	}
+	s.mu.Lock()
+	s.last[account] = calc
+	s.mu.Unlock()
+	return snap, true, nil
+}
+
+// lastCalculation devuelve el último LastCalculation guardado de la cuenta; la primera
+// vez lo busca en el store para no duplicar snapshots al reiniciar.
+func (s *Snapshotter) lastCalculation(ctx context.Context, account string) (int64, error) {
+	s.mu.Lock()
+	last, ok := s.last[account]
+	s.mu.Unlock()
+	if ok {
+		return last, nil
+	}
+	stored, err := s.store.Query(ctx, account, marketDay(time.Now()), time.Time{})
+	if err != nil {
+		return 0, fmt.Errorf("snapshot: store: %w", err)
+	}
+	if len(stored) > 0 {
+		last = stored[len(stored)-1].LastCalculation
+	}
+	s.mu.Lock()
+	s.last[account] = last
+	s.mu.Unlock()
+	return last, nil
+}
+
+// SnapshotMetric extrae un valor de un snapshot.
+type SnapshotMetric func(AccountSnapshot) float64
+
+// Métricas del reporte de cuenta.
+var (
+	MetricCollateral            SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.Collateral }
+	MetricMargin                SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.Margin }
+	MetricUncoveredMargin       SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.UncoveredMargin }
+	MetricAvailableToCollateral SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.AvailableToCollateral }
+	MetricCurrentCash           SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.CurrentCash }
+	MetricPortfolio             SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.Portfolio }
+	MetricDailyDiff             SnapshotMetric = func(s AccountSnapshot) float64 { return s.Report.DailyDiff }
+)
+
+// MetricNetPosition devuelve la posición neta (buySize - sellSize) de un instrumento.
+func MetricNetPosition(symbol string) SnapshotMetric {
+	return func(s AccountSnapshot) float64 {
+		var net float64
+		for _, p := range s.Positions {
+			if positionSymbol(p) == symbol {
+				net += p.BuySize - p.SellSize
+			}
+		}
+		return net
+	}
+}
+
+// SeriesPoint es un valor de una serie temporal.
+type SeriesPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// QuerySeries devuelve la serie de metric para la cuenta en [from, to).
+func QuerySeries(ctx context.Context, store SnapshotStore, account string, from, to time.Time, metric SnapshotMetric) ([]SeriesPoint, error) {
+	snaps, err := store.Query(ctx, account, from, to)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SeriesPoint, 0, len(snaps))
+	for _, s := range snaps {
+		out = append(out, SeriesPoint{Time: s.Time, Value: metric(s)})
+	}
+	return out, nil
+}
+
+// FileSnapshotStore es un SnapshotStore en archivos JSON Lines: uno por cuenta y día de
+// mercado, en <dir>/<cuenta>/<yyyymmdd>.jsonl.
+type FileSnapshotStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileSnapshotStore crea el directorio dir si no existe y devuelve el store.
+func NewFileSnapshotStore(dir string) (*FileSnapshotStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileSnapshotStore{dir: dir}, nil
+}
+
+// Append agrega el snapshot al archivo de su cuenta y día.
+func (fs *FileSnapshotStore) Append(_ context.Context, s AccountSnapshot) error {
+	if s.Account == "" {
+		return &ValidationError{Field: "account", Msg: "required"}
+	}
+	line, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	dir := filepath.Join(fs.dir, filepath.Base(s.Account))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, formatDate(s.Time)+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Query lee los archivos de los días en [from, to) y filtra por Time.
+func (fs *FileSnapshotStore) Query(ctx context.Context, account string, from, to time.Time) ([]AccountSnapshot, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	files, err := filepath.Glob(filepath.Join(fs.dir, filepath.Base(account), "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	var out []AccountSnapshot
+	for _, name := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		day, err := parseDate(filepath.Base(name[:len(name)-len(".jsonl")]))
+		if err != nil {
+			continue
+		}
+		if (!from.IsZero() && day.AddDate(0, 0, 1).Before(from)) || (!to.IsZero() && !day.Before(to)) {
+			continue
+		}
+		snaps, err := readSnapshots(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range snaps {
+			if (from.IsZero() || !s.Time.Before(from)) && (to.IsZero() || s.Time.Before(to)) {
+				out = append(out, s)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out, nil
+}
+
+func readSnapshots(name string) ([]AccountSnapshot, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var out []AccountSnapshot
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var s AccountSnapshot
+		if err := json.Unmarshal(sc.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		out = append(out, s)
+	}
+	return out, sc.Err()
+}
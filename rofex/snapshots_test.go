@@ -0,0 +1,89 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotter_DedupAndSeries(t *testing.T) {
+	// Constantes
+	account := "REM6771"
+	base := time.Now().Add(-time.Minute).UnixMilli()
+	calcs := []int64{base, base, base + 30_000, base + 30_000, base + 40_000, base + 50_000}
+	margins := []float64{100, 100, 250, 250, 0, 400}
+	reports, positions := 0, 0
+	reportStatus, positionsStatus := "OK", "OK"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/rest/risk/accountReport/"):
+			i := reports
+			reports++
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": reportStatus, "accountData": map[string]any{
+				"accountName": account, "margin": margins[i], "collateral": 1000, "lastCalculation": calcs[i],
+			}})
+		case strings.HasPrefix(r.URL.Path, "/rest/risk/position/getPositions/"):
+			positions++
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": positionsStatus, "positions": []any{
+				map[string]any{"symbol": "DLR/MAR26", "buySize": 5 * positions, "sellSize": 1},
+			}})
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx := context.Background()
+	store, err := NewFileSnapshotStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileSnapshotStore: %v", err)
+	}
+
+	snap := NewSnapshotter(c, store, SnapshotOptions{Accounts: []string{account}})
+	snap.pace.interval = 20 * time.Millisecond
+	start := time.Now()
+	for i, want := range []bool{true, false, true} {
+		if _, stored, err := snap.Capture(ctx, account); err != nil || stored != want {
+			t.Fatalf("capture %d: stored=%v err=%v", i, stored, err)
+		}
+	}
+	// 5 consultas espaciadas
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("report requests not paced: %v", elapsed)
+	}
+	if positions != 2 {
+		t.Fatalf("positions queried %d times, want 2", positions)
+	}
+
+	// Un Snapshotter nuevo sobre el mismo store no duplica el último reporte
+	if _, stored, err := NewSnapshotter(c, store, SnapshotOptions{}).Capture(ctx, account); err != nil || stored {
+		t.Fatalf("capture after restart: stored=%v err=%v", stored, err)
+	}
+
+	margin, err := QuerySeries(ctx, store, account, time.Time{}, time.Time{}, MetricMargin)
+	if err != nil || len(margin) != 2 || margin[0].Value != 100 || margin[1].Value != 250 || !margin[1].Time.Equal(time.UnixMilli(base+30_000)) {
+		t.Fatalf("margin series: %+v (%v)", margin, err)
+	}
+	// Una respuesta ERROR no guarda un snapshot vacío
+	reportStatus = "ERROR"
+	if _, stored, err := snap.Capture(ctx, account); err == nil || stored {
+		t.Fatalf("capture with ERROR report: stored=%v err=%v", stored, err)
+	}
+	reportStatus, positionsStatus = "OK", "ERROR"
+	if _, stored, err := snap.Capture(ctx, account); err == nil || stored {
+		t.Fatalf("capture with ERROR positions: stored=%v err=%v", stored, err)
+	}
+	if margin, _ := QuerySeries(ctx, store, account, time.Time{}, time.Time{}, MetricMargin); len(margin) != 2 {
+		t.Fatalf("margin series after ERROR: %+v", margin)
+	}
+	net, _ := QuerySeries(ctx, store, account, time.UnixMilli(base+1), time.Time{}, MetricNetPosition("DLR/MAR26"))
+	if len(net) != 1 || net[0].Value != 9 {
+		t.Fatalf("net position series: %+v", net)
+	}
+}
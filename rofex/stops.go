@@ -0,0 +1,277 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// StopTicket identifica una orden stop aceptada por StopManager.
+type StopTicket struct {
+	ID     string // clOrdID de la orden nativa o ID local del stop emulado
+	Native bool   // true si se envió al mercado como STOP_LIMIT / STOP_LIMIT_MERVAL
+}
+
+// stopOrder es un stop emulado pendiente de disparo.
+type stopOrder struct {
+	id      string
+	order   NewOrder
+	created time.Time
+}
+
+// StopManager envía órdenes stop y stop-limit de forma nativa cuando el instrumento
+// las soporta (orderTypes) y las emula del lado del cliente en caso contrario.
+//
+// Un stop emulado se dispara con Market Data (LA, BI, OF):
+//   - Compra: último operado (LA) o mejor oferta de venta (OF) >= StopPx.
+//   - Venta: último operado (LA) o mejor oferta de compra (BI) <= StopPx.
+//
+// Al dispararse se envía con SendOrder una orden LIMIT (stop-limit, al Price indicado)
+// o MARKET (stop). Los cambios de estado de los stops emulados (NEW, TRIGGERED,
+// CANCELLED, REJECTED) se publican en Reports con la misma forma que un Execution
+// Report; el reporte TRIGGERED informa en Text el clOrdID de la orden enviada.
+//
+// Uso:
+//
+//	sm := rofex.NewStopManager(client)
+//	md, _ := client.SubscribeMarketData(ctx, []string{"DLR/DIC23"}, []model.MDEntry{model.MDLast, model.MDBids, model.MDOffers}, 1, model.MarketROFEX)
+//	go sm.Watch(ctx, md.Events)
+//	ticket, err := sm.Submit(ctx, rofex.NewOrder{Type: model.OrderTypeStopLimit, StopPx: &stop, Price: &limit, ...})
+type StopManager struct {
+	client  *Client
+	reports chan *model.OrderReportEvent
+
+	mu     sync.Mutex
+	orders map[string]*stopOrder
+	seq    int
+}
+
+// NewStopManager crea un StopManager. El canal Reports tiene el tamaño de buffer WebSocket del cliente.
+func NewStopManager(c *Client) *StopManager {
+	return &StopManager{
+		client:  c,
+		reports: make(chan *model.OrderReportEvent, c.wsBuf),
+		orders:  make(map[string]*stopOrder),
+	}
+}
+
+// Reports devuelve el canal de reportes de los stops emulados.
+func (s *StopManager) Reports() <-chan *model.OrderReportEvent { return s.reports }
+
+// Submit envía la orden stop de forma nativa si el instrumento soporta su tipo o la
+// registra para emularla. El tipo STOP siempre se emula.
+func (s *StopManager) Submit(ctx context.Context, o NewOrder) (StopTicket, error) {
+	if !o.Type.IsStop() {
+		return StopTicket{}, &ValidationError{Field: "type", Msg: "stop order type required"}
+	}
+	if o.StopPx == nil {
+		return StopTicket{}, &ValidationError{Field: "stopPx", Msg: "required for stop"}
+	}
+	// Se valida la orden que se envía al dispararse: el tipo stop puede no existir en el mercado
+	if err := s.client.validateOrder(ctx, triggeredOrder(o)); err != nil {
+		return StopTicket{}, err
+	}
+	if o.Market == "" {
+		o.Market = model.MarketROFEX
+	}
+	if o.Type != model.OrderTypeStop {
+		inst, err := s.client.Instrument(ctx, o.Symbol, o.Market)
+		if err != nil {
+			return StopTicket{}, fmt.Errorf("stop: instrument detail: %w", err)
+		}
+		if inst.SupportsOrderType(o.Type) {
+			res, err := s.client.SendOrder(ctx, o)
+			if err != nil {
+				return StopTicket{}, err
+			}
+			if (res.Status != "" && !strings.EqualFold(res.Status, "OK")) || res.Order.ClientID == "" {
+				return StopTicket{}, fmt.Errorf("stop: send order %s: status %s", o.Symbol, res.Status)
+			}
+			return StopTicket{ID: res.Order.ClientID, Native: true}, nil
+		}
+	}
+
+	s.mu.Lock()
+	s.seq++
+	so := &stopOrder{
+		id:      fmt.Sprintf("stop-%d-%d", time.Now().UnixNano(), s.seq),
+		order:   o,
+		created: time.Now(),
+	}
+	s.orders[so.id] = so
+	s.mu.Unlock()
+
+	if s.client.logger != nil {
+		s.client.logger.Debug("stop order emulated", slog.String("id", so.id), slog.String("symbol", o.Symbol))
+	}
+	s.emit(so, model.StatusNew, "stop emulado pendiente de disparo")
+	return StopTicket{ID: so.id}, nil
+}
+
+// Cancel cancela un stop. Los emulados se descartan localmente; los nativos se cancelan con CancelOrder.
+func (s *StopManager) Cancel(ctx context.Context, t StopTicket) error {
+	if t.Native {
+		_, err := s.client.CancelOrder(ctx, t.ID, "")
+		return err
+	}
+	s.mu.Lock()
+	so, ok := s.orders[t.ID]
+	delete(s.orders, t.ID)
+	s.mu.Unlock()
+	if !ok {
+		return &ValidationError{Field: "id", Msg: "unknown or already triggered stop"}
+	}
+	s.emit(so, model.StatusCancelled, "cancelada por el usuario")
+	return nil
+}
+
+// Pending devuelve los stops emulados pendientes de disparo, por orden de creación.
+func (s *StopManager) Pending() []StopTicket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := make([]*stopOrder, 0, len(s.orders))
+	for _, so := range s.orders {
+		pending = append(pending, so)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].created.Before(pending[j].created) })
+	out := make([]StopTicket, 0, len(pending))
+	for _, so := range pending {
+		out = append(out, StopTicket{ID: so.id})
+	}
+	return out
+}
+
+// Watch procesa Market Data hasta que ctx se cancele o el canal se cierre.
+func (s *StopManager) Watch(ctx context.Context, events <-chan *model.MarketDataEvent) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			s.OnMarketData(ctx, ev)
+		}
+	}
+}
+
+// OnMarketData evalúa los stops emulados del instrumento y dispara los alcanzados.
+func (s *StopManager) OnMarketData(ctx context.Context, ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	md := ev.MarketData
+	s.mu.Lock()
+	var fired []*stopOrder
+	for id, so := range s.orders {
+		if so.order.Symbol != ev.InstrumentID.Symbol || string(so.order.Market) != ev.InstrumentID.MarketID {
+			continue
+		}
+		if stopTriggered(so.order, md) {
+			fired = append(fired, so)
+			delete(s.orders, id)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(fired, func(i, j int) bool { return fired[i].created.Before(fired[j].created) })
+	for _, so := range fired {
+		res, err := s.client.SendOrder(ctx, triggeredOrder(so.order))
+		if err != nil {
+			s.emit(so, model.StatusRejected, fmt.Sprintf("stop disparado, envío fallido: %v", err))
+			continue
+		}
+		if (res.Status != "" && !strings.EqualFold(res.Status, "OK")) || res.Order.ClientID == "" {
+			s.emit(so, model.StatusRejected, "stop disparado, envío fallido: status "+res.Status)
+			continue
+		}
+		s.emit(so, model.StatusTriggered, "triggered: "+res.Order.ClientID)
+	}
+}
+
+// triggeredOrder devuelve la orden que se envía al dispararse un stop emulado: LIMIT
+// para stop-limit y MARKET para stop.
+func triggeredOrder(o NewOrder) NewOrder {
+	o.StopPx = nil
+	if o.Type == model.OrderTypeStop {
+		o.Type = model.OrderTypeMarket
+		o.Price = nil
+	} else {
+		o.Type = model.OrderTypeLimit
+	}
+	return o
+}
+
+// stopTriggered evalúa si la Market Data alcanza el precio stop de la orden.
+func stopTriggered(o NewOrder, md model.MarketData) bool {
+	stop := *o.StopPx
+	var prices []float64
+	if md.LA != nil && md.LA.Price != nil {
+		prices = append(prices, *md.LA.Price)
+	}
+	if o.Side == model.Sell {
+		if len(md.Bids) > 0 {
+			prices = append(prices, md.Bids[0].Price)
+		}
+		for _, p := range prices {
+			if p <= stop {
+				return true
+			}
+		}
+		return false
+	}
+	if len(md.Offers) > 0 {
+		prices = append(prices, md.Offers[0].Price)
+	}
+	for _, p := range prices {
+		if p >= stop {
+			return true
+		}
+	}
+	return false
+}
+
+// emit publica un reporte con forma de Execution Report para un stop emulado.
+func (s *StopManager) emit(so *stopOrder, status model.OrderStatus, text string) {
+	now := time.Now()
+	ts := now.UnixMilli()
+	leaves := int(so.order.Qty)
+	cum := 0
+	if status != model.StatusNew {
+		leaves = 0
+	}
+	ev := &model.OrderReportEvent{
+		Type:      model.WSMessageOrderReport,
+		Timestamp: &ts,
+		OrderReport: model.OrderDetails{
+			ClOrdID:      so.id,
+			Proprietary:  s.client.proprietary,
+			AccountID:    &model.AccountReference{ID: so.order.Account},
+			InstrumentID: model.InstrumentID{Symbol: so.order.Symbol, MarketID: string(so.order.Market)},
+			Price:        so.order.Price,
+			OrderQty:     int(so.order.Qty),
+			OrdType:      string(so.order.Type),
+			Side:         string(so.order.Side),
+			TimeInForce:  string(so.order.TIF),
+			TransactTime: now.In(MarketLocation).Format(transactTimeLayout),
+			CumQty:       &cum,
+			LeavesQty:    &leaves,
+			Status:       string(status),
+			Text:         &text,
+		},
+	}
+	select {
+	case s.reports <- ev:
+	default:
+		if s.client.logger != nil {
+			s.client.logger.Warn("stop report dropped - channel full", slog.String("id", so.id))
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestStopManager_NativeAndEmulated(t *testing.T) {
+	// Constantes
+	native := "DLR/DIC23"
+	emulated := "MERV - XMEV - GGAL - 48hs"
+	stop, limit := 210.0, 211.0
+	var newOrders []string
+	status := "OK"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/rest/instruments/detail":
+			types := []any{"LIMIT", "MARKET"}
+			if q.Get("symbol") == native {
+				types = append(types, "STOP LIMIT")
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status":     "OK",
+				"instrument": map[string]any{"orderTypes": types},
+			})
+		case "/rest/order/newSingleOrder":
+			newOrders = append(newOrders, q.Get("symbol")+" "+q.Get("ordType")+" "+q.Get("stopPx"))
+			if status != "OK" {
+				_ = json.NewEncoder(w).Encode(map[string]any{"status": status, "description": "rejected"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status": "OK",
+				"order":  map[string]any{"clientId": "user1", "proprietary": "PBCP"},
+			})
+		default:
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL + "/"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sm := NewStopManager(c)
+
+	base := NewOrder{Side: model.Buy, Type: model.OrderTypeStopLimit, Qty: 1, Price: &limit, StopPx: &stop, TIF: model.Day, Account: "REM6771"}
+
+	o := base
+	o.Symbol = native
+	tk, err := sm.Submit(ctx, o)
+	if err != nil || !tk.Native {
+		t.Fatalf("native submit: %+v %v", tk, err)
+	}
+
+	o = base
+	o.Symbol, o.Market = emulated, model.MarketMERV
+	tk, err = sm.Submit(ctx, o)
+	if err != nil || tk.Native {
+		t.Fatalf("emulated submit: %+v %v", tk, err)
+	}
+	if ev := <-sm.Reports(); ev.OrderReport.Status != string(model.StatusNew) {
+		t.Fatalf("want NEW report, got %s", ev.OrderReport.Status)
+	}
+
+	below, above := 209.0, 210.5
+	md := func(last float64) *model.MarketDataEvent {
+		return &model.MarketDataEvent{
+			InstrumentID: model.InstrumentID{Symbol: emulated, MarketID: string(model.MarketMERV)},
+			MarketData:   model.MarketData{LA: &model.Entry{Price: &last}},
+		}
+	}
+	sm.OnMarketData(ctx, md(below))
+	if len(sm.Pending()) != 1 {
+		t.Fatalf("stop fired below trigger")
+	}
+	sm.OnMarketData(ctx, md(above))
+	ev := <-sm.Reports()
+	if ev.OrderReport.Status != string(model.StatusTriggered) || ev.OrderReport.ClOrdID != tk.ID {
+		t.Fatalf("want TRIGGERED for %s, got %+v", tk.ID, ev.OrderReport)
+	}
+
+	// Fuera de StopManager solo se envían los stops que el instrumento soporta
+	var ve *ValidationError
+	o.Type = model.OrderTypeStop
+	if _, err := c.SendOrder(ctx, o); !errors.As(err, &ve) || ve.Field != "type" {
+		t.Fatalf("STOP via SendOrder: want ValidationError, got %v", err)
+	}
+	o.Type = model.OrderTypeStopLimit
+	if _, err := c.SendOrder(ctx, o); !errors.As(err, &ve) || ve.Field != "type" {
+		t.Fatalf("unsupported STOP_LIMIT via SendOrder: want ValidationError, got %v", err)
+	}
+
+	want := []string{native + " STOP_LIMIT 210", emulated + " LIMIT "}
+	if strings.Join(newOrders, "|") != strings.Join(want, "|") {
+		t.Fatalf("orders sent:\nwant %v\ngot  %v", want, newOrders)
+	}
+
+	// Un envío con status ERROR no es un stop aceptado ni disparado
+	status = "ERROR"
+	o = base
+	o.Symbol = native
+	if tk, err := sm.Submit(ctx, o); err == nil {
+		t.Fatalf("native submit with status ERROR: %+v", tk)
+	}
+	o.Symbol, o.Market = emulated, model.MarketMERV
+	if _, err := sm.Submit(ctx, o); err != nil {
+		t.Fatalf("emulated submit: %v", err)
+	}
+	<-sm.Reports()
+	sm.OnMarketData(ctx, md(above))
+	if ev := <-sm.Reports(); ev.OrderReport.Status != string(model.StatusRejected) || !strings.Contains(*ev.OrderReport.Text, "ERROR") {
+		t.Fatalf("want REJECTED with status, got %+v", ev.OrderReport)
+	}
+}
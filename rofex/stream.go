@@ -2,6 +2,7 @@ package rofex
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -47,17 +48,140 @@ type StreamConnection struct {
 //	for event := range sub.Events {
 //	    fmt.Printf("Símbolo: %s, Precio: %v\n", event.InstrumentID.Symbol, event.MarketData)
 //	}
+//
+// Los instrumentos pueden cambiarse sin cerrar la conexión con Add y Remove; al
+// reconectar se vuelve a suscribir el conjunto vigente.
 type MarketDataSubscription struct {
 	Events <-chan *model.MarketDataEvent // Canal tipado según primary-api.md
 	Errs   <-chan error                  // Canal de errores
 	Close  func() error                  // Función para cerrar suscripción
 
 	// Campos internos para gestión
-	conn    *StreamConnection
-	symbols []string
+	client  *Client
 	entries []model.MDEntry
 	depth   int
-	market  model.Market
+
+	mu          sync.RWMutex
+	conn        *StreamConnection
+	instruments []model.InstrumentID // Conjunto suscripto, en orden de alta
+}
+
+// Instruments devuelve los instrumentos suscriptos actualmente.
+func (s *MarketDataSubscription) Instruments() []model.InstrumentID {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]model.InstrumentID(nil), s.instruments...)
+}
+
+// Add suscribe instrumentos adicionales enviando un "smd" con ellos por la conexión
+// existente. Cada instrumento se valida contra los datos de referencia como en
+// SubscribeMarketDataInstruments; los ya suscriptos se ignoran.
+//
+// Si la conexión está caída los instrumentos quedan en el conjunto y se suscriben al
+// reconectar.
+func (s *MarketDataSubscription) Add(ctx context.Context, instruments ...model.InstrumentID) error {
+	ids, err := s.client.validateInstruments(ctx, instruments)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	added := make([]model.InstrumentID, 0, len(ids))
+	for _, id := range ids {
+		if !containsInstrument(s.instruments, id) {
+			s.instruments = append(s.instruments, id)
+			added = append(added, id)
+		}
+	}
+	conn := s.conn
+	s.mu.Unlock()
+	if len(added) == 0 {
+		return nil
+	}
+	if s.client.paper != nil {
+		s.client.paper.watch(added)
+	}
+	if conn == nil {
+		return nil
+	}
+	if err := conn.WriteJSON(ctx, marketDataMessage(added, s.entries, s.depth)); err != nil && !errors.Is(err, ErrClosed) {
+		return fmt.Errorf("subscription send failed: %w", err)
+	}
+	return nil
+}
+
+// Remove quita instrumentos de la suscripción: desde que retorna, sus eventos ya no se
+// entregan en Events. La API no tiene un mensaje para desuscribir, así que el servidor
+// sigue enviándolos por esta conexión hasta la próxima reconexión, que suscribe solo el
+// conjunto vigente. Un MarketID vacío equivale a model.MarketROFEX. Con
+// WithPaperTrading, el simulador abre su propia suscripción para los instrumentos
+// quitados que tengan órdenes activas.
+func (s *MarketDataSubscription) Remove(ctx context.Context, instruments ...model.InstrumentID) error {
+	if len(instruments) == 0 {
+		return &ValidationError{Field: "instruments", Msg: "required"}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	var removed []model.InstrumentID
+	kept := s.instruments[:0]
+	for _, id := range s.instruments {
+		if containsInstrument(instruments, id) {
+			removed = append(removed, id)
+		} else {
+			kept = append(kept, id)
+		}
+	}
+	s.instruments = kept
+	s.mu.Unlock()
+	if s.client.paper != nil && len(removed) > 0 {
+		s.client.paper.unwatch(removed)
+	}
+	return nil
+}
+
+// subscribed indica si los eventos del instrumento deben entregarse.
+func (s *MarketDataSubscription) subscribed(id model.InstrumentID) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return containsInstrument(s.instruments, id)
+}
+
+// message arma el "smd" con el conjunto vigente.
+func (s *MarketDataSubscription) message() mdSubscription {
+	return marketDataMessage(s.Instruments(), s.entries, s.depth)
+}
+
+func (s *MarketDataSubscription) setConn(conn *StreamConnection) {
+	s.mu.Lock()
+	s.conn = conn
+	s.mu.Unlock()
+}
+
+func (s *MarketDataSubscription) close() error {
+	s.mu.RLock()
+	conn := s.conn
+	s.mu.RUnlock()
+	if conn != nil {
+		return conn.Disconnect()
+	}
+	return nil
+}
+
+// containsInstrument compara con MarketID vacío equivalente a model.MarketROFEX.
+func containsInstrument(list []model.InstrumentID, id model.InstrumentID) bool {
+	if id.MarketID == "" {
+		id.MarketID = string(model.MarketROFEX)
+	}
+	for _, x := range list {
+		if x.MarketID == "" {
+			x.MarketID = string(model.MarketROFEX)
+		}
+		if x == id {
+			return true
+		}
+	}
+	return false
 }
 
 // OrderReportSubscription gestiona una suscripción a reportes de órdenes en tiempo real.
@@ -225,6 +349,8 @@ func (sc *StreamConnection) Ping(ctx context.Context) error {
 //	  "depth":2
 //	}
 //
+// Con WithPaperTrading los eventos también alimentan el libro del simulador de órdenes.
+//
 // Referencia: docs/primary-api.md - "Suscribirse a MarketData en tiempo real a través de WebSocket"
 func (c *Client) SubscribeMarketData(ctx context.Context, symbols []string, entries []model.MDEntry, depth int, market model.Market) (*MarketDataSubscription, error) {
 	if len(symbols) == 0 {
@@ -233,6 +359,103 @@ func (c *Client) SubscribeMarketData(ctx context.Context, symbols []string, entr
 	if market == "" {
 		market = model.MarketROFEX
 	}
+	ids := make([]model.InstrumentID, 0, len(symbols))
+	for _, symbol := range symbols {
+		ids = append(ids, model.InstrumentID{Symbol: symbol, MarketID: string(market)})
+	}
+	sub, err := c.subscribeMarketData(ctx, ids, entries, depth)
+	if err != nil {
+		return nil, err
+	}
+	return c.paperTee(sub), nil
+}
+
+// SubscribeMarketDataInstruments suscribe a Market Data de instrumentos de distintos
+// mercados (por ejemplo futuros de ROFX y acciones de MERV) en una sola conexión: el
+// mensaje "smd" lleva el marketId de cada producto.
+//
+// Antes de suscribir valida cada instrumento contra los datos de referencia
+// (Instrument); si alguno no existe devuelve un *UnknownInstrumentsError con todos los
+// desconocidos y no abre la conexión. Un MarketID vacío equivale a model.MarketROFEX.
+//
+// Ejemplo:
+//
+//	sub, err := client.SubscribeMarketDataInstruments(ctx, []model.InstrumentID{
+//		{Symbol: "DLR/MAR26", MarketID: "ROFX"},
+//		{Symbol: "MERV - XMEV - GGAL - 48hs", MarketID: "MERV"},
+//	}, []model.MDEntry{model.MDBids, model.MDOffers, model.MDLast}, 1)
+func (c *Client) SubscribeMarketDataInstruments(ctx context.Context, instruments []model.InstrumentID, entries []model.MDEntry, depth int) (*MarketDataSubscription, error) {
+	ids, err := c.validateInstruments(ctx, instruments)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := c.subscribeMarketData(ctx, ids, entries, depth)
+	if err != nil {
+		return nil, err
+	}
+	return c.paperTee(sub), nil
+}
+
+// validateInstruments normaliza el mercado, elimina duplicados y verifica que cada
+// instrumento exista en los datos de referencia.
+func (c *Client) validateInstruments(ctx context.Context, instruments []model.InstrumentID) ([]model.InstrumentID, error) {
+	if len(instruments) == 0 {
+		return nil, &ValidationError{Field: "instruments", Msg: "required"}
+	}
+	seen := make(map[model.InstrumentID]bool, len(instruments))
+	ids := make([]model.InstrumentID, 0, len(instruments))
+	var unknown []model.InstrumentID
+	for _, id := range instruments {
+		if id.Symbol == "" {
+			return nil, &ValidationError{Field: "symbol", Msg: "required"}
+		}
+		if id.MarketID == "" {
+			id.MarketID = string(model.MarketROFEX)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := c.Instrument(ctx, id.Symbol, model.Market(id.MarketID)); err != nil {
+			if !errors.Is(err, ErrUnknownInstrument) {
+				return nil, fmt.Errorf("validate instruments: %w", err)
+			}
+			unknown = append(unknown, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownInstrumentsError{Instruments: unknown}
+	}
+	return ids, nil
+}
+
+// paperTee, con WithPaperTrading, hace que los eventos de sub también alimenten el
+// libro del simulador de órdenes.
+func (c *Client) paperTee(sub *MarketDataSubscription) *MarketDataSubscription {
+	if c.paper == nil {
+		return sub
+	}
+	c.paper.watch(sub.Instruments())
+	events := make(chan *model.MarketDataEvent, c.wsBuf)
+	in := sub.Events
+	sub.Events = events
+	go func() {
+		defer close(events)
+		for ev := range in {
+			c.paper.OnMarketData(ev)
+			events <- ev
+		}
+	}()
+	return sub
+}
+
+// subscribeMarketData abre la suscripción de Market Data en el WebSocket.
+func (c *Client) subscribeMarketData(ctx context.Context, instruments []model.InstrumentID, entries []model.MDEntry, depth int) (*MarketDataSubscription, error) {
+	if len(instruments) == 0 {
+		return nil, &ValidationError{Field: "instruments", Msg: "required"}
+	}
 	if depth <= 0 {
 		depth = 1
 	}
@@ -241,55 +464,47 @@ func (c *Client) SubscribeMarketData(ctx context.Context, symbols []string, entr
 	eventsChan := make(chan *model.MarketDataEvent, c.wsBuf)
 	errorChan := make(chan error, 5)
 
-	// Create subscription message structure
-	subscriptionMsg := struct {
-		Type     model.WSMessageType `json:"type"`
-		Level    int                 `json:"level"`
-		Depth    int                 `json:"depth"`
-		Entries  []model.MDEntry     `json:"entries"`
-		Products []map[string]string `json:"products"`
-	}{
-		Type:     model.WSMessageSubscribeMarketData,
-		Level:    1,
-		Depth:    depth,
-		Entries:  entries,
-		Products: make([]map[string]string, 0, len(symbols)),
-	}
-
-	for _, symbol := range symbols {
-		subscriptionMsg.Products = append(subscriptionMsg.Products, map[string]string{
-			"symbol":   symbol,
-			"marketId": string(market),
-		})
-	}
-
 	subscription := &MarketDataSubscription{
-		Events:  eventsChan,
-		Errs:    errorChan,
-		symbols: symbols,
-		entries: entries,
-		depth:   depth,
-		market:  market,
+		Events:      eventsChan,
+		Errs:        errorChan,
+		client:      c,
+		instruments: append([]model.InstrumentID(nil), instruments...),
+		entries:     entries,
+		depth:       depth,
 	}
+	subscription.Close = subscription.close
 
 	// Iniciar gestión de conexión
-	go c.manageMarketDataConnection(ctx, subscription, subscriptionMsg, eventsChan, errorChan)
-
-	subscription.Close = func() error {
-		if subscription.conn != nil {
-			return subscription.conn.Disconnect()
-		}
-		return nil
-	}
+	go c.manageMarketDataConnection(ctx, subscription, eventsChan, errorChan)
 
 	return subscription, nil
 }
 
-// manageMarketDataConnection handles connection lifecycle with exponential backoff
+// mdSubscription es el mensaje "smd" de suscripción a Market Data.
+type mdSubscription struct {
+	Type     model.WSMessageType  `json:"type"`
+	Level    int                  `json:"level"`
+	Depth    int                  `json:"depth"`
+	Entries  []model.MDEntry      `json:"entries"`
+	Products []model.InstrumentID `json:"products"`
+}
+
+// marketDataMessage arma el mensaje "smd" con el marketId de cada producto.
+func marketDataMessage(instruments []model.InstrumentID, entries []model.MDEntry, depth int) mdSubscription {
+	return mdSubscription{
+		Type:     model.WSMessageSubscribeMarketData,
+		Level:    1,
+		Depth:    depth,
+		Entries:  entries,
+		Products: instruments,
+	}
+}
+
+// manageMarketDataConnection handles connection lifecycle with exponential backoff.
+// Each (re)connection subscribes the subscription's current instrument set.
 func (c *Client) manageMarketDataConnection(
 	ctx context.Context,
 	sub *MarketDataSubscription,
-	subscriptionMsg interface{},
 	eventsChan chan<- *model.MarketDataEvent,
 	errorChan chan<- error,
 ) {
@@ -321,7 +536,7 @@ func (c *Client) manageMarketDataConnection(
 		// Create connection with authentication header
 		headers := http.Header{"X-Auth-Token": []string{token}}
 		conn := c.NewStreamConnection(ctx, c.wsURL, headers)
-		sub.conn = conn
+		sub.setConn(conn)
 
 		// Attempt to connect
 		if err := conn.Connect(); err != nil {
@@ -330,7 +545,7 @@ func (c *Client) manageMarketDataConnection(
 		}
 
 		// Send subscription message
-		if err := conn.WriteJSON(ctx, subscriptionMsg); err != nil {
+		if err := conn.WriteJSON(ctx, sub.message()); err != nil {
 			c.handleConnectionError(errorChan, ctx, fmt.Errorf("subscription send failed: %w", err), &backoff, maxBackoff, &retryCount, maxRetries)
 			conn.Disconnect()
 			continue
@@ -345,7 +560,7 @@ func (c *Client) manageMarketDataConnection(
 		}
 
 		// Start keepalive and message processing
-		if err := c.processMarketDataMessages(ctx, conn, eventsChan, errorChan); err != nil {
+		if err := c.processMarketDataMessages(ctx, sub, conn, eventsChan, errorChan); err != nil {
 			if c.logger != nil {
 				c.logger.Debug("connection lost, attempting reconnect", slog.Any("err", err))
 			}
@@ -369,6 +584,7 @@ func (c *Client) manageMarketDataConnection(
 // processMarketDataMessages handles message reading and keepalive
 func (c *Client) processMarketDataMessages(
 	ctx context.Context,
+	sub *MarketDataSubscription,
 	conn *StreamConnection,
 	eventsChan chan<- *model.MarketDataEvent,
 	errorChan chan<- error,
@@ -396,8 +612,9 @@ func (c *Client) processMarketDataMessages(
 		// Normalizar el tipo a minúsculas para ser tolerantes con variantes ("Md" vs "md")
 		event.Type = model.WSMessageType(strings.ToLower(string(event.Type)))
 
-		// Enviar solo si es market data tipado
-		if event.Type == model.WSMessageMarketData {
+		// Enviar solo si es market data tipado de un instrumento suscripto (Remove no
+		// corta el envío del servidor)
+		if event.Type == model.WSMessageMarketData && sub.subscribed(event.InstrumentID) {
 			if c.wsDropOnFull {
 				select {
 				case eventsChan <- &event:
@@ -470,10 +687,15 @@ func (c *Client) keepAlive(ctx context.Context, conn *StreamConnection) {
 //
 //	{"type":"os", "snapshotOnlyActive":true}
 //
+// Con WithPaperTrading la suscripción recibe los reportes del simulador de órdenes.
+//
 // Referencia: docs/primary-api.md - "Suscribirse a Execution Reports a través de WebSocket"
 func (c *Client) SubscribeOrderReport(ctx context.Context, account string, snapshotOnlyActive bool) (*OrderReportSubscription, error) {
-	if account == "" {
-		return nil, &ValidationError{Field: "account", Msg: "required"}
+	if err := c.validateAccount(account); err != nil {
+		return nil, err
+	}
+	if c.paper != nil {
+		return c.paper.subscribe(ctx, account, snapshotOnlyActive), nil
 	}
 
 	eventsChan := make(chan *model.OrderReportEvent, c.wsBuf)
@@ -615,6 +837,7 @@ func (c *Client) processOrderReportMessages(
 
 		// Enviar solo si es order report tipado
 		if event.Type == model.WSMessageOrderReport {
+			c.lineage.OnOrderReport(&event)
 			if c.wsDropOnFull {
 				select {
 				case eventsChan <- &event:
@@ -723,6 +946,29 @@ func (c *Client) sendError(errorChan chan<- error, ctx context.Context, err erro
 // mandamos la orden, de lo contrario no recibiremos ningún mensaje sobre el estado
 // de la orden.
 //
+// SendOrderWS usa una conexión compartida por todas sus órdenes: la primera orden de
+// cada cuenta se suscribe a sus Execution Reports ("os") y espera a que la suscripción
+// esté activa antes de enviar la orden ("no") con un wsClOrdId (se genera uno único si
+// o.WSClOrdID es nil). Devuelve un WSOrderHandle que se resuelve con el primer Execution
+// Report que trae ese wsClOrdId (PENDING_NEW o REJECTED), de donde se obtiene el clOrdId
+// para seguir la orden. La espera está acotada por WithWSOrderTimeout. La conexión se
+// cierra tras un minuto sin órdenes pendientes.
+//
+//	h, err := client.SendOrderWS(ctx, order)
+//	if err != nil {
+//		return err
+//	}
+//	ack, err := h.Wait(ctx)
+//	var rej *rofex.OrderRejectedError
+//	switch {
+//	case errors.As(err, &rej):
+//		log.Printf("rechazada: %s", rej.Text)
+//	case errors.Is(err, rofex.ErrOrderAckTimeout):
+//		log.Printf("sin confirmación para %s", h.WSClOrdID)
+//	case err == nil:
+//		log.Printf("clOrdId=%s status=%s", ack.ClOrdID, ack.Status)
+//	}
+//
 // Mensaje enviado:
 //
 //	{
@@ -792,26 +1038,24 @@ func (c *Client) sendError(errorChan chan<- error, ctx context.Context, err erro
 //	}
 //
 // Referencia: docs/primary-api.md - "Ingresar una orden a través de WebSocket"
-func (c *Client) SendOrderWS(ctx context.Context, o NewOrder) error {
-	if err := o.validate(); err != nil {
-		return err
+func (c *Client) SendOrderWS(ctx context.Context, o NewOrder) (*WSOrderHandle, error) {
+	if err := c.validateOrder(ctx, o); err != nil {
+		return nil, err
 	}
 	if o.Market == "" {
 		o.Market = model.MarketROFEX
 	}
-
-	token, err := c.wsAuthToken(ctx)
+	reserved, err := c.beforeNewOrder(ctx, o)
 	if err != nil {
-		return fmt.Errorf("auth token error: %w", err)
+		return nil, err
 	}
-
-	headers := http.Header{"X-Auth-Token": []string{token}}
-	conn := c.NewStreamConnection(ctx, c.wsURL, headers)
-
-	if err := conn.Connect(); err != nil {
-		return fmt.Errorf("connection failed: %w", err)
+	if o.WSClOrdID == nil || *o.WSClOrdID == "" {
+		id := newWSClOrdID()
+		o.WSClOrdID = &id
+	}
+	if c.paper != nil {
+		return c.paperOrderWS(o, reserved), nil
 	}
-	defer conn.Disconnect()
 
 	orderMsg := struct {
 		Type        model.WSMessageType `json:"type"`
@@ -825,6 +1069,7 @@ func (c *Client) SendOrderWS(ctx context.Context, o NewOrder) error {
 		Price       *string             `json:"price,omitempty"`
 		Iceberg     *string             `json:"iceberg,omitempty"`
 		DisplayQty  *string             `json:"displayQuantity,omitempty"`
+		StopPx      *string             `json:"stopPx,omitempty"`
 		ExpireDate  *string             `json:"expireDate,omitempty"`
 		WSClOrdID   *string             `json:"wsClOrdId,omitempty"`
 	}{
@@ -842,24 +1087,32 @@ func (c *Client) SendOrderWS(ctx context.Context, o NewOrder) error {
 	}
 
 	// Add optional fields
-	if o.Price != nil && o.Type == model.OrderTypeLimit {
+	if o.Price != nil && o.Type.HasLimitPrice() {
 		priceStr := fmt.Sprintf("%v", *o.Price)
 		orderMsg.Price = &priceStr
 	}
+	if o.StopPx != nil && o.Type.IsStop() {
+		stopStr := fmt.Sprintf("%v", *o.StopPx)
+		orderMsg.StopPx = &stopStr
+	}
 	if o.Iceberg && o.DisplayQty != nil {
 		icebergStr := "true"
 		displayStr := fmt.Sprintf("%d", *o.DisplayQty)
 		orderMsg.Iceberg = &icebergStr
 		orderMsg.DisplayQty = &displayStr
 	}
-	if o.TIF == model.GoodTillDate && o.ExpireDate != nil {
-		orderMsg.ExpireDate = o.ExpireDate
-	}
-	if o.WSClOrdID != nil && *o.WSClOrdID != "" {
-		orderMsg.WSClOrdID = o.WSClOrdID
+	if o.TIF == model.GoodTillDate {
+		expire := formatDate(o.ExpireDate)
+		orderMsg.ExpireDate = &expire
 	}
+	orderMsg.WSClOrdID = o.WSClOrdID
 
-	return conn.WriteJSON(ctx, orderMsg)
+	h, err := c.wsOrders.send(ctx, o.Account, *o.WSClOrdID, orderMsg, reserved)
+	if err != nil {
+		reserved.release()
+		return nil, err
+	}
+	return h, nil
 }
 
 // CancelOrderWS permite cancelar una orden a través de WebSocket.
@@ -882,6 +1135,10 @@ func (c *Client) CancelOrderWS(ctx context.Context, clientOrderID, proprietary s
 	if proprietary == "" {
 		proprietary = c.proprietary
 	}
+	clientOrderID = c.liveID(clientOrderID)
+	if c.paper != nil {
+		return c.paper.cancelOrder(clientOrderID)
+	}
 
 	token, err := c.wsAuthToken(ctx)
 	if err != nil {
@@ -0,0 +1,245 @@
+package rofex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestSubscribeMarketDataInstruments_MixedMarkets(t *testing.T) {
+	known := map[string]string{"DLR/MAR26": "ROFX", "MERV - XMEV - GGAL - 48hs": "MERV"}
+	var connects int32
+	smd := make(chan map[string]any, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/instruments/detail" {
+			symbol, market := r.URL.Query().Get("symbol"), r.URL.Query().Get("marketId")
+			if known[symbol] != market {
+				_ = json.NewEncoder(w).Encode(map[string]any{"status": "ERROR", "description": "Product " + symbol + ":" + market + " doesn't exist"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status":     "OK",
+				"instrument": map[string]any{"instrumentId": map[string]any{"marketId": market, "symbol": symbol}},
+			})
+			return
+		}
+		atomic.AddInt32(&connects, 1)
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+		var msg map[string]any
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			t.Errorf("read smd: %v", err)
+			return
+		}
+		smd <- msg
+		for symbol, market := range known {
+			_ = wsjson.Write(ctx, conn, map[string]any{
+				"type":         "Md",
+				"instrumentId": map[string]any{"marketId": market, "symbol": symbol},
+				"marketData":   map[string]any{"LA": map[string]any{"price": 100, "size": 1}},
+			})
+		}
+		_, _, _ = conn.Read(ctx)
+	}))
+	defer ts.Close()
+
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithWSURL("ws"+strings.TrimPrefix(ts.URL, "http")), WithStaticToken("tok"))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("unknown instruments", func(t *testing.T) {
+		_, err := c.SubscribeMarketDataInstruments(ctx, []model.InstrumentID{
+			{Symbol: "DLR/MAR26"},
+			{Symbol: "DLR/XXX26", MarketID: "ROFX"},
+			{Symbol: "DLR/MAR26", MarketID: "MERV"},
+		}, []model.MDEntry{model.MDLast}, 1)
+		var unknown *UnknownInstrumentsError
+		if !errors.As(err, &unknown) || !errors.Is(err, ErrUnknownInstrument) {
+			t.Fatalf("want UnknownInstrumentsError, got %v", err)
+		}
+		if len(unknown.Instruments) != 2 || unknown.Instruments[0].Symbol != "DLR/XXX26" || unknown.Instruments[1].MarketID != "MERV" {
+			t.Fatalf("unknown: %+v", unknown.Instruments)
+		}
+		if n := atomic.LoadInt32(&connects); n != 0 {
+			t.Fatalf("connected %d times with unknown instruments", n)
+		}
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		subCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		sub, err := c.SubscribeMarketDataInstruments(subCtx, []model.InstrumentID{
+			{Symbol: "DLR/MAR26"},
+			{Symbol: "MERV - XMEV - GGAL - 48hs", MarketID: "MERV"},
+			{Symbol: "DLR/MAR26", MarketID: "ROFX"},
+		}, []model.MDEntry{model.MDLast}, 1)
+		if err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+
+		msg := <-smd
+		products, _ := msg["products"].([]any)
+		if msg["type"] != "smd" || len(products) != 2 {
+			t.Fatalf("smd: %v", msg)
+		}
+		for _, p := range products {
+			p := p.(map[string]any)
+			if known[p["symbol"].(string)] != p["marketId"] {
+				t.Fatalf("product: %v", p)
+			}
+		}
+
+		seen := make(map[model.InstrumentID]bool)
+		for len(seen) < 2 {
+			select {
+			case ev := <-sub.Events:
+				seen[ev.InstrumentID] = true
+			case <-ctx.Done():
+				t.Fatalf("events: got %v", seen)
+			}
+		}
+		if !seen[model.InstrumentID{Symbol: "MERV - XMEV - GGAL - 48hs", MarketID: "MERV"}] {
+			t.Fatalf("missing MERV event: %v", seen)
+		}
+	})
+}
+
+func TestMarketDataSubscription_AddRemove(t *testing.T) {
+	dlr := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	ggal := model.InstrumentID{Symbol: "MERV - XMEV - GGAL - 48hs", MarketID: "MERV"}
+	smd := make(chan []any, 4)
+	send := make(chan []model.InstrumentID)
+	var connects int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/instruments/detail" {
+			q := r.URL.Query()
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"status":     "OK",
+				"instrument": map[string]any{"instrumentId": map[string]any{"marketId": q.Get("marketId"), "symbol": q.Get("symbol")}},
+			})
+			return
+		}
+		n := atomic.AddInt32(&connects, 1)
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		ctx := r.Context()
+		go func() {
+			for {
+				var msg map[string]any
+				if err := wsjson.Read(ctx, conn, &msg); err != nil {
+					return
+				}
+				products, _ := msg["products"].([]any)
+				smd <- products
+			}
+		}()
+		for ids := range send {
+			if ids == nil {
+				if n == 1 {
+					// Cortar la primera conexión para forzar la reconexión
+					_ = conn.Close(websocket.StatusTryAgainLater, "restart")
+					return
+				}
+				continue
+			}
+			for _, id := range ids {
+				_ = wsjson.Write(ctx, conn, map[string]any{
+					"type":         "Md",
+					"instrumentId": map[string]any{"marketId": id.MarketID, "symbol": id.Symbol},
+					"marketData":   map[string]any{"LA": map[string]any{"price": 100, "size": 1}},
+				})
+			}
+		}
+	}))
+	defer ts.Close()
+	defer close(send)
+
+	c, _ := NewClient(WithBaseURL(ts.URL+"/"), WithWSURL("ws"+strings.TrimPrefix(ts.URL, "http")), WithStaticToken("tok"))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub, err := c.SubscribeMarketData(ctx, []string{dlr.Symbol}, []model.MDEntry{model.MDLast}, 1, model.MarketROFEX)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer sub.Close()
+
+	wantSMD := func(want ...model.InstrumentID) {
+		t.Helper()
+		select {
+		case products := <-smd:
+			if len(products) != len(want) {
+				t.Fatalf("smd products: %v, want %v", products, want)
+			}
+			for i, p := range products {
+				p := p.(map[string]any)
+				if p["symbol"] != want[i].Symbol || p["marketId"] != want[i].MarketID {
+					t.Fatalf("smd products: %v, want %v", products, want)
+				}
+			}
+		case <-ctx.Done():
+			t.Fatalf("no smd, want %v", want)
+		}
+	}
+	wantEvent := func(want model.InstrumentID) {
+		t.Helper()
+		select {
+		case ev := <-sub.Events:
+			if ev.InstrumentID != want {
+				t.Fatalf("event for %v, want %v", ev.InstrumentID, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("no event, want %v", want)
+		}
+	}
+
+	wantSMD(dlr)
+
+	// Add envía un smd solo con los instrumentos nuevos
+	if err := sub.Add(ctx, ggal, dlr); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	wantSMD(ggal)
+	send <- []model.InstrumentID{dlr, ggal}
+	wantEvent(dlr)
+	wantEvent(ggal)
+
+	// Remove filtra los eventos que el servidor sigue enviando
+	if err := sub.Remove(ctx, model.InstrumentID{Symbol: dlr.Symbol}); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	send <- []model.InstrumentID{dlr, ggal}
+	wantEvent(ggal)
+	if got := sub.Instruments(); len(got) != 1 || got[0] != ggal {
+		t.Fatalf("instruments: %v", got)
+	}
+
+	// Al reconectar se suscribe el conjunto vigente
+	send <- nil
+	wantSMD(ggal)
+	if n := atomic.LoadInt32(&connects); n != 2 {
+		t.Fatalf("connects: %d", n)
+	}
+}
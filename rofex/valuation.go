@@ -0,0 +1,248 @@
+package rofex
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// BaseCurrency es la moneda en que la Risk API expresa ExchangeRate y los montos "Plain".
+const BaseCurrency = "ARS"
+
+// FXRate es la cotización de una moneda en pesos (BaseCurrency por unidad).
+type FXRate struct {
+	Rate   float64
+	Source string    // Origen: "report" (ExchangeRate de DetailedPosition) o "md:<símbolo> <entry>"
+	Time   time.Time // Cero si la fuente no informa hora
+}
+
+// RateTable son cotizaciones por moneda.
+type RateTable map[string]FXRate
+
+// RatesFromDetailedPosition arma una RateTable con el ExchangeRate de cada moneda del reporte.
+func RatesFromDetailedPosition(d model.DetailedPosition) RateTable {
+	rates := make(RateTable)
+	for _, item := range d.Items() {
+		if item.Currency != "" && item.ExchangeRate != 0 {
+			rates[item.Currency] = FXRate{Rate: item.ExchangeRate, Source: "report"}
+		}
+	}
+	return rates
+}
+
+// FXFeed cotiza monedas a partir de Market Data de instrumentos en pesos (por ejemplo
+// DLR/MAR26 para "USD R" o el dólar MEP de contado para "USD MtR").
+//
+// Ejemplo:
+//
+//	feed := rofex.NewFXFeed(rofex.MarkMid, map[string]model.InstrumentID{
+//		"USD R": {Symbol: "DLR/MAR26", MarketID: "ROFX"},
+//	})
+//	md, _ := client.SubscribeMarketData(ctx, []string{"DLR/MAR26"}, []model.MDEntry{model.MDBids, model.MDOffers}, 1, model.MarketROFEX)
+//	go func() {
+//		for ev := range md.Events {
+//			feed.OnMarketData(ev)
+//		}
+//	}()
+//	v := rofex.Valuator{Currency: "USD R", Rates: []rofex.RateTable{feed.Rates(), rofex.RatesFromDetailedPosition(pos)}}
+type FXFeed struct {
+	mark MarkSource
+	ids  map[model.InstrumentID]string // Instrumento -> moneda
+
+	mu    sync.RWMutex
+	rates RateTable
+}
+
+// NewFXFeed crea un FXFeed que cotiza cada moneda con el precio mark de su instrumento.
+func NewFXFeed(mark MarkSource, instruments map[string]model.InstrumentID) *FXFeed {
+	if mark == "" {
+		mark = MarkLast
+	}
+	f := &FXFeed{mark: mark, ids: make(map[model.InstrumentID]string), rates: make(RateTable)}
+	for currency, id := range instruments {
+		if id.MarketID == "" {
+			id.MarketID = string(model.MarketROFEX)
+		}
+		f.ids[id] = currency
+	}
+	return f
+}
+
+// OnMarketData actualiza la cotización de la moneda del instrumento del evento.
+func (f *FXFeed) OnMarketData(ev *model.MarketDataEvent) {
+	if ev == nil {
+		return
+	}
+	currency, ok := f.ids[ev.InstrumentID]
+	if !ok {
+		return
+	}
+	px, ok := markPrice(ev.MarketData, f.mark)
+	if !ok || px <= 0 {
+		return
+	}
+	at := time.Now()
+	if t := ev.HumanTime(); t != nil {
+		at = *t
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rates[currency] = FXRate{Rate: px, Source: "md:" + ev.InstrumentID.Symbol + " " + string(f.mark), Time: at}
+}
+
+// Rates devuelve una copia de las cotizaciones actuales.
+func (f *FXFeed) Rates() RateTable {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	out := make(RateTable, len(f.rates))
+	for k, v := range f.rates {
+		out[k] = v
+	}
+	return out
+}
+
+// Tipos de línea de una Valuation.
+const (
+	ValuationBalance  = "balance"  // DetailedCurrencyBalance.Available de una liquidación
+	ValuationCash     = "cash"     // DetailedCash de una liquidación
+	ValuationPosition = "position" // Valor de mercado de un ítem de DetailedPosition
+)
+
+// ValuationLine es un monto convertido a la moneda de reporte.
+type ValuationLine struct {
+	Kind       string // ValuationBalance, ValuationCash o ValuationPosition
+	Settlement string // Clave de liquidación del reporte de cuenta (balance y cash)
+	Symbol     string // Instrumento (position)
+	Currency   string
+	Amount     float64 // En Currency
+	Rate       float64 // Unidades de la moneda de reporte por unidad de Currency (0 si falta cotización)
+	RateSource string  // Origen de la cotización usada
+	Value      float64 // Amount * Rate
+}
+
+// Valuation es el detalle de una conversión a la moneda de reporte.
+type Valuation struct {
+	Currency string
+	Lines    []ValuationLine
+	Totals   map[string]float64 // Por tipo de línea, en Currency
+	Missing  []string           // Monedas sin cotización (sus líneas valen 0)
+}
+
+// Valuator convierte saldos y posiciones a una moneda de reporte.
+//
+// Rates se consultan en orden: la primera tabla que cotiza una moneda es la que se usa,
+// así las cotizaciones en vivo pueden tener prioridad sobre las del reporte. BaseCurrency
+// siempre vale 1.
+type Valuator struct {
+	Currency string // Moneda de reporte (por defecto BaseCurrency)
+	Rates    []RateTable
+}
+
+// AccountReport valúa los saldos (DetailedCurrencyBalance) y el efectivo (DetailedCash)
+// de cada liquidación del reporte de cuenta.
+func (v Valuator) AccountReport(d model.AccountData) Valuation {
+	val := v.newValuation()
+	settlements := make([]string, 0, len(d.DetailedAccountReports))
+	for k := range d.DetailedAccountReports {
+		settlements = append(settlements, k)
+	}
+	sort.Strings(settlements)
+	for _, st := range settlements {
+		r := d.DetailedAccountReports[st]
+		for _, cur := range sortedKeys(r.CurrencyBalance.DetailedCurrencyBalance) {
+			v.add(&val, ValuationLine{Kind: ValuationBalance, Settlement: st, Currency: cur, Amount: r.CurrencyBalance.DetailedCurrencyBalance[cur].Available})
+		}
+		for _, cur := range sortedKeys(r.AvailableToOperate.Cash.DetailedCash) {
+			v.add(&val, ValuationLine{Kind: ValuationCash, Settlement: st, Currency: cur, Amount: r.AvailableToOperate.Cash.DetailedCash[cur]})
+		}
+	}
+	return val
+}
+
+// Positions valúa el valor de mercado (MarketValue) de cada ítem del detalle de posiciones.
+func (v Valuator) Positions(d model.DetailedPosition) Valuation {
+	val := v.newValuation()
+	for _, item := range d.Items() {
+		v.add(&val, ValuationLine{Kind: ValuationPosition, Symbol: item.SymbolReference, Currency: item.Currency, Amount: item.MarketValue()})
+	}
+	return val
+}
+
+// Convert convierte amount de currency a la moneda de reporte. Devuelve la cotización
+// usada y su origen; ok es false si falta alguna cotización.
+func (v Valuator) Convert(amount float64, currency string) (value, rate float64, source string, ok bool) {
+	from, ok := v.lookup(currency)
+	if !ok {
+		return 0, 0, "", false
+	}
+	to, ok := v.lookup(v.reporting())
+	if !ok {
+		return 0, 0, "", false
+	}
+	rate, source = from.Rate/to.Rate, from.Source
+	if v.reporting() != BaseCurrency && v.reporting() != currency {
+		source += " / " + to.Source
+	}
+	return amount * rate, rate, source, true
+}
+
+func (v Valuator) reporting() string {
+	if v.Currency == "" {
+		return BaseCurrency
+	}
+	return v.Currency
+}
+
+func (v Valuator) lookup(currency string) (FXRate, bool) {
+	if currency == BaseCurrency || currency == "" {
+		return FXRate{Rate: 1, Source: "base"}, true
+	}
+	for _, t := range v.Rates {
+		if r, ok := t[currency]; ok && r.Rate > 0 {
+			return r, true
+		}
+	}
+	return FXRate{}, false
+}
+
+func (v Valuator) newValuation() Valuation {
+	return Valuation{Currency: v.reporting(), Totals: make(map[string]float64)}
+}
+
+func (v Valuator) add(val *Valuation, line ValuationLine) {
+	if line.Currency == v.reporting() {
+		line.Rate, line.RateSource, line.Value = 1, "same currency", line.Amount
+	} else if value, rate, source, ok := v.Convert(line.Amount, line.Currency); ok {
+		line.Rate, line.RateSource, line.Value = rate, source, value
+	} else {
+		missing := line.Currency
+		if _, ok := v.lookup(line.Currency); ok {
+			missing = v.reporting()
+		}
+		if !containsString(val.Missing, missing) {
+			val.Missing = append(val.Missing, missing)
+		}
+	}
+	val.Lines = append(val.Lines, line)
+	val.Totals[line.Kind] += line.Value
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package rofex
+
+import (
+	"math"
+	"testing"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+func TestValuator_ReportAndLiveRates(t *testing.T) {
+	// Constantes
+	dlr := model.InstrumentID{Symbol: "DLR/MAR26", MarketID: "ROFX"}
+	pos := model.DetailedPosition{Report: map[string]map[string]model.DetailedInstrument{
+		"FUTURE OPTION CALL": {"SOJ.ROS/MAY23 380 C": {DetailedPositions: []model.DetailedPositionItem{{
+			SymbolReference: "SOJ.ROS/MAY23 380 C", Currency: "USD G", ExchangeRate: 167.77, ContractMultiplier: 1, MarketPrice: 380.5, TotalCurrentSize: -2,
+		}}}},
+		"FUTURE": {"DLR/MAR26": {DetailedPositions: []model.DetailedPositionItem{{
+			SymbolReference: "DLR/MAR26", Currency: "ARS", ContractMultiplier: 1000, MarketPrice: 1000, TotalCurrentSize: 5,
+		}}}},
+	}}
+	report := model.AccountData{DetailedAccountReports: map[string]model.DetailedAccountReport{
+		"0": {
+			CurrencyBalance: model.CurrencyBalance{DetailedCurrencyBalance: map[string]model.CurrencyAmount{
+				"ARS": {Available: 1_001_000}, "USD G": {Available: 100}, "EUR": {Available: 10},
+			}},
+			AvailableToOperate: model.AvailableToOperate{Cash: model.Cash{DetailedCash: map[string]float64{"USD R": 50}}},
+		},
+	}}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+	feed := NewFXFeed(MarkMid, map[string]model.InstrumentID{"USD R": {Symbol: dlr.Symbol}})
+	feed.OnMarketData(&model.MarketDataEvent{InstrumentID: dlr, MarketData: model.MarketData{
+		Bids: []model.BookLevel{{Price: 1000, Size: 1}}, Offers: []model.BookLevel{{Price: 1002, Size: 1}},
+	}})
+	v := Valuator{Currency: "USD R", Rates: []RateTable{feed.Rates(), RatesFromDetailedPosition(pos)}}
+
+	p := v.Positions(pos)
+	if len(p.Lines) != 2 || len(p.Missing) != 0 {
+		t.Fatalf("positions: %+v", p)
+	}
+	if l := p.Lines[0]; l.Symbol != "DLR/MAR26" || l.Value != 5e6/1001 || l.RateSource != "base / md:DLR/MAR26 MID" {
+		t.Fatalf("ARS line: %+v", l)
+	}
+	if l := p.Lines[1]; !near(l.Rate, 167.77/1001) || l.RateSource != "report / md:DLR/MAR26 MID" || !near(p.Totals[ValuationPosition], 5e6/1001-761*167.77/1001) {
+		t.Fatalf("USD G line: %+v totals=%v", l, p.Totals)
+	}
+
+	a := v.AccountReport(report)
+	if len(a.Lines) != 4 || len(a.Missing) != 1 || a.Missing[0] != "EUR" {
+		t.Fatalf("account report: %+v", a)
+	}
+	if l := a.Lines[0]; l.Currency != "ARS" || l.Value != 1000 {
+		t.Fatalf("ARS balance: %+v", l)
+	}
+	if l := a.Lines[1]; l.Currency != "EUR" || l.Rate != 0 || l.Value != 0 {
+		t.Fatalf("EUR balance: %+v", l)
+	}
+	if l := a.Lines[3]; l.Kind != ValuationCash || l.Rate != 1 || l.Value != 50 || a.Totals[ValuationCash] != 50 {
+		t.Fatalf("cash: %+v", l)
+	}
+
+	// En pesos alcanza con las cotizaciones del reporte
+	ars := Valuator{Rates: []RateTable{RatesFromDetailedPosition(pos)}}.Positions(pos)
+	if !near(ars.Totals[ValuationPosition], 5e6-761*167.77) {
+		t.Fatalf("ARS totals: %v", ars.Totals)
+	}
+}
@@ -0,0 +1,330 @@
+package rofex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// wsClOrdSeq desambigua wsClOrdIds generados en el mismo nanosegundo.
+var wsClOrdSeq atomic.Uint64
+
+// newWSClOrdID genera un wsClOrdId único para el proceso.
+func newWSClOrdID() string {
+	return fmt.Sprintf("ws%d%d", time.Now().UnixNano(), wsClOrdSeq.Add(1))
+}
+
+// WSOrderAck es el primer Execution Report de una orden enviada por WebSocket.
+type WSOrderAck struct {
+	WSClOrdID string             // wsClOrdId enviado con la orden
+	ClOrdID   string             // clOrdId asignado por el mercado, para seguir la orden
+	Status    model.OrderStatus  // PENDING_NEW (o REJECTED, informado como OrderRejectedError)
+	Report    model.OrderDetails // Execution Report completo
+}
+
+// WSOrderHandle correlaciona una orden enviada con SendOrderWS con su primer Execution
+// Report, que es el único que trae el wsClOrdId.
+type WSOrderHandle struct {
+	WSClOrdID string
+
+	done chan struct{}
+	once sync.Once
+	ack  WSOrderAck
+	err  error
+}
+
+// Done se cierra cuando la orden fue confirmada, rechazada o venció la espera.
+func (h *WSOrderHandle) Done() <-chan struct{} { return h.done }
+
+// Wait espera el primer Execution Report de la orden y devuelve su clOrdId y estado.
+//
+// Errores:
+//   - *OrderRejectedError si el mercado rechazó la orden.
+//   - ErrOrderAckTimeout si no llegó el reporte dentro del timeout (ver WithWSOrderTimeout);
+//     la orden pudo haber ingresado igual, verificar con ActiveOrders.
+//   - Un error de conexión si se cayó la conexión compartida antes del reporte.
+//   - ctx.Err() si ctx se cancela antes.
+func (h *WSOrderHandle) Wait(ctx context.Context) (WSOrderAck, error) {
+	select {
+	case <-h.done:
+		return h.ack, h.err
+	case <-ctx.Done():
+		return WSOrderAck{WSClOrdID: h.WSClOrdID}, ctx.Err()
+	}
+}
+
+func (h *WSOrderHandle) resolve(ack WSOrderAck, err error) {
+	h.once.Do(func() {
+		h.ack, h.err = ack, err
+		close(h.done)
+	})
+}
+
+// wsOrderIdle es el tiempo sin órdenes pendientes tras el cual se cierra la conexión
+// compartida de SendOrderWS; la próxima orden la vuelve a abrir.
+const wsOrderIdle = time.Minute
+
+// wsSubscribeSettle es la espera máxima de la suscripción a los Execution Reports de
+// una cuenta. Primary no confirma el "os": se espera el snapshot de órdenes activas
+// (primer mensaje recibido) o este tiempo si la cuenta no tiene órdenes activas.
+const wsSubscribeSettle = 500 * time.Millisecond
+
+// wsLateAckWindow es el tiempo que se sigue esperando el reporte de una orden vencida
+// (ErrOrderAckTimeout) para confirmar o devolver su reserva de los controles pre-trade.
+const wsLateAckWindow = time.Minute
+
+// wsOrderSession es la conexión de Execution Reports compartida por SendOrderWS. Se abre
+// con la primera orden, se suscribe una sola vez a cada cuenta y correlaciona los
+// reportes con las órdenes pendientes por wsClOrdId.
+type wsOrderSession struct {
+	c *Client
+
+	mu       sync.Mutex
+	conn     *StreamConnection
+	msgs     chan struct{}   // señal de mensaje recibido en conn (suscripciones)
+	accounts map[string]bool // cuentas suscriptas en conn
+	pending  map[string]*wsPendingOrder
+	idle     *time.Timer
+}
+
+// wsPendingOrder es una orden enviada sin su primer Execution Report.
+type wsPendingOrder struct {
+	h        *WSOrderHandle
+	reserved *riskReservation
+	timer    *time.Timer
+	expired  bool // el handle ya se resolvió con ErrOrderAckTimeout
+}
+
+func newWSOrderSession(c *Client) *wsOrderSession {
+	return &wsOrderSession{c: c, pending: make(map[string]*wsPendingOrder)}
+}
+
+// send envía la orden por la conexión compartida, suscribiéndose antes a la cuenta si
+// hace falta. La orden se registra antes de escribirla para no perder un reporte rápido.
+func (s *wsOrderSession) send(ctx context.Context, account, wsClOrdID string, msg any, reserved *riskReservation) (*WSOrderHandle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conn, err := s.connectLocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.subscribeLocked(ctx, conn, account); err != nil {
+		return nil, err
+	}
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	p := &wsPendingOrder{h: &WSOrderHandle{WSClOrdID: wsClOrdID, done: make(chan struct{})}, reserved: reserved}
+	s.pending[wsClOrdID] = p
+	if err := conn.WriteJSON(ctx, msg); err != nil {
+		delete(s.pending, wsClOrdID)
+		s.idleLocked()
+		return nil, err
+	}
+	p.timer = time.AfterFunc(s.c.wsOrderTimeout, func() { s.timeout(wsClOrdID) })
+	return p.h, nil
+}
+
+func (s *wsOrderSession) connectLocked(ctx context.Context) (*StreamConnection, error) {
+	if s.conn != nil && s.conn.IsConnected() {
+		return s.conn, nil
+	}
+	token, err := s.c.wsAuthToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("auth token error: %w", err)
+	}
+	headers := http.Header{"X-Auth-Token": []string{token}}
+	// La conexión sobrevive a la orden que la abrió: no depende de ctx.
+	conn := s.c.NewStreamConnection(context.Background(), s.c.wsURL, headers)
+	if err := conn.Connect(); err != nil {
+		return nil, fmt.Errorf("connection failed: %w", err)
+	}
+	s.conn, s.accounts, s.msgs = conn, make(map[string]bool), make(chan struct{}, 1)
+	go s.c.keepAlive(conn.ctx, conn)
+	go s.read(conn, s.msgs)
+	return conn, nil
+}
+
+// subscribeLocked se suscribe a los Execution Reports de la cuenta ("os") y espera a
+// que la suscripción esté activa (ver wsSubscribeSettle).
+func (s *wsOrderSession) subscribeLocked(ctx context.Context, conn *StreamConnection, account string) error {
+	if s.accounts[account] {
+		return nil
+	}
+	subscriptionMsg := struct {
+		Type    model.WSMessageType `json:"type"`
+		Account struct {
+			ID string `json:"id"`
+		} `json:"account"`
+		SnapshotOnlyActive bool `json:"snapshotOnlyActive"`
+	}{Type: model.WSMessageOrderSubscription, SnapshotOnlyActive: true}
+	subscriptionMsg.Account.ID = account
+
+	select {
+	case <-s.msgs:
+	default:
+	}
+	if err := conn.WriteJSON(ctx, subscriptionMsg); err != nil {
+		return fmt.Errorf("subscription send failed: %w", err)
+	}
+	settle := time.NewTimer(wsSubscribeSettle)
+	defer settle.Stop()
+	select {
+	case <-s.msgs:
+	case <-settle.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	s.accounts[account] = true
+	return nil
+}
+
+// read consume los Execution Reports de conn y resuelve las órdenes pendientes.
+func (s *wsOrderSession) read(conn *StreamConnection, msgs chan<- struct{}) {
+	for {
+		var ev model.OrderReportEvent
+		if err := conn.ReadJSON(conn.ctx, &ev); err != nil {
+			s.closed(conn, err)
+			return
+		}
+		select {
+		case msgs <- struct{}{}:
+		default:
+		}
+		if model.WSMessageType(strings.ToLower(string(ev.Type))) != model.WSMessageOrderReport {
+			continue
+		}
+		if rep := ev.OrderReport; rep.WSClOrdID != nil {
+			s.resolve(*rep.WSClOrdID, rep)
+		}
+	}
+}
+
+// resolve confirma o devuelve la reserva de la orden con su primer Execution Report,
+// aunque llegue después de ErrOrderAckTimeout.
+func (s *wsOrderSession) resolve(wsClOrdID string, rep model.OrderDetails) {
+	s.mu.Lock()
+	p, ok := s.pending[wsClOrdID]
+	if ok {
+		delete(s.pending, wsClOrdID)
+		p.timer.Stop()
+		s.idleLocked()
+	}
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	ack := WSOrderAck{
+		WSClOrdID: wsClOrdID,
+		ClOrdID:   rep.ClOrdID,
+		Status:    model.OrderStatus(rep.Status),
+		Report:    rep,
+	}
+	if ack.Status == model.StatusRejected {
+		text := ""
+		if rep.Text != nil {
+			text = *rep.Text
+		}
+		p.reserved.release()
+		p.h.resolve(ack, &OrderRejectedError{ClOrdID: rep.ClOrdID, WSClOrdID: wsClOrdID, Text: text})
+		return
+	}
+	p.reserved.commit(rep.ClOrdID)
+	p.h.resolve(ack, nil)
+}
+
+// timeout resuelve el handle con ErrOrderAckTimeout. La orden pudo haber ingresado: su
+// reserva se mantiene hasta que llegue el reporte tardío o venza wsLateAckWindow; en ese
+// caso se devuelve y el RiskManager la conoce por sus Execution Reports.
+func (s *wsOrderSession) timeout(wsClOrdID string) {
+	s.mu.Lock()
+	p, ok := s.pending[wsClOrdID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	if p.expired {
+		delete(s.pending, wsClOrdID)
+		s.idleLocked()
+		s.mu.Unlock()
+		p.reserved.release()
+		return
+	}
+	p.expired = true
+	p.timer = time.AfterFunc(wsLateAckWindow, func() { s.timeout(wsClOrdID) })
+	s.mu.Unlock()
+
+	if s.c.logger != nil {
+		s.c.logger.Warn("ws order not acknowledged", slog.String("wsClOrdId", wsClOrdID), slog.Any("err", ErrOrderAckTimeout))
+	}
+	p.h.resolve(WSOrderAck{WSClOrdID: wsClOrdID}, ErrOrderAckTimeout)
+}
+
+// closed descarta la conexión caída y resuelve sus órdenes pendientes con el error.
+func (s *wsOrderSession) closed(conn *StreamConnection, err error) {
+	s.mu.Lock()
+	if s.conn != conn {
+		s.mu.Unlock()
+		return
+	}
+	s.conn = nil
+	pending := s.pending
+	s.pending = make(map[string]*wsPendingOrder)
+	s.mu.Unlock()
+	conn.Disconnect()
+
+	for id, p := range pending {
+		p.timer.Stop()
+		p.reserved.release()
+		if s.c.logger != nil {
+			s.c.logger.Warn("ws order connection lost", slog.String("wsClOrdId", id), slog.Any("err", err))
+		}
+		p.h.resolve(WSOrderAck{WSClOrdID: id}, fmt.Errorf("order report connection: %w", err))
+	}
+}
+
+// idleLocked programa el cierre de la conexión si no quedan órdenes pendientes.
+func (s *wsOrderSession) idleLocked() {
+	if len(s.pending) > 0 || s.conn == nil {
+		return
+	}
+	if s.idle != nil {
+		s.idle.Stop()
+	}
+	conn := s.conn
+	s.idle = time.AfterFunc(wsOrderIdle, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.conn == conn && len(s.pending) == 0 {
+			s.conn = nil
+			conn.Disconnect()
+		}
+	})
+}
+
+// paperOrderWS ingresa la orden en el simulador de WithPaperTrading y resuelve el handle
+// con su primer reporte (*OrderRejectedError si el simulador la rechaza).
+func (c *Client) paperOrderWS(o NewOrder, reserved *riskReservation) *WSOrderHandle {
+	h := &WSOrderHandle{WSClOrdID: *o.WSClOrdID, done: make(chan struct{})}
+	rep := c.paper.submit(o, o.WSClOrdID)
+	ack := WSOrderAck{WSClOrdID: h.WSClOrdID, ClOrdID: rep.ClOrdID, Status: model.OrderStatus(rep.Status), Report: rep}
+	if ack.Status == model.StatusRejected {
+		text := ""
+		if rep.Text != nil {
+			text = *rep.Text
+		}
+		reserved.release()
+		h.resolve(ack, &OrderRejectedError{ClOrdID: rep.ClOrdID, WSClOrdID: h.WSClOrdID, Text: text})
+		return h
+	}
+	reserved.commit(rep.ClOrdID)
+	h.resolve(ack, nil)
+	return h
+}
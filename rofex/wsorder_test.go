@@ -0,0 +1,157 @@
+package rofex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/carvalab/rofex-go/rofex/model"
+)
+
+// wsOrderServer responde a cada orden "no" con el Execution Report que devuelva reply
+// (nil = no responder). A la suscripción "os" le sigue el snapshot de una orden ajena.
+// conns cuenta las conexiones abiertas.
+func wsOrderServer(t *testing.T, conns *atomic.Int32, reply func(no map[string]any) map[string]any) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Auth-Token") != "tok" {
+			t.Errorf("missing auth token")
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		conns.Add(1)
+		ctx := r.Context()
+
+		var os map[string]any
+		if err := wsjson.Read(ctx, conn, &os); err != nil || os["type"] != "os" {
+			t.Errorf("want os first, got %v (%v)", os, err)
+			return
+		}
+		if acc, _ := os["account"].(map[string]any); acc["id"] != "REM6771" {
+			t.Errorf("os account: %v", os["account"])
+		}
+		_ = wsjson.Write(ctx, conn, map[string]any{"type": "or", "orderReport": map[string]any{"clOrdId": "other", "status": "NEW"}})
+		for {
+			var no map[string]any
+			if err := wsjson.Read(ctx, conn, &no); err != nil {
+				return
+			}
+			if no["type"] != "no" {
+				t.Errorf("want no, got %v", no)
+				return
+			}
+			if rep := reply(no); rep != nil {
+				_ = wsjson.Write(ctx, conn, map[string]any{"type": "or", "orderReport": rep})
+			}
+		}
+	}))
+}
+
+func TestSendOrderWS_Handle(t *testing.T) {
+	price := 200.0
+	order := NewOrder{Symbol: "DLR/DIC23", Side: model.Buy, Type: model.OrderTypeLimit, Qty: 1, Price: &price, TIF: model.Day, Account: "REM6771"}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	newClient := func(ts *httptest.Server, opts ...Option) *Client {
+		wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+		c, _ := NewClient(append([]Option{WithWSURL(wsURL), WithStaticToken("tok")}, opts...)...)
+		return c
+	}
+
+	t.Run("ack with generated wsClOrdId", func(t *testing.T) {
+		var conns atomic.Int32
+		var seq atomic.Int32
+		ts := wsOrderServer(t, &conns, func(no map[string]any) map[string]any {
+			return map[string]any{"clOrdId": fmt.Sprintf("user%d", 123+seq.Add(1)-1), "wsClOrdId": no["wsClOrdId"], "status": "PENDING_NEW"}
+		})
+		defer ts.Close()
+
+		c := newClient(ts)
+		for i, want := range []string{"user123", "user124"} {
+			h, err := c.SendOrderWS(ctx, order)
+			if err != nil {
+				t.Fatalf("SendOrderWS: %v", err)
+			}
+			if h.WSClOrdID == "" {
+				t.Fatalf("wsClOrdId not generated")
+			}
+			ack, err := h.Wait(ctx)
+			if err != nil {
+				t.Fatalf("Wait %d: %v", i, err)
+			}
+			if ack.ClOrdID != want || ack.Status != model.StatusPendingNew || ack.WSClOrdID != h.WSClOrdID {
+				t.Fatalf("ack %d: %+v", i, ack)
+			}
+		}
+		// Ambas órdenes usan la misma conexión y una sola suscripción
+		if n := conns.Load(); n != 1 {
+			t.Fatalf("want 1 connection, got %d", n)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		var conns atomic.Int32
+		ts := wsOrderServer(t, &conns, func(no map[string]any) map[string]any {
+			return map[string]any{"clOrdId": "user124", "wsClOrdId": no["wsClOrdId"], "status": "REJECTED", "text": "Price out of range"}
+		})
+		defer ts.Close()
+
+		id := "mine-1"
+		o := order
+		o.WSClOrdID = &id
+		h, err := newClient(ts).SendOrderWS(ctx, o)
+		if err != nil {
+			t.Fatalf("SendOrderWS: %v", err)
+		}
+		_, err = h.Wait(ctx)
+		var rej *OrderRejectedError
+		if !errors.As(err, &rej) || rej.ClOrdID != "user124" || rej.WSClOrdID != "mine-1" || rej.Text != "Price out of range" {
+			t.Fatalf("want OrderRejectedError, got %v", err)
+		}
+	})
+
+	t.Run("timeout and late ack", func(t *testing.T) {
+		var conns atomic.Int32
+		ts := wsOrderServer(t, &conns, func(no map[string]any) map[string]any {
+			time.Sleep(150 * time.Millisecond)
+			return map[string]any{"clOrdId": "user125", "wsClOrdId": no["wsClOrdId"], "status": "PENDING_NEW"}
+		})
+		defer ts.Close()
+
+		risk := NewRiskManager()
+		risk.SetAccountLimits("REM6771", RiskLimits{MaxOpenOrders: 5})
+		c := newClient(ts, WithWSOrderTimeout(50*time.Millisecond), WithRiskManager(risk))
+		h, err := c.SendOrderWS(ctx, order)
+		if err != nil {
+			t.Fatalf("SendOrderWS: %v", err)
+		}
+		if _, err := h.Wait(ctx); !errors.Is(err, ErrOrderAckTimeout) {
+			t.Fatalf("want ErrOrderAckTimeout, got %v", err)
+		}
+		// El reporte tardío confirma la reserva con el clOrdId real, no con el wsClOrdId
+		for _, ok := risk.lookupOrder("user125"); !ok; _, ok = risk.lookupOrder("user125") {
+			select {
+			case <-ctx.Done():
+				t.Fatalf("late ack not registered")
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		if _, ok := risk.lookupOrder(h.WSClOrdID); ok {
+			t.Fatalf("order registered under wsClOrdId")
+		}
+	})
+}